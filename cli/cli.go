@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"yoru/shared"
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{
+			name:    "connect",
+			usage:   "connect [--credential name] <host name|[user@]host[:port]|ssh://...>",
+			summary: "Open a terminal tab straight to a saved host or an address",
+			run:     runConnect,
+		},
+		{
+			name:    "hosts",
+			usage:   "hosts list [--json] | add [--group g] [--tags a,b] [--credential name] <name> <[user@]host[:port]> | rm <name|id>...",
+			summary: "List, add or remove saved hosts",
+			run:     runHosts,
+		},
+		{
+			name:    "exec",
+			usage:   "exec [--json] [--concurrency n] [--timeout 2m] <hosts> <command...>",
+			summary: "Run a command on several hosts; hosts are names, #tags or /groups, separated by commas",
+			run:     runExec,
+		},
+		{
+			name:    "keys",
+			usage:   "keys list [--json] | add [--name n] [--username u] [--passphrase-stdin] <private key file>",
+			summary: "List keychain identities and keys, or import a private key",
+			run:     runKeys,
+		},
+		{
+			name:    "logs",
+			usage:   "logs [-n count] [--json]",
+			summary: "Show the latest sessions",
+			run:     runLogs,
+		},
+		{
+			name:    "import",
+			usage:   "import ssh-config [--dry-run] [--on-conflict skip|replace|rename] [path]",
+			summary: "Import hosts from an OpenSSH client config",
+			run:     runImport,
+		},
+		{
+			name:    "known-hosts",
+			usage:   "known-hosts import|export [path] | trust-ca|revoke <key.pub> [pattern]",
+			summary: "Sync known_hosts files, trust host CAs or revoke keys",
+			run:     runKnownHosts,
+		},
+		{
+			name:    "prefs",
+			usage:   "prefs list | set <preference> <value> | unset <preference>",
+			summary: "Show or change preferences, such as how background sessions notify",
+			run:     runPrefs,
+		},
+		{
+			name:    "workspaces",
+			usage:   "workspaces list [--json] | save <name> <hosts> | rm <name>... | open <name>",
+			summary: "List, save, remove or open sets of tabs; hosts are names, #tags or /groups, separated by commas",
+			run:     runWorkspaces,
+		},
+		{
+			name:    "version",
+			usage:   "version",
+			summary: "Print the version and build date",
+			run:     runVersion,
+		},
+	}
+}
+
+// Run executes the subcommand named by args[0] and returns the exit code
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+	if args[0] == "--version" || args[0] == "-v" {
+		args = []string{"version"}
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		if err := cmd.run(args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", shared.PackageName, cmd.name, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", shared.PackageName, args[0])
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage:\n  %s                 Start the terminal interface\n", shared.PackageName)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\n      %s\n", shared.PackageName, cmd.usage, cmd.summary)
+	}
+}
+
+func runVersion(args []string) error {
+	fmt.Printf("%s %s (built %s)\n", shared.PrettyName, shared.Version, shared.Date)
+	return nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/screens"
+	"yoru/ssh"
+	"yoru/utils/network"
+)
+
+func runConnect(args []string) error {
+	flags := flag.NewFlagSet("connect", flag.ContinueOnError)
+	credentialName := flags.String("credential", "", "identity or key to log in with, for targets that are not saved hosts")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected a host name or user@host[:port]")
+	}
+
+	host, quickConnect, err := resolveTarget(flags.Arg(0), *credentialName)
+	if err != nil {
+		return err
+	}
+
+	screens.ScreenManager.OpenOnStart(host, quickConnect)
+	return screens.Run()
+}
+
+// resolveTarget finds the saved host named value, or the one at the address
+// it holds; any other address is connected to without saving it
+func resolveTarget(value, credentialName string) (*models.Host, bool, error) {
+	if credentialName == "" {
+		host, err := findHost(value)
+		if err != nil || host != nil {
+			return host, false, err
+		}
+	}
+
+	target, err := network.ParseTarget(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("no host is named %q, and it is not an address: %w", value, err)
+	}
+
+	if target.User == "" && credentialName == "" {
+		hosts, err := repository.GetAllHosts()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, host := range hosts {
+			if host.Hostname == target.Hostname && host.Port == target.Port {
+				return &host, false, nil
+			}
+		}
+	}
+
+	credential, err := findCredential(credentialName, target.User)
+	if err != nil {
+		return nil, false, err
+	}
+	if credential == nil {
+		credentials, err := loadCredentials()
+		if err != nil {
+			return nil, false, err
+		}
+		// The login name typed replaces the credential's; without one, the
+		// first credential that names a user logs in, identities before
+		// keys and each newest first, as the keychain lists them
+		for _, candidate := range credentials {
+			if target.User != "" || candidate.Username != "" {
+				credential = &candidate
+				break
+			}
+		}
+	}
+	if credential == nil {
+		return nil, false, errors.New("no identity or key can log in, add one to the keychain or type user@host")
+	}
+
+	return ssh.NewQuickConnectHost(target, credential.Type, credential.ID), true, nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"yoru/repository"
+	"yoru/ssh"
+	"yoru/utils/hostgroup"
+)
+
+type execOutput struct {
+	Host       string `json:"host"`
+	HostID     uint   `json:"host_id"`
+	Hostname   string `json:"hostname"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Error      string `json:"error,omitempty"`
+}
+
+func runExec(args []string) error {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the results as JSON once every host has finished")
+	concurrency := flags.Int("concurrency", ssh.DefaultExecConcurrency, "hosts to run on at a time")
+	timeout := flags.Duration("timeout", ssh.DefaultExecTimeout, "time allowed per host")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return errors.New("expected hosts (e.g. \"web-1,#db,/prod\") and a command")
+	}
+	if *timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	allHosts, err := repository.GetAllHosts()
+	if err != nil {
+		return err
+	}
+	hosts, err := hostgroup.Select(allHosts, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts match %q", flags.Arg(0))
+	}
+	command := strings.Join(flags.Args()[1:], " ")
+
+	// Text output streams as hosts finish; JSON waits for all of them
+	var onResult func(int, ssh.ExecResult)
+	if !*asJSON {
+		results := make(chan ssh.ExecResult)
+		done := make(chan struct{})
+		go func() {
+			for result := range results {
+				printExecResult(result)
+			}
+			close(done)
+		}()
+		onResult = func(_ int, result ssh.ExecResult) { results <- result }
+		defer func() {
+			close(results)
+			<-done
+		}()
+	}
+
+	results := ssh.ExecAll(hosts, command, *concurrency, *timeout, onResult)
+
+	failed := 0
+	output := make([]execOutput, 0, len(results))
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		}
+		entry := execOutput{
+			Host:       result.Host.Name,
+			HostID:     result.Host.ID,
+			Hostname:   result.Host.Hostname,
+			ExitCode:   result.ExitCode,
+			DurationMS: result.Duration.Milliseconds(),
+			Stdout:     result.Stdout,
+			Stderr:     result.Stderr,
+		}
+		if result.Error != nil {
+			entry.Error = result.Error.Error()
+		}
+		output = append(output, entry)
+	}
+
+	if *asJSON {
+		if err := printJSON(output); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed on %d of %d hosts", failed, len(results))
+	}
+	return nil
+}
+
+// printExecResult prints a host's output under a header line, stdout to
+// stdout and stderr to stderr
+func printExecResult(result ssh.ExecResult) {
+	status := fmt.Sprintf("exit %d", result.ExitCode)
+	if result.Error != nil {
+		status = "error: " + result.Error.Error()
+	}
+	fmt.Printf("==> %s (%s, %s) <==\n", result.Host.Name, status, result.Duration.Round(10*time.Millisecond))
+
+	if result.Stdout != "" {
+		fmt.Print(result.Stdout)
+		if !strings.HasSuffix(result.Stdout, "\n") {
+			fmt.Println()
+		}
+	}
+	if result.Stderr != "" {
+		fmt.Fprint(os.Stderr, result.Stderr)
+		if !strings.HasSuffix(result.Stderr, "\n") {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
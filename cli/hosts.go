@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+	"yoru/utils/hostgroup"
+	"yoru/utils/network"
+)
+
+type hostOutput struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Hostname        string     `json:"hostname"`
+	Port            int        `json:"port"`
+	Mode            string     `json:"mode"`
+	Group           string     `json:"group"`
+	Tags            []string   `json:"tags"`
+	Credential      string     `json:"credential,omitempty"`
+	CredentialType  string     `json:"credential_type,omitempty"`
+	Username        string     `json:"username,omitempty"`
+	LastConnectedAt *time.Time `json:"last_connected_at,omitempty"`
+}
+
+func runHosts(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected \"list\", \"add\" or \"rm\"")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runHostsList(args[1:])
+	case "add":
+		return runHostsAdd(args[1:])
+	case "rm", "remove":
+		return runHostsRemove(args[1:])
+	}
+	return fmt.Errorf("unknown action %q", args[0])
+}
+
+func runHostsList(args []string) error {
+	flags := flag.NewFlagSet("hosts list", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the hosts as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		return err
+	}
+	credentials, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	users := make(map[string]string, len(credentials))
+	for _, credential := range credentials {
+		users[credentialKey(credential.Type, credential.ID)] = credential.Username
+	}
+	names := credentialNames(credentials)
+
+	output := make([]hostOutput, 0, len(hosts))
+	for _, host := range hosts {
+		key := credentialKey(host.CredentialType, host.CredentialID)
+		tags := hostgroup.ParseTags(host.Tags)
+		if tags == nil {
+			tags = []string{}
+		}
+		username := users[key]
+		if host.User != "" {
+			username = host.User
+		}
+		output = append(output, hostOutput{
+			ID:              host.ID,
+			Name:            host.Name,
+			Hostname:        host.Hostname,
+			Port:            host.Port,
+			Mode:            string(host.Mode),
+			Group:           host.Group,
+			Tags:            tags,
+			Credential:      names[key],
+			CredentialType:  string(host.CredentialType),
+			Username:        username,
+			LastConnectedAt: host.LastConnectedAt,
+		})
+	}
+
+	if *asJSON {
+		return printJSON(output)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tADDRESS\tUSER\tGROUP\tTAGS")
+	for _, host := range output {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			host.ID,
+			host.Name,
+			network.JoinHostPort(host.Hostname, host.Port),
+			host.Username,
+			host.Group,
+			hostgroup.JoinTags(host.Tags))
+	}
+	return writer.Flush()
+}
+
+func runHostsAdd(args []string) error {
+	flags := flag.NewFlagSet("hosts add", flag.ContinueOnError)
+	group := flags.String("group", "", "group path, e.g. prod/eu-west")
+	tags := flags.String("tags", "", "comma separated tags")
+	credentialName := flags.String("credential", "", "identity or key to log in with (default: the one for the user given)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.New("expected a name and [user@]host[:port]")
+	}
+
+	target, err := network.ParseTarget(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	credential, err := findCredential(*credentialName, target.User)
+	if err != nil {
+		return err
+	}
+	if credential == nil && target.User != "" {
+		return fmt.Errorf("no identity or key logs in as %q, add one or pass --credential", target.User)
+	}
+
+	host := &models.Host{
+		Name:     flags.Arg(0),
+		Hostname: target.Hostname,
+		Mode:     types.ModeSSH,
+		Port:     target.Port,
+		Group:    hostgroup.NormalizePath(*group),
+		Tags:     hostgroup.NormalizeTags(*tags),
+	}
+	if credential != nil {
+		host.CredentialType = credential.Type
+		host.CredentialID = credential.ID
+		if target.User != "" && credential.Username != target.User {
+			fmt.Fprintf(os.Stderr, "warning: %s logs in as %q, not %q\n", credential.Name, credential.Username, target.User)
+		}
+	}
+	if err := repository.CreateHost(host); err != nil {
+		return err
+	}
+
+	fmt.Printf("added %s (ID %d)\n", host.Name, host.ID)
+	return nil
+}
+
+func runHostsRemove(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected the names or IDs of the hosts to remove")
+	}
+
+	// Check them all first, so a typo does not leave half the list removed
+	hosts := make([]*models.Host, 0, len(args))
+	for _, arg := range args {
+		host, err := findHost(arg)
+		if err != nil {
+			return err
+		}
+		if host == nil {
+			return fmt.Errorf("no host is named %q", arg)
+		}
+		hosts = append(hosts, host)
+	}
+
+	for _, host := range hosts {
+		if err := repository.DeleteHost(host.ID); err != nil {
+			return err
+		}
+		fmt.Printf("removed %s (ID %d)\n", host.Name, host.ID)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"yoru/sshconfig"
+	"yoru/utils/network"
+)
+
+var conflictActions = map[string]sshconfig.ConflictAction{
+	"skip":    sshconfig.ActionSkip,
+	"replace": sshconfig.ActionReplace,
+	"rename":  sshconfig.ActionRename,
+}
+
+func runImport(args []string) error {
+	if len(args) == 0 || args[0] != "ssh-config" {
+		return errors.New("expected a source, e.g. \"import ssh-config\"")
+	}
+
+	flags := flag.NewFlagSet("import ssh-config", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "show what would be imported without saving")
+	onConflict := flags.String("on-conflict", "skip", "what to do with hosts that already exist: skip, replace or rename")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	action, ok := conflictActions[*onConflict]
+	if !ok {
+		return fmt.Errorf("unknown conflict action %q", *onConflict)
+	}
+
+	path := sshconfig.DefaultPath()
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+
+	entries, err := sshconfig.Parse(path)
+	if err != nil {
+		return err
+	}
+	candidates, err := sshconfig.Plan(entries)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "HOST\tADDRESS\tUSER\tACTION")
+	for i := range candidates {
+		candidate := &candidates[i]
+		status := "create"
+		if candidate.Existing != nil {
+			candidate.Action = action
+			status = action.String()
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			candidate.Entry.Alias,
+			network.JoinHostPort(candidate.Entry.HostName, candidate.Entry.Port),
+			candidate.Entry.User,
+			status)
+	}
+	writer.Flush()
+
+	if *dryRun {
+		return nil
+	}
+
+	result, err := sshconfig.Apply(candidates)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d created, %d updated, %d skipped, %d keys added\n",
+		result.Created, result.Updated, result.Skipped, result.KeysCreated)
+	return nil
+}
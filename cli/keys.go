@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"yoru/repository"
+	"yoru/ssh"
+	"yoru/types"
+)
+
+type credentialOutput struct {
+	ID          uint   `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Username    string `json:"username"`
+	KeyType     string `json:"key_type,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Certificate bool   `json:"certificate,omitempty"`
+}
+
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected \"list\" or \"add\"")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runKeysList(args[1:])
+	case "add":
+		return runKeysAdd(args[1:])
+	}
+	return fmt.Errorf("unknown action %q", args[0])
+}
+
+func runKeysList(args []string) error {
+	flags := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the identities and keys as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	identities, err := repository.GetAllIdentities()
+	if err != nil {
+		return err
+	}
+	keys, err := repository.GetAllKeys()
+	if err != nil {
+		return err
+	}
+
+	// Passwords and private keys are never printed
+	output := make([]credentialOutput, 0, len(identities)+len(keys))
+	for _, identity := range identities {
+		output = append(output, credentialOutput{
+			ID:       identity.ID,
+			Type:     string(types.CredentialIdentity),
+			Name:     identity.Name,
+			Username: identity.Username,
+		})
+	}
+	for _, key := range keys {
+		entry := credentialOutput{
+			ID:          key.ID,
+			Type:        string(types.CredentialKey),
+			Name:        key.Name,
+			Username:    key.Username,
+			Certificate: key.Certificate != "",
+		}
+		if info, err := ssh.InspectPrivateKey(key.PrivateKey); err == nil {
+			entry.KeyType = info.Type
+			entry.Fingerprint = info.Fingerprint
+		}
+		output = append(output, entry)
+	}
+
+	if *asJSON {
+		return printJSON(output)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTYPE\tNAME\tUSER\tFINGERPRINT")
+	for _, entry := range output {
+		kind := entry.Type
+		if entry.KeyType != "" {
+			kind = entry.Type + " (" + entry.KeyType + ")"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", entry.ID, kind, entry.Name, entry.Username, entry.Fingerprint)
+	}
+	return writer.Flush()
+}
+
+func runKeysAdd(args []string) error {
+	flags := flag.NewFlagSet("keys add", flag.ContinueOnError)
+	name := flags.String("name", "", "name in the keychain (default: the key's comment or file name)")
+	username := flags.String("username", "", "user the key logs in as")
+	passphraseStdin := flags.Bool("passphrase-stdin", false, "read the passphrase of an encrypted key from the first line of stdin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected the path of a private key file")
+	}
+
+	var passphrase string
+	if *passphraseStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("unable to read the passphrase: %w", err)
+		}
+		passphrase = strings.TrimRight(line, "\r\n")
+	}
+
+	key, imported, err := ssh.ImportKeyFile(flags.Arg(0), passphrase)
+	if errors.Is(err, ssh.ErrKeyEncrypted) {
+		return fmt.Errorf("%w, pass --passphrase-stdin", err)
+	}
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		key.Name = *name
+	}
+	key.Username = *username
+	if err := repository.CreateKey(key); err != nil {
+		return err
+	}
+
+	fmt.Printf("added %s (ID %d, %s key)\n", key.Name, key.ID, imported.Format)
+	return nil
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"yoru/ssh"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func runKnownHosts(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected \"import\", \"export\", \"trust-ca\" or \"revoke\"")
+	}
+
+	switch args[0] {
+	case "trust-ca", "revoke":
+		return runMarkKey(args[0], args[1:])
+	}
+
+	path := ssh.DefaultKnownHostsPath()
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	switch args[0] {
+	case "import":
+		result, err := ssh.ImportKnownHosts(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d imported, %d already known, %d invalid lines\n", result.Imported, result.Skipped, result.Invalid)
+	case "export":
+		result, err := ssh.ExportKnownHosts(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d written to %s, %d already present\n", result.Written, path, result.Present)
+		if result.Skipped > 0 {
+			fmt.Printf("%d entries saved before public keys were kept could not be exported\n", result.Skipped)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", args[0])
+	}
+	return nil
+}
+
+// runMarkKey adds an @cert-authority or @revoked entry from a public key
+// file, for the hosts matching an optional pattern (all hosts by default)
+func runMarkKey(action string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: known-hosts %s <public key file> [host pattern]", action)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	key, comment, _, _, err := gossh.ParseAuthorizedKey(data)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %w", args[0], err)
+	}
+
+	pattern := "*"
+	if len(args) > 1 {
+		pattern = args[1]
+	}
+
+	outcome := "trusted as host CA"
+	if action == "trust-ca" {
+		err = ssh.TrustHostAuthority(key, pattern, comment)
+	} else {
+		outcome = "revoked"
+		err = ssh.RevokeKey(key, pattern, comment)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s for %s\n", ssh.GetFingerprint(key), outcome, pattern)
+	return nil
+}
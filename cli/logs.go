@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"yoru/repository"
+)
+
+type logOutput struct {
+	ID             uint       `json:"id"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	RemoteHostname string     `json:"remote_hostname"`
+	LocalHostname  string     `json:"local_hostname"`
+	LocalIP        string     `json:"local_ip"`
+	Mode           string     `json:"mode"`
+	Credential     string     `json:"credential,omitempty"`
+	RecordingPath  string     `json:"recording_path,omitempty"`
+}
+
+func runLogs(args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	limit := flags.Int("n", 50, "number of sessions to show, 0 for all")
+	asJSON := flags.Bool("json", false, "print the sessions as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logs, err := repository.GetAllConnectionLogs()
+	if *limit > 0 {
+		logs, err = repository.GetLastNConnectionLogs(*limit)
+	}
+	if err != nil {
+		return err
+	}
+	credentials, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	names := credentialNames(credentials)
+
+	output := make([]logOutput, 0, len(logs))
+	for _, log := range logs {
+		output = append(output, logOutput{
+			ID:             log.ID,
+			StartedAt:      log.StartedAt,
+			EndedAt:        log.EndedAt,
+			RemoteHostname: log.RemoteHostname,
+			LocalHostname:  log.LocalHostname,
+			LocalIP:        log.LocalIP,
+			Mode:           string(log.Mode),
+			Credential:     names[credentialKey(log.CredentialType, log.CredentialID)],
+			RecordingPath:  log.RecordingPath,
+		})
+	}
+
+	if *asJSON {
+		return printJSON(output)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tSTARTED\tDURATION\tHOST\tCREDENTIAL\tRECORDING")
+	for _, log := range output {
+		duration := "active"
+		if log.EndedAt != nil {
+			duration = log.EndedAt.Sub(log.StartedAt).Round(time.Second).String()
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			log.ID,
+			log.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			duration,
+			log.RemoteHostname,
+			log.Credential,
+			log.RecordingPath)
+	}
+	return writer.Flush()
+}
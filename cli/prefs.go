@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"yoru/recording"
+	"yoru/repository"
+	"yoru/types"
+	"yoru/utils/notify"
+)
+
+type preference struct {
+	key      types.PreferenceKey
+	summary  string
+	fallback string
+	validate func(value string) error
+}
+
+// preferences are those worth setting by hand; the interface keeps its
+// own state in others
+var preferences = []preference{
+	{types.PrefPasteConfirmLines, "lines above which a paste asks first, 0 never asks", "5", validateCount},
+	{types.PrefTranscriptTemplate, "transcript file name template", recording.DefaultTranscriptTemplate, nil},
+	{types.PrefTranscriptMaxSizeMB, "size at which a transcript continues in a new file, 0 never", "10", validateCount},
+	{types.PrefNotifyMethod, "how background sessions notify: osc9, osc777, command or off", string(notify.DefaultMethod), func(value string) error {
+		_, err := notify.ParseMethod(value)
+		return err
+	}},
+	{types.PrefNotifyCommand, "command run by the command method, given $YORU_TITLE, $YORU_MESSAGE and $YORU_HOST", "", nil},
+}
+
+func runPrefs(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected \"list\", \"set\" or \"unset\"")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runPrefsList()
+	case "set":
+		if len(args) < 3 {
+			return errors.New("expected a preference and its value")
+		}
+		pref, err := findPreference(args[1])
+		if err != nil {
+			return err
+		}
+		value := strings.Join(args[2:], " ")
+		if pref.validate != nil {
+			if err := pref.validate(value); err != nil {
+				return err
+			}
+		}
+		return repository.SetPreference(pref.key, value)
+	case "unset":
+		if len(args) != 2 {
+			return errors.New("expected a preference")
+		}
+		pref, err := findPreference(args[1])
+		if err != nil {
+			return err
+		}
+		return repository.DeletePreference(pref.key)
+	}
+	return fmt.Errorf("unknown action %q", args[0])
+}
+
+func runPrefsList() error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PREFERENCE\tVALUE\tDESCRIPTION")
+	for _, pref := range preferences {
+		value := repository.GetPreference(pref.key, pref.fallback)
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", pref.key, value, pref.summary)
+	}
+	return writer.Flush()
+}
+
+func findPreference(name string) (*preference, error) {
+	for i := range preferences {
+		if string(preferences[i].key) == name {
+			return &preferences[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown preference %q, see \"prefs list\"", name)
+}
+
+func validateCount(value string) error {
+	if count, err := strconv.Atoi(value); err != nil || count < 0 {
+		return fmt.Errorf("expected a whole number, not %q", value)
+	}
+	return nil
+}
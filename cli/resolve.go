@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+)
+
+// credential is an identity or key of the keychain
+type credential struct {
+	Type     types.CredentialType
+	ID       uint
+	Name     string
+	Username string
+}
+
+func loadCredentials() ([]credential, error) {
+	identities, err := repository.GetAllIdentities()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := repository.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]credential, 0, len(identities)+len(keys))
+	for _, identity := range identities {
+		credentials = append(credentials, credential{types.CredentialIdentity, identity.ID, identity.Name, identity.Username})
+	}
+	for _, key := range keys {
+		credentials = append(credentials, credential{types.CredentialKey, key.ID, key.Name, key.Username})
+	}
+	return credentials, nil
+}
+
+// credentialNames maps "type:id" to the name of each credential, for
+// printing what hosts and logs refer to
+func credentialNames(credentials []credential) map[string]string {
+	names := make(map[string]string, len(credentials))
+	for _, credential := range credentials {
+		names[credentialKey(credential.Type, credential.ID)] = credential.Name
+	}
+	return names
+}
+
+func credentialKey(credentialType types.CredentialType, id uint) string {
+	return fmt.Sprintf("%s:%d", credentialType, id)
+}
+
+// findCredential picks the credential to log in with: the one named, if
+// any, otherwise the first one for user in keychain order (identities
+// before keys, each newest first)
+func findCredential(name, user string) (*credential, error) {
+	credentials, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		for _, credential := range credentials {
+			if strings.EqualFold(credential.Name, name) {
+				return &credential, nil
+			}
+		}
+		return nil, fmt.Errorf("no identity or key is named %q", name)
+	}
+
+	for _, credential := range credentials {
+		if user != "" && credential.Username == user {
+			return &credential, nil
+		}
+	}
+	return nil, nil
+}
+
+// findHost looks a saved host up by ID or name
+func findHost(value string) (*models.Host, error) {
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if host, err := repository.GetHostByID(uint(id)); err == nil {
+			return host, nil
+		}
+	}
+
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		return nil, err
+	}
+	var found []models.Host
+	for _, host := range hosts {
+		if strings.EqualFold(host.Name, value) {
+			found = append(found, host)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &found[0], nil
+	}
+	return nil, fmt.Errorf("%d hosts are named %q, use an ID from \"hosts list\"", len(found), value)
+}
+
+func printJSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
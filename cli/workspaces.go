@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/screens"
+	"yoru/utils/hostgroup"
+)
+
+type workspaceOutput struct {
+	Name  string   `json:"name"`
+	Tabs  int      `json:"tabs"`
+	Hosts []string `json:"hosts"`
+}
+
+func runWorkspaces(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected \"list\", \"save\", \"rm\" or \"open\"")
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		return runWorkspacesList(args[1:])
+	case "save":
+		return runWorkspacesSave(args[1:])
+	case "rm", "remove":
+		return runWorkspacesRemove(args[1:])
+	case "open":
+		return runWorkspacesOpen(args[1:])
+	}
+	return fmt.Errorf("unknown action %q", args[0])
+}
+
+func runWorkspacesList(args []string) error {
+	flags := flag.NewFlagSet("workspaces list", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the workspaces as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	workspaces, err := repository.GetNamedWorkspaces()
+	if err != nil {
+		return err
+	}
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		return err
+	}
+	names := make(map[uint]string, len(hosts))
+	for _, host := range hosts {
+		names[host.ID] = host.Name
+	}
+
+	output := make([]workspaceOutput, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		hostNames := []string{}
+		for _, tab := range workspace.Tabs {
+			hostNames = appendPaneHosts(hostNames, tab.Pane, names)
+		}
+		output = append(output, workspaceOutput{
+			Name:  workspace.Name,
+			Tabs:  len(workspace.Tabs),
+			Hosts: hostNames,
+		})
+	}
+
+	if *asJSON {
+		return printJSON(output)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tTABS\tHOSTS")
+	for _, workspace := range output {
+		fmt.Fprintf(writer, "%s\t%d\t%s\n", workspace.Name, workspace.Tabs, strings.Join(workspace.Hosts, ", "))
+	}
+	return writer.Flush()
+}
+
+// appendPaneHosts adds the names of the hosts of a pane layout, in pane
+// order; deleted hosts are shown by ID
+func appendPaneHosts(hostNames []string, pane *models.WorkspacePane, names map[uint]string) []string {
+	if pane == nil {
+		return hostNames
+	}
+	if pane.First == nil && pane.Second == nil {
+		name, ok := names[pane.HostID]
+		if !ok {
+			name = fmt.Sprintf("deleted host %d", pane.HostID)
+		}
+		return append(hostNames, name)
+	}
+	hostNames = appendPaneHosts(hostNames, pane.First, names)
+	return appendPaneHosts(hostNames, pane.Second, names)
+}
+
+func runWorkspacesSave(args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected a name and the hosts to open, as names, #tags or /groups separated by commas")
+	}
+	name := strings.TrimSpace(args[0])
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	allHosts, err := repository.GetAllHosts()
+	if err != nil {
+		return err
+	}
+	hosts, err := hostgroup.Select(allHosts, args[1])
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no hosts match %q", args[1])
+	}
+
+	// One tab per host, the first one shown
+	workspace := &models.Workspace{Name: name}
+	for _, host := range hosts {
+		workspace.Tabs = append(workspace.Tabs, models.WorkspaceTab{
+			Name: host.Name + "@" + host.Hostname,
+			Pane: &models.WorkspacePane{HostID: host.ID},
+		})
+	}
+	if err := repository.SaveWorkspace(workspace); err != nil {
+		return err
+	}
+	fmt.Printf("saved %s (%d tabs)\n", workspace.Name, len(workspace.Tabs))
+	return nil
+}
+
+func runWorkspacesRemove(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected the names of the workspaces to remove")
+	}
+
+	// Check them all first, so a typo does not leave half the list removed
+	for _, name := range args {
+		workspace, err := repository.GetWorkspace(name)
+		if err != nil {
+			return err
+		}
+		if workspace == nil || name == "" {
+			return fmt.Errorf("no workspace is named %q", name)
+		}
+	}
+
+	for _, name := range args {
+		if err := repository.DeleteWorkspace(name); err != nil {
+			return err
+		}
+		fmt.Printf("removed %s\n", name)
+	}
+	return nil
+}
+
+func runWorkspacesOpen(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected the name of a workspace")
+	}
+
+	workspace, err := repository.GetWorkspace(args[0])
+	if err != nil {
+		return err
+	}
+	if workspace == nil || args[0] == "" {
+		return fmt.Errorf("no workspace is named %q", args[0])
+	}
+
+	screens.ScreenManager.OpenWorkspaceOnStart(workspace)
+	return screens.Run()
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+	"yoru/models"
+)
+
+func TestAppendPaneHosts(t *testing.T) {
+	names := map[uint]string{1: "web", 2: "db", 3: "cache"}
+	leaf := func(hostID uint) *models.WorkspacePane {
+		return &models.WorkspacePane{HostID: hostID}
+	}
+
+	tests := []struct {
+		name string
+		pane *models.WorkspacePane
+		want []string
+	}{
+		{"no pane", nil, []string{}},
+		{"one host", leaf(1), []string{"web"}},
+		{"split", &models.WorkspacePane{Vertical: true, First: leaf(2), Second: leaf(1)}, []string{"db", "web"}},
+		{
+			"nested split",
+			&models.WorkspacePane{First: leaf(1), Second: &models.WorkspacePane{First: leaf(3), Second: leaf(2)}},
+			[]string{"web", "cache", "db"},
+		},
+		{"deleted host", &models.WorkspacePane{First: leaf(1), Second: leaf(9)}, []string{"web", "deleted host 9"}},
+	}
+
+	for _, test := range tests {
+		if got := appendPaneHosts([]string{}, test.pane, names); !slices.Equal(got, test.want) {
+			t.Errorf("%s: appendPaneHosts() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
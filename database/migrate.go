@@ -1,16 +1,36 @@
 package database
 
 import (
+	"strings"
 	"yoru/models"
 	"yoru/types"
 )
 
 func migrate(db *types.Database) error {
+	if err := dropUniqueFingerprintIndex(db); err != nil {
+		return err
+	}
+
 	return db.AutoMigrate(
 		&models.Host{},
 		&models.KnownHost{},
 		&models.Identity{},
 		&models.Key{},
 		&models.ConnectionLog{},
+		&models.Preference{},
+		&models.Workspace{},
 	)
 }
+
+// Known hosts used to be unique by fingerprint, but known_hosts files list
+// the same key under several host patterns and markers
+func dropUniqueFingerprintIndex(db *types.Database) error {
+	const index = "idx_known_hosts_fingerprint"
+
+	var definition string
+	err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?", index).Scan(&definition).Error
+	if err != nil || !strings.HasPrefix(definition, "CREATE UNIQUE INDEX") {
+		return err
+	}
+	return db.Migrator().DropIndex(&models.KnownHost{}, index)
+}
@@ -13,13 +13,33 @@ type Host struct {
 	Port            int                  `gorm:"not null"`
 	CredentialID    uint                 `gorm:"not null"`
 	CredentialType  types.CredentialType `gorm:"type:text;not null"`
+	AlwaysRecord    bool                 `gorm:"not null;default:false"`
+	Transcript      bool                 `gorm:"not null;default:false"`
+	StaticTitle     bool                 `gorm:"not null;default:false"` // ignore the titles the session sets
+	NotifyBell      bool                 `gorm:"not null;default:false"`
+	NotifyExit      bool                 `gorm:"not null;default:false"`
+	NotifyPatterns  string               `gorm:"type:text;not null;default:''"`         // comma separated
+	Group           string               `gorm:"column:group_path;not null;default:''"` // folder path, "/" separated
+	Tags            string               `gorm:"not null;default:''"`                   // comma separated
+	SortOrder       int                  `gorm:"not null;default:0"`                    // position within the group
 	LastConnectedAt *time.Time
+
+	// User logs in instead of the credential's username when it is set,
+	// as for hosts imported from ssh_config or saved from quick connect
+	User string `gorm:"column:login_user;not null;default:''"`
 }
 
+// KnownHost is a trusted (or revoked) key. Pattern holds the host field of
+// the equivalent known_hosts line, which may be hashed or contain wildcards;
+// Hostname and Port are only set for entries naming a single plain host.
 type KnownHost struct {
 	types.Model
-	Hostname    string `gorm:"not null"`
-	Port        int    `gorm:"not null"`
-	KeyType     string `gorm:"not null"`
-	Fingerprint string `gorm:"not null;uniqueIndex"`
+	Hostname    string                `gorm:"not null"`
+	Port        int                   `gorm:"not null"`
+	Pattern     string                `gorm:"not null;default:''"`
+	Marker      types.KnownHostMarker `gorm:"type:text;not null;default:''"`
+	KeyType     string                `gorm:"not null"`
+	Fingerprint string                `gorm:"not null;index"`
+	PublicKey   string                `gorm:"type:text;not null;default:''"`
+	Comment     string                `gorm:"not null;default:''"`
 }
@@ -15,4 +15,5 @@ type ConnectionLog struct {
 	Mode           types.ConnectionMode `gorm:"type:text;not null"`
 	CredentialID   uint                 `gorm:"not null"`
 	CredentialType types.CredentialType `gorm:"type:text;not null"`
+	RecordingPath  string
 }
@@ -0,0 +1,9 @@
+package models
+
+import "yoru/types"
+
+type Preference struct {
+	types.Model
+	Key   types.PreferenceKey `gorm:"not null;uniqueIndex"`
+	Value string              `gorm:"type:text;not null"`
+}
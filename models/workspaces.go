@@ -0,0 +1,30 @@
+package models
+
+import "yoru/types"
+
+// Workspace is a set of tabs opened together. The workspace without a name
+// holds the tabs that were open when the interface last quit.
+type Workspace struct {
+	types.Model
+	Name      string         `gorm:"not null;uniqueIndex"`
+	Tabs      []WorkspaceTab `gorm:"type:text;not null;serializer:json"`
+	ActiveTab int            `gorm:"not null;default:0"` // index among Tabs, -1 for the home tab
+}
+
+// WorkspaceTab is a tab of a workspace: one host, or several in split panes
+type WorkspaceTab struct {
+	Name    string         `json:"name"`
+	Renamed bool           `json:"renamed,omitempty"`
+	Pane    *WorkspacePane `json:"pane"`
+}
+
+// WorkspacePane is either the terminal of a host, or two panes side by
+// side (Vertical) or stacked, the first taking Ratio of the room
+type WorkspacePane struct {
+	HostID   uint           `json:"host_id,omitempty"`
+	Focused  bool           `json:"focused,omitempty"`
+	Vertical bool           `json:"vertical,omitempty"`
+	Ratio    float64        `json:"ratio,omitempty"`
+	First    *WorkspacePane `json:"first,omitempty"`
+	Second   *WorkspacePane `json:"second,omitempty"`
+}
@@ -0,0 +1,123 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+const (
+	EventOutput = "o"
+	EventResize = "r"
+)
+
+// Header is the first line of an asciicast v2 file
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is a single timed entry of an asciicast v2 file
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// Cast is a fully loaded recording
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SafeName turns a host name into something usable as a file or directory name
+func SafeName(name string) string {
+	name = unsafeFileChars.ReplaceAllString(name, "_")
+	if name == "" || name == "." || name == ".." {
+		return "session"
+	}
+	return name
+}
+
+// FileName builds the recording file name for a session started at the given time
+func FileName(hostName string, startedAt time.Time) string {
+	return fmt.Sprintf("%s-%s.cast", SafeName(hostName), startedAt.Format("20060102-150405"))
+}
+
+// Load reads an asciicast v2 file into memory
+func Load(path string) (*Cast, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("recording is empty")
+	}
+
+	cast := &Cast{}
+	if err := json.Unmarshal(scanner.Bytes(), &cast.Header); err != nil {
+		return nil, fmt.Errorf("invalid recording header: %w", err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d", cast.Header.Version)
+	}
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var fields []any
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil || len(fields) != 3 {
+			continue
+		}
+
+		eventTime, okTime := fields[0].(float64)
+		eventType, okType := fields[1].(string)
+		eventData, okData := fields[2].(string)
+		if !okTime || !okType || !okData {
+			continue
+		}
+
+		cast.Events = append(cast.Events, Event{Time: eventTime, Type: eventType, Data: eventData})
+	}
+
+	return cast, scanner.Err()
+}
+
+// Duration returns the time of the last event
+func (cast *Cast) Duration() time.Duration {
+	if len(cast.Events) == 0 {
+		return 0
+	}
+	return seconds(cast.Events[len(cast.Events)-1].Time)
+}
+
+// ParseResize parses the "COLSxROWS" payload of a resize event
+func ParseResize(data string) (width, height int, ok bool) {
+	if _, err := fmt.Sscanf(data, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,118 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSafeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"web-01.example.com", "web-01.example.com"},
+		{"user@host:22", "user_host_22"},
+		{"../etc/passwd", ".._etc_passwd"},
+		{"prod / db", "prod_db"},
+		{"", "session"},
+		{".", "session"},
+		{"..", "session"},
+	}
+
+	for _, test := range tests {
+		if got := SafeName(test.name); got != test.want {
+			t.Errorf("SafeName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+	if got := FileName("web 01", time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)); got != "web_01-20260304-050607.cast" {
+		t.Errorf("FileName() = %q", got)
+	}
+}
+
+func TestParseResize(t *testing.T) {
+	tests := []struct {
+		data   string
+		width  int
+		height int
+		ok     bool
+	}{
+		{"80x24", 80, 24, true},
+		{"200x60", 200, 60, true},
+		{"0x24", 0, 0, false},
+		{"80x-1", 0, 0, false},
+		{"80", 0, 0, false},
+		{"wide", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		width, height, ok := ParseResize(test.data)
+		if width != test.width || height != test.height || ok != test.ok {
+			t.Errorf("ParseResize(%q) = %d, %d, %v, want %d, %d, %v", test.data, width, height, ok, test.width, test.height, test.ok)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     []Event
+		duration time.Duration
+		wantErr  bool
+	}{
+		{
+			name: "events",
+			data: `{"version": 2, "width": 80, "height": 24, "timestamp": 1}
+[0.5, "o", "hello"]
+
+[1.25, "r", "100x30"]
+`,
+			want:     []Event{{0.5, EventOutput, "hello"}, {1.25, EventResize, "100x30"}},
+			duration: 1250 * time.Millisecond,
+		},
+		{
+			name: "malformed events are skipped",
+			data: `{"version": 2, "width": 80, "height": 24}
+[0.1, "o"]
+not json
+["0.2", "o", "x"]
+[0.3, "o", "kept"]
+`,
+			want:     []Event{{0.3, EventOutput, "kept"}},
+			duration: 300 * time.Millisecond,
+		},
+		{name: "no events", data: `{"version": 2, "width": 80, "height": 24}`},
+		{name: "empty", data: "", wantErr: true},
+		{name: "bad header", data: "[0.1, \"o\", \"x\"]\n", wantErr: true},
+		{name: "version 1", data: `{"version": 1, "width": 80, "height": 24}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.cast")
+			if err := os.WriteFile(path, []byte(test.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cast, err := Load(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cast.Header.Width != 80 || cast.Header.Height != 24 {
+				t.Errorf("size = %dx%d, want 80x24", cast.Header.Width, cast.Header.Height)
+			}
+			if !reflect.DeepEqual(cast.Events, test.want) {
+				t.Errorf("events = %v, want %v", cast.Events, test.want)
+			}
+			if cast.Duration() != test.duration {
+				t.Errorf("Duration() = %v, want %v", cast.Duration(), test.duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder writes a session's output stream to an asciicast v2 file
+type Recorder struct {
+	file      *os.File
+	path      string
+	started   time.Time
+	paused    bool
+	pausedAt  time.Time
+	pausedFor time.Duration
+
+	// size last written to the recording, and the latest one, which
+	// differ after a resize while paused
+	recordedWidth, recordedHeight int
+	width, height                 int
+
+	// trailing bytes of a UTF-8 sequence split across two writes
+	pending []byte
+}
+
+// NewRecorder creates the recording file and writes its header
+func NewRecorder(path string, width, height int, title string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &Recorder{
+		file:           file,
+		path:           path,
+		started:        time.Now(),
+		recordedWidth:  width,
+		recordedHeight: height,
+		width:          width,
+		height:         height,
+	}
+
+	header, err := json.Marshal(Header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: recorder.started.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Write(append(header, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return recorder, nil
+}
+
+func (recorder *Recorder) Path() string {
+	return recorder.path
+}
+
+func (recorder *Recorder) IsPaused() bool {
+	return recorder.paused
+}
+
+// Pause stops recording output; the paused time is left out of the recording
+func (recorder *Recorder) Pause() {
+	if !recorder.paused {
+		recorder.paused = true
+		recorder.pausedAt = time.Now()
+		// The rest of a split character is not recorded either
+		recorder.pending = nil
+	}
+}
+
+// Resume records output again, starting with the terminal size if it
+// changed during the pause
+func (recorder *Recorder) Resume() error {
+	if !recorder.paused {
+		return nil
+	}
+	recorder.paused = false
+	recorder.pausedFor += time.Since(recorder.pausedAt)
+
+	if recorder.width != recorder.recordedWidth || recorder.height != recorder.recordedHeight {
+		return recorder.writeResize()
+	}
+	return nil
+}
+
+// WriteOutput records data received from the remote
+func (recorder *Recorder) WriteOutput(data []byte) error {
+	if recorder.paused {
+		return nil
+	}
+
+	data = append(recorder.pending, data...)
+	cut := completeRunesLength(data)
+	recorder.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return nil
+	}
+
+	return recorder.writeEvent(EventOutput, string(data[:cut]))
+}
+
+// Resize records a change of the terminal size; while paused, it is
+// recorded on resume
+func (recorder *Recorder) Resize(width, height int) error {
+	recorder.width, recorder.height = width, height
+	if recorder.paused {
+		return nil
+	}
+	return recorder.writeResize()
+}
+
+func (recorder *Recorder) writeResize() error {
+	recorder.recordedWidth, recorder.recordedHeight = recorder.width, recorder.height
+	return recorder.writeEvent(EventResize, fmt.Sprintf("%dx%d", recorder.width, recorder.height))
+}
+
+func (recorder *Recorder) Close() error {
+	if len(recorder.pending) > 0 && !recorder.paused {
+		recorder.writeEvent(EventOutput, string(recorder.pending))
+	}
+	return recorder.file.Close()
+}
+
+func (recorder *Recorder) writeEvent(eventType string, data string) error {
+	elapsed := time.Since(recorder.started) - recorder.pausedFor
+	line, err := json.Marshal([]any{elapsed.Seconds(), eventType, data})
+	if err != nil {
+		return err
+	}
+
+	_, err = recorder.file.Write(append(line, '\n'))
+	return err
+}
+
+// completeRunesLength returns the length of data without a trailing incomplete UTF-8 sequence
+func completeRunesLength(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,132 @@
+package recording
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	type step struct {
+		action string // output, resize, pause or resume
+		data   string
+		width  int
+		height int
+	}
+	type event struct {
+		Type string
+		Data string
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  []event
+	}{
+		{
+			name:  "output",
+			steps: []step{{action: "output", data: "hello"}, {action: "output", data: " world"}},
+			want:  []event{{EventOutput, "hello"}, {EventOutput, " world"}},
+		},
+		{
+			name:  "character split across writes",
+			steps: []step{{action: "output", data: "caf\xc3"}, {action: "output", data: "\xa9!"}},
+			want:  []event{{EventOutput, "caf"}, {EventOutput, "é!"}},
+		},
+		{
+			name:  "resize",
+			steps: []step{{action: "resize", width: 100, height: 30}},
+			want:  []event{{EventResize, "100x30"}},
+		},
+		{
+			name: "paused output is left out",
+			steps: []step{
+				{action: "output", data: "a"},
+				{action: "pause"},
+				{action: "output", data: "b"},
+				{action: "resume"},
+				{action: "output", data: "c"},
+			},
+			want: []event{{EventOutput, "a"}, {EventOutput, "c"}},
+		},
+		{
+			name: "resize while paused is recorded on resume",
+			steps: []step{
+				{action: "pause"},
+				{action: "resize", width: 120, height: 40},
+				{action: "resize", width: 132, height: 43},
+				{action: "resume"},
+				{action: "output", data: "after"},
+			},
+			want: []event{{EventResize, "132x43"}, {EventOutput, "after"}},
+		},
+		{
+			name: "resize back while paused records nothing",
+			steps: []step{
+				{action: "pause"},
+				{action: "resize", width: 120, height: 40},
+				{action: "resize", width: 80, height: 24},
+				{action: "resume"},
+			},
+			want: nil,
+		},
+		{
+			name: "split character dropped by a pause",
+			steps: []step{
+				{action: "output", data: "x\xc3"},
+				{action: "pause"},
+				{action: "resume"},
+				{action: "output", data: "y"},
+			},
+			want: []event{{EventOutput, "x"}, {EventOutput, "y"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.cast")
+			recorder, err := NewRecorder(path, 80, 24, "title")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, step := range test.steps {
+				switch step.action {
+				case "output":
+					err = recorder.WriteOutput([]byte(step.data))
+				case "resize":
+					err = recorder.Resize(step.width, step.height)
+				case "pause":
+					recorder.Pause()
+				case "resume":
+					err = recorder.Resume()
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", step.action, err)
+				}
+			}
+			if err := recorder.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			cast, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cast.Header.Width != 80 || cast.Header.Height != 24 || cast.Header.Title != "title" {
+				t.Errorf("header = %+v", cast.Header)
+			}
+			var got []event
+			last := 0.0
+			for _, castEvent := range cast.Events {
+				if castEvent.Time < last {
+					t.Errorf("event at %v after one at %v", castEvent.Time, last)
+				}
+				last = castEvent.Time
+				got = append(got, event{castEvent.Type, castEvent.Data})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("events = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTranscriptTemplate names transcripts when no template is configured.
+// Supported placeholders are {host}, {date}, {time} and {session}.
+const DefaultTranscriptTemplate = "{host}-{date}-{session}.log"
+
+// transcriptLineLimit is the longest line kept; output that never sends a
+// line feed is wrapped there
+const transcriptLineLimit = 4096
+
+// ErrTranscriptClosed is returned once a transcript can no longer be
+// written, after it is closed or a new part could not be opened
+var ErrTranscriptClosed = errors.New("transcript is closed")
+
+type transcriptState int
+
+const (
+	transcriptGround transcriptState = iota
+	transcriptEscape
+	transcriptIntermediate
+	transcriptCSI
+	transcriptString
+	transcriptStringEscape
+)
+
+// Transcript writes an ANSI-stripped text copy of a session's output. When
+// maxSize is set, writing continues in a new numbered file once the current
+// one would grow past it.
+type Transcript struct {
+	basePath string
+	title    string
+	maxSize  int64
+	part     int
+	file     *os.File
+	size     int64
+
+	state   transcriptState
+	line    []rune
+	column  int
+	pending []byte
+}
+
+// TranscriptFileName expands a transcript file name template
+func TranscriptFileName(template, hostName string, sessionID uint, startedAt time.Time) string {
+	if template == "" {
+		template = DefaultTranscriptTemplate
+	}
+
+	name := strings.NewReplacer(
+		"{host}", SafeName(hostName),
+		"{date}", startedAt.Format("2006-01-02"),
+		"{time}", startedAt.Format("150405"),
+		"{session}", strconv.FormatUint(uint64(sessionID), 10),
+	).Replace(template)
+
+	return filepath.Base(name)
+}
+
+// NewTranscript creates the first transcript file at path
+func NewTranscript(path string, title string, maxSize int64) (*Transcript, error) {
+	transcript := &Transcript{
+		basePath: path,
+		title:    title,
+		maxSize:  maxSize,
+	}
+
+	if err := transcript.openPart(); err != nil {
+		return nil, err
+	}
+	return transcript, nil
+}
+
+func (transcript *Transcript) Path() string {
+	return transcript.basePath
+}
+
+// WriteOutput strips escape sequences from data and writes completed lines
+func (transcript *Transcript) WriteOutput(data []byte) error {
+	if transcript.file == nil {
+		return ErrTranscriptClosed
+	}
+
+	data = append(transcript.pending, data...)
+	cut := completeRunesLength(data)
+	transcript.pending = append([]byte(nil), data[cut:]...)
+
+	for _, r := range string(data[:cut]) {
+		if err := transcript.handleRune(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (transcript *Transcript) Close() error {
+	if transcript.file == nil {
+		return nil
+	}
+	if len(transcript.line) > 0 {
+		transcript.flushLine()
+	}
+	if transcript.file == nil {
+		return nil
+	}
+	err := transcript.file.Close()
+	transcript.file = nil
+	return err
+}
+
+func (transcript *Transcript) handleRune(r rune) error {
+	switch transcript.state {
+	case transcriptGround:
+		switch {
+		case r == 0x1b:
+			transcript.state = transcriptEscape
+		case r == '\n':
+			return transcript.flushLine()
+		case r == '\r':
+			transcript.column = 0
+		case r == '\b':
+			if transcript.column > 0 {
+				transcript.column--
+			}
+		case r == '\t':
+			return transcript.put(r)
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			// Other control characters have no textual representation
+		default:
+			return transcript.put(r)
+		}
+	case transcriptEscape:
+		switch {
+		case r == '[':
+			transcript.state = transcriptCSI
+		case r == ']', r == 'P', r == 'X', r == '^', r == '_':
+			transcript.state = transcriptString
+		case r >= 0x20 && r <= 0x2f:
+			transcript.state = transcriptIntermediate
+		default:
+			transcript.state = transcriptGround
+		}
+	case transcriptIntermediate:
+		if r < 0x20 || r > 0x2f {
+			transcript.state = transcriptGround
+		}
+	case transcriptCSI:
+		if r >= 0x40 && r <= 0x7e {
+			transcript.state = transcriptGround
+		}
+	case transcriptString:
+		switch r {
+		case 0x07:
+			transcript.state = transcriptGround
+		case 0x1b:
+			transcript.state = transcriptStringEscape
+		}
+	case transcriptStringEscape:
+		if r == '\\' {
+			transcript.state = transcriptGround
+		} else {
+			transcript.state = transcriptString
+		}
+	}
+	return nil
+}
+
+// put writes r at the cursor column so carriage returns and backspaces
+// overwrite text the way they do on screen. A line reaching the limit is
+// written out and continues on the next one.
+func (transcript *Transcript) put(r rune) error {
+	if transcript.column >= transcriptLineLimit {
+		if err := transcript.flushLine(); err != nil {
+			return err
+		}
+	}
+
+	for len(transcript.line) < transcript.column {
+		transcript.line = append(transcript.line, ' ')
+	}
+	if transcript.column < len(transcript.line) {
+		transcript.line[transcript.column] = r
+	} else {
+		transcript.line = append(transcript.line, r)
+	}
+	transcript.column++
+	return nil
+}
+
+func (transcript *Transcript) flushLine() error {
+	text := strings.TrimRight(string(transcript.line), " ") + "\n"
+	transcript.line = transcript.line[:0]
+	transcript.column = 0
+
+	if transcript.maxSize > 0 && transcript.size > 0 && transcript.size+int64(len(text)) > transcript.maxSize {
+		// No more writes go anywhere if the next part cannot be opened
+		transcript.file.Close()
+		transcript.file = nil
+		transcript.part++
+		if err := transcript.openPart(); err != nil {
+			return err
+		}
+	}
+
+	return transcript.write(text)
+}
+
+func (transcript *Transcript) openPart() error {
+	path := transcript.basePath
+	if transcript.part > 0 {
+		extension := filepath.Ext(path)
+		path = fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, extension), transcript.part, extension)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	transcript.file = file
+	transcript.size = info.Size()
+
+	header := fmt.Sprintf("# %s, part %d, started %s\n", transcript.title, transcript.part+1, time.Now().Format(time.RFC3339))
+	return transcript.write(header)
+}
+
+func (transcript *Transcript) write(text string) error {
+	written, err := transcript.file.WriteString(text)
+	transcript.size += int64(written)
+	return err
+}
@@ -0,0 +1,163 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readTranscript returns the text of a transcript file without its header
+func readTranscript(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, text, _ := strings.Cut(string(data), "\n")
+	if !strings.HasPrefix(header, "# ") {
+		t.Fatalf("%s starts with %q, want a header", filepath.Base(path), header)
+	}
+	return text
+}
+
+func TestTranscriptFileName(t *testing.T) {
+	startedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "web-01-2026-03-04-42.log"},
+		{"{host}_{time}.txt", "web-01_050607.txt"},
+		{"../{host}/{session}.log", "42.log"},
+	}
+
+	for _, test := range tests {
+		if got := TranscriptFileName(test.template, "web-01", 42, startedAt); got != test.want {
+			t.Errorf("TranscriptFileName(%q) = %q, want %q", test.template, got, test.want)
+		}
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"plain lines", []string{"one\r\ntwo\n"}, "one\ntwo\n"},
+		{"colors", []string{"\x1b[1;31mred\x1b[0m text\n"}, "red text\n"},
+		{"title", []string{"\x1b]0;user@host\x07$ ls\n", "\x1b]2;other\x1b\\done\n"}, "$ ls\ndone\n"},
+		{"charset selection", []string{"\x1b(Bplain\n"}, "plain\n"},
+		{"carriage return overwrites", []string{"50%\r100%\n"}, "100%\n"},
+		{"backspace overwrites", []string{"cat\b\bup\n"}, "cup\n"},
+		{"control characters", []string{"a\x07b\x00c\n"}, "abc\n"},
+		{"trailing spaces", []string{"text   \n"}, "text\n"},
+		{"sequence split across writes", []string{"a\x1b[3", "2mb\n"}, "ab\n"},
+		{"character split across writes", []string{"caf\xc3", "\xa9\n"}, "café\n"},
+		{"unfinished line on close", []string{"$ exit"}, "$ exit\n"},
+		{"long line", []string{strings.Repeat("x", transcriptLineLimit+3) + "\n"}, strings.Repeat("x", transcriptLineLimit) + "\nxxx\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.log")
+			transcript, err := NewTranscript(path, "test", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range test.writes {
+				if err := transcript.WriteOutput([]byte(data)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := transcript.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := transcript.WriteOutput([]byte("late\n")); err != ErrTranscriptClosed {
+				t.Errorf("WriteOutput() after Close = %v, want %v", err, ErrTranscriptClosed)
+			}
+
+			if got := readTranscript(t, path); got != test.want {
+				t.Errorf("transcript = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.log")
+	// Room for the header and about two lines per part
+	transcript, err := NewTranscript(path, "test", 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line one", "line two", "line three", "line four", "line five"} {
+		if err := transcript.WriteOutput([]byte(line + "\r\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := transcript.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Parts are numbered from the second on
+	names := []string{"session.log"}
+	for part := 1; ; part++ {
+		name := "session." + strconv.Itoa(part) + ".log"
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			break
+		}
+		names = append(names, name)
+	}
+	if len(names) < 2 {
+		t.Fatalf("wrote %d part, want more than one", len(names))
+	}
+
+	var got []string
+	for part, name := range names {
+		partPath := filepath.Join(dir, name)
+		data, err := os.ReadFile(partPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(data)) > 80 {
+			t.Errorf("%s is %d bytes, want at most 80", name, len(data))
+		}
+		if want := "part " + strconv.Itoa(part+1) + ","; !strings.Contains(string(data), want) {
+			t.Errorf("%s header does not say %q", name, want)
+		}
+		got = append(got, readTranscript(t, partPath))
+	}
+
+	want := "line one\nline two\nline three\nline four\nline five\n"
+	if joined := strings.Join(got, ""); joined != want {
+		t.Errorf("parts = %q, want %q", joined, want)
+	}
+}
+
+func TestTranscriptFailedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.log")
+	// The second part cannot be opened where a directory is in the way
+	if err := os.Mkdir(filepath.Join(dir, "session.1.log"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	transcript, err := NewTranscript(path, "test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transcript.WriteOutput([]byte("first\n")); err == nil {
+		t.Fatal("WriteOutput() = nil, want an error opening the next part")
+	}
+	if err := transcript.WriteOutput([]byte("second\n")); err != ErrTranscriptClosed {
+		t.Errorf("WriteOutput() = %v, want %v", err, ErrTranscriptClosed)
+	}
+	if err := transcript.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"yoru/database"
 	"yoru/models"
 	"yoru/types"
+
+	"gorm.io/gorm"
 )
 
 func CreateHost(host *models.Host) error {
@@ -65,3 +67,15 @@ func GetKnownHostByFingerprint(fingerprint string) (*models.KnownHost, error) {
 func DeleteKnownHost(id uint) error {
 	return database.DB.Delete(&models.KnownHost{}, id).Error
 }
+
+// ReorderHosts stores the order of the hosts of a group, given by their IDs
+func ReorderHosts(ids []uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for order, id := range ids {
+			if err := tx.Model(&models.Host{}).Where("id = ?", id).Update("sort_order", order).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
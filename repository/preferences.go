@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"strconv"
+	"yoru/database"
+	"yoru/models"
+	"yoru/types"
+)
+
+func GetPreference(key types.PreferenceKey, fallback string) string {
+	var preference models.Preference
+	if err := database.DB.Where(&models.Preference{Key: key}).First(&preference).Error; err != nil {
+		return fallback
+	}
+	return preference.Value
+}
+
+func GetIntPreference(key types.PreferenceKey, fallback int) int {
+	value, err := strconv.Atoi(GetPreference(key, strconv.Itoa(fallback)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func SetPreference(key types.PreferenceKey, value string) error {
+	var preference models.Preference
+	err := database.DB.Where(&models.Preference{Key: key}).First(&preference).Error
+	if err != nil {
+		return database.DB.Create(&models.Preference{Key: key, Value: value}).Error
+	}
+	preference.Value = value
+	return database.DB.Save(&preference).Error
+}
+
+// DeletePreference goes back to the default of a preference
+func DeletePreference(key types.PreferenceKey) error {
+	return database.DB.Where(&models.Preference{Key: key}).Delete(&models.Preference{}).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"yoru/database"
+	"yoru/models"
+
+	"gorm.io/gorm"
+)
+
+// GetWorkspace returns the workspace called name, nil if there is none
+func GetWorkspace(name string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	err := database.DB.Where("name = ?", name).First(&workspace).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// GetNamedWorkspaces lists the saved workspaces, leaving out the last session
+func GetNamedWorkspaces() ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	err := database.DB.Where("name <> ''").Order("name").Find(&workspaces).Error
+	return workspaces, err
+}
+
+// SaveWorkspace creates the workspace, or replaces the one of the same name
+func SaveWorkspace(workspace *models.Workspace) error {
+	existing, err := GetWorkspace(workspace.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		workspace.ID = existing.ID
+		workspace.CreatedAt = existing.CreatedAt
+	}
+	return database.DB.Save(workspace).Error
+}
+
+// DeleteWorkspace removes a workspace for good, so its name can be used again
+func DeleteWorkspace(name string) error {
+	return database.DB.Unscoped().Where("name = ?", name).Delete(&models.Workspace{}).Error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+	"yoru/models"
+)
+
+func TestSaveWorkspace(t *testing.T) {
+	const name = "test workspace"
+	cleanup := func() {
+		if err := DeleteWorkspace(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	first := &models.Workspace{Name: name, Tabs: []models.WorkspaceTab{{Name: "web", Pane: &models.WorkspacePane{HostID: 1}}}}
+	if err := SaveWorkspace(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving under the same name replaces the workspace
+	second := &models.Workspace{Name: name, ActiveTab: 1, Tabs: []models.WorkspaceTab{
+		{Name: "db", Pane: &models.WorkspacePane{HostID: 2}},
+		{Name: "split", Renamed: true, Pane: &models.WorkspacePane{
+			Vertical: true, Ratio: 0.3,
+			First:  &models.WorkspacePane{HostID: 1},
+			Second: &models.WorkspacePane{HostID: 3, Focused: true},
+		}},
+	}}
+	if err := SaveWorkspace(second); err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("ID = %d, want the replaced workspace's %d", second.ID, first.ID)
+	}
+
+	saved, err := GetWorkspace(name)
+	if err != nil || saved == nil {
+		t.Fatalf("GetWorkspace() = %v, %v", saved, err)
+	}
+	if saved.ActiveTab != 1 || len(saved.Tabs) != 2 {
+		t.Fatalf("saved %d tabs with tab %d active, want 2 with tab 1", len(saved.Tabs), saved.ActiveTab)
+	}
+	pane := saved.Tabs[1].Pane
+	if !saved.Tabs[1].Renamed || !pane.Vertical || pane.Ratio != 0.3 || pane.First.HostID != 1 || !pane.Second.Focused {
+		t.Errorf("split tab = %+v, pane %+v", saved.Tabs[1], pane)
+	}
+
+	workspaces, err := GetNamedWorkspaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, workspace := range workspaces {
+		if workspace.Name == "" {
+			t.Error("GetNamedWorkspaces() lists the last session")
+		}
+		if workspace.Name == name {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("GetNamedWorkspaces() lists %q %d times, want once", name, count)
+	}
+
+	// Deleted for good, so the name can be used again
+	cleanup()
+	if workspace, err := GetWorkspace(name); workspace != nil || err != nil {
+		t.Errorf("GetWorkspace() after delete = %v, %v", workspace, err)
+	}
+	if err := SaveWorkspace(&models.Workspace{Name: name}); err != nil {
+		t.Errorf("SaveWorkspace() after delete = %v", err)
+	}
+}
@@ -16,6 +16,10 @@ func (navBar *navBar) GetActiveTab() (int, string) {
 	return navBar.activeIndex, navBar.items[navBar.activeIndex]
 }
 
+func (navBar *navBar) GetItems() []string {
+	return navBar.items
+}
+
 func (navBar *navBar) SwitchToTab(index int) {
 	if index < 0 || index >= len(navBar.items) {
 		return
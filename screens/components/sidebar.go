@@ -1,42 +1,126 @@
 package components
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 	"yoru/models"
 	"yoru/screens/styles"
 	"yoru/shared"
+	"yoru/utils/fuzzy"
+	"yoru/utils/hostgroup"
+	"yoru/utils/network"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+const (
+	hostRowHeight  = 3 // title, address and margin
+	groupRowHeight = 1
+	groupIndent    = 2
+	// sidebarTextWidth is what is left of the sidebar for a row after its
+	// left border and padding
+	sidebarTextWidth = 28
+)
+
+// hostsSidebarRow is a line of the host tree: a group header when group
+// is set, otherwise a host inside parent. While filtering the rows are a
+// flat list of matches without a parent.
+type hostsSidebarRow struct {
+	group  *hostgroup.Node
+	host   *models.Host
+	parent *hostgroup.Node
+	depth  int
+}
+
+// hostMatch is how well a host matched the filter, with the runes of its
+// name and hostname to highlight
+type hostMatch struct {
+	score    int
+	name     []int
+	hostname []int
+}
+
 type HostsSidebar struct {
 	allHosts        []models.Host
-	filteredHosts   []models.Host
+	usernames       map[uint]string
+	matches         map[uint]hostMatch
+	rows            []hostsSidebarRow
+	collapsed       map[string]bool
 	selectedIdx     int
 	filterActive    bool
-	filterText      string
+	filterText      []rune
 	filterCursorPos int
 }
 
 func NewHostsSidebar() *HostsSidebar {
 	return &HostsSidebar{
 		allHosts:        []models.Host{},
-		filteredHosts:   []models.Host{},
+		usernames:       make(map[uint]string),
+		matches:         make(map[uint]hostMatch),
+		rows:            []hostsSidebarRow{},
+		collapsed:       make(map[string]bool),
 		selectedIdx:     0,
 		filterActive:    false,
-		filterText:      "",
+		filterText:      nil,
 		filterCursorPos: 0,
 	}
 }
 
+// SetUsernames gives the login of each host, by host ID, for searching
+func (sidebar *HostsSidebar) SetUsernames(usernames map[uint]string) {
+	sidebar.usernames = usernames
+}
+
+// SetHosts replaces the hosts shown, keeping the selected host or group
 func (sidebar *HostsSidebar) SetHosts(hosts []models.Host) {
 	sidebar.allHosts = hosts
-	sidebar.applyFilter()
-	if sidebar.selectedIdx >= len(sidebar.filteredHosts) {
-		sidebar.selectedIdx = 0
+	sidebar.rebuild()
+}
+
+// SelectHost moves the selection to a host, opening the groups it is in
+func (sidebar *HostsSidebar) SelectHost(id uint) {
+	for _, host := range sidebar.allHosts {
+		if host.ID != id {
+			continue
+		}
+		for _, path := range hostgroup.Ancestors(hostgroup.NormalizePath(host.Group)) {
+			delete(sidebar.collapsed, path)
+		}
+		break
+	}
+	sidebar.buildRows()
+	for i, row := range sidebar.rows {
+		if row.host != nil && row.host.ID == id {
+			sidebar.selectedIdx = i
+			return
+		}
+	}
+}
+
+// CollapsedGroups lists the paths of the collapsed groups, for saving
+func (sidebar *HostsSidebar) CollapsedGroups() []string {
+	var paths []string
+	for path := range sidebar.collapsed {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (sidebar *HostsSidebar) SetCollapsedGroups(paths []string) {
+	sidebar.collapsed = make(map[string]bool)
+	for _, path := range paths {
+		if path != "" {
+			sidebar.collapsed[path] = true
+		}
 	}
+	sidebar.rebuild()
 }
 
 func (sidebar *HostsSidebar) IsFilterActive() bool {
@@ -48,30 +132,342 @@ func (sidebar *HostsSidebar) SetFilterActive(active bool) {
 }
 
 func (sidebar *HostsSidebar) GetSelected() *models.Host {
-	if sidebar.selectedIdx >= 0 && sidebar.selectedIdx < len(sidebar.filteredHosts) {
-		return &sidebar.filteredHosts[sidebar.selectedIdx]
+	if sidebar.selectedIdx >= 0 && sidebar.selectedIdx < len(sidebar.rows) {
+		return sidebar.rows[sidebar.selectedIdx].host
 	}
 	return nil
 }
 
-func (sidebar *HostsSidebar) applyFilter() {
-	if sidebar.filterText == "" {
-		sidebar.filteredHosts = sidebar.allHosts
+// GetSelectedGroup returns the path of the selected group header, if any
+func (sidebar *HostsSidebar) GetSelectedGroup() (string, bool) {
+	if sidebar.selectedIdx >= 0 && sidebar.selectedIdx < len(sidebar.rows) {
+		if group := sidebar.rows[sidebar.selectedIdx].group; group != nil {
+			return group.Path, true
+		}
+	}
+	return "", false
+}
+
+// ToggleSelectedGroup collapses or expands the selected group. Matches
+// are listed without their groups while filtering, so it does nothing then.
+func (sidebar *HostsSidebar) ToggleSelectedGroup() bool {
+	path, ok := sidebar.GetSelectedGroup()
+	if !ok || len(sidebar.filterText) > 0 {
+		return false
+	}
+	if sidebar.collapsed[path] {
+		delete(sidebar.collapsed, path)
 	} else {
-		sidebar.filteredHosts = []models.Host{}
-		filterLower := strings.ToLower(sidebar.filterText)
-		for _, host := range sidebar.allHosts {
-			if strings.Contains(strings.ToLower(host.Name), filterLower) {
-				sidebar.filteredHosts = append(sidebar.filteredHosts, host)
+		sidebar.collapsed[path] = true
+	}
+	sidebar.rebuild()
+	return true
+}
+
+// MoveSelected moves the selected host up (delta < 0) or down among the
+// hosts of its group. It returns the group's new order as host IDs, or
+// nil when the host cannot move.
+func (sidebar *HostsSidebar) MoveSelected(delta int) []uint {
+	if len(sidebar.filterText) > 0 || sidebar.selectedIdx < 0 || sidebar.selectedIdx >= len(sidebar.rows) {
+		return nil
+	}
+	row := sidebar.rows[sidebar.selectedIdx]
+	if row.host == nil {
+		return nil
+	}
+
+	ids := hostIDs(row.parent.Hosts)
+	from := slices.Index(ids, row.host.ID)
+	to := from + delta
+	if to < 0 || to >= len(ids) {
+		return nil
+	}
+	ids[from], ids[to] = ids[to], ids[from]
+	return ids
+}
+
+// DropSelected moves the selected host onto the row at index: into a
+// group when it is a header, or to the place of another host. It returns
+// the host's group and that group's new order as host IDs.
+func (sidebar *HostsSidebar) DropSelected(index int) (string, []uint, bool) {
+	if len(sidebar.filterText) > 0 || index == sidebar.selectedIdx || index < 0 || index >= len(sidebar.rows) {
+		return "", nil, false
+	}
+	source := sidebar.GetSelected()
+	if source == nil {
+		return "", nil, false
+	}
+
+	target := sidebar.rows[index]
+	if target.group != nil {
+		ids := []uint{source.ID}
+		for _, id := range hostIDs(target.group.Hosts) {
+			if id != source.ID {
+				ids = append(ids, id)
+			}
+		}
+		return target.group.Path, ids, true
+	}
+
+	// Moving down in the same group, the host goes after the target
+	siblings := hostIDs(target.parent.Hosts)
+	sameGroup := slices.Contains(siblings, source.ID)
+	position := slices.Index(siblings, target.host.ID)
+	if sameGroup && slices.Index(siblings, source.ID) < position {
+		position++
+	}
+
+	var ids []uint
+	for i, id := range siblings {
+		if i == position {
+			ids = append(ids, source.ID)
+		}
+		if id != source.ID {
+			ids = append(ids, id)
+		}
+	}
+	if position >= len(siblings) {
+		ids = append(ids, source.ID)
+	}
+	return target.parent.Path, ids, true
+}
+
+// RowAt returns the index of the row shown on a line of the sidebar,
+// counted from its top, or -1
+func (sidebar *HostsSidebar) RowAt(line int) int {
+	start, end := sidebar.pageBounds()
+	// The filter and its margin come first
+	line -= 2
+	for i := start; i < end && line >= 0; i++ {
+		height := rowHeight(sidebar.rows[i])
+		if line < height {
+			return i
+		}
+		line -= height
+	}
+	return -1
+}
+
+// Select moves the selection to a row, as returned by RowAt
+func (sidebar *HostsSidebar) Select(index int) {
+	if index >= 0 && index < len(sidebar.rows) {
+		sidebar.selectedIdx = index
+	}
+}
+
+func hostIDs(hosts []models.Host) []uint {
+	ids := make([]uint, len(hosts))
+	for i, host := range hosts {
+		ids[i] = host.ID
+	}
+	return ids
+}
+
+// matchHost checks every word of the filter against the host and adds
+// up the scores. A word starting with # only matches tags, user@host
+// matches the username and hostname, and host:port the port. Other words
+// match the best of the name, hostname, port, username, group or a tag.
+func (sidebar *HostsSidebar) matchHost(host models.Host) (hostMatch, bool) {
+	var match hostMatch
+	username := sidebar.usernames[host.ID]
+	tags := hostgroup.ParseTags(host.Tags)
+	port := strconv.Itoa(host.Port)
+
+	// best keeps the highest scoring of the fields, remembering which one
+	// so the name or hostname can be highlighted
+	type candidate struct {
+		text      string
+		positions *[]int
+	}
+	best := func(pattern string, candidates ...candidate) bool {
+		var top fuzzy.Result
+		var topPositions *[]int
+		found := false
+		for _, candidate := range candidates {
+			result, ok := fuzzy.Match(pattern, candidate.text)
+			if ok && (!found || result.Score > top.Score) {
+				top, topPositions, found = result, candidate.positions, true
 			}
 		}
+		if found {
+			match.score += top.Score
+			if topPositions != nil {
+				*topPositions = append(*topPositions, top.Positions...)
+			}
+		}
+		return found
 	}
 
-	if sidebar.selectedIdx >= len(sidebar.filteredHosts) && len(sidebar.filteredHosts) > 0 {
+	name := candidate{host.Name, &match.name}
+	hostname := candidate{host.Hostname, &match.hostname}
+	for _, word := range strings.Fields(string(sidebar.filterText)) {
+		if tag, ok := strings.CutPrefix(word, "#"); ok {
+			var tagCandidates []candidate
+			for _, hostTag := range tags {
+				tagCandidates = append(tagCandidates, candidate{hostTag, nil})
+			}
+			if len(tagCandidates) == 0 || !best(tag, tagCandidates...) {
+				return match, false
+			}
+			continue
+		}
+
+		if user, address, ok := strings.Cut(word, "@"); ok {
+			if user != "" && !best(user, candidate{username, nil}) {
+				return match, false
+			}
+			if address != "" && !best(address, hostname, name) {
+				return match, false
+			}
+			continue
+		}
+
+		// host:port, but not an IPv6 address
+		if address, portPrefix, ok := strings.Cut(word, ":"); ok && !strings.Contains(portPrefix, ":") && isDigits(portPrefix) {
+			if !strings.HasPrefix(port, portPrefix) {
+				return match, false
+			}
+			if address != "" && !best(address, hostname, name) {
+				return match, false
+			}
+			continue
+		}
+
+		candidates := []candidate{name, hostname, {port, nil}, {username, nil}, {host.Group, nil}}
+		for _, hostTag := range tags {
+			candidates = append(candidates, candidate{hostTag, nil})
+		}
+		if !best(word, candidates...) {
+			return match, false
+		}
+	}
+	return match, true
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// rebuild lays out the rows again, keeping the selection where possible
+func (sidebar *HostsSidebar) rebuild() {
+	var selectedHost uint
+	selectedGroup, groupSelected := sidebar.GetSelectedGroup()
+	if host := sidebar.GetSelected(); host != nil {
+		selectedHost = host.ID
+	}
+
+	sidebar.buildRows()
+
+	for i, row := range sidebar.rows {
+		if (row.host != nil && row.host.ID == selectedHost) || (groupSelected && row.group != nil && row.group.Path == selectedGroup) {
+			sidebar.selectedIdx = i
+			return
+		}
+	}
+	if sidebar.selectedIdx >= len(sidebar.rows) {
 		sidebar.selectedIdx = 0
 	}
 }
 
+// applyFilter lays out the rows for a changed filter and selects the
+// first host that matches
+func (sidebar *HostsSidebar) applyFilter() {
+	sidebar.buildRows()
+	sidebar.selectedIdx = 0
+	for i, row := range sidebar.rows {
+		if row.host != nil {
+			sidebar.selectedIdx = i
+			return
+		}
+	}
+}
+
+func (sidebar *HostsSidebar) buildRows() {
+	sidebar.rows = []hostsSidebarRow{}
+	sidebar.matches = make(map[uint]hostMatch)
+	if len(sidebar.filterText) == 0 {
+		sidebar.addRows(hostgroup.Build(sidebar.allHosts), 0)
+		return
+	}
+
+	// Matches are ranked in a flat list, best first
+	var hosts []models.Host
+	for _, host := range sidebar.allHosts {
+		if match, ok := sidebar.matchHost(host); ok {
+			sidebar.matches[host.ID] = match
+			hosts = append(hosts, host)
+		}
+	}
+	slices.SortStableFunc(hosts, func(a, b models.Host) int {
+		if order := cmp.Compare(sidebar.matches[b.ID].score, sidebar.matches[a.ID].score); order != 0 {
+			return order
+		}
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	for i := range hosts {
+		sidebar.rows = append(sidebar.rows, hostsSidebarRow{host: &hosts[i]})
+	}
+}
+
+func (sidebar *HostsSidebar) addRows(node *hostgroup.Node, depth int) {
+	for _, group := range node.Groups {
+		sidebar.rows = append(sidebar.rows, hostsSidebarRow{group: group, parent: node, depth: depth})
+		if !sidebar.collapsed[group.Path] {
+			sidebar.addRows(group, depth+1)
+		}
+	}
+	for i := range node.Hosts {
+		sidebar.rows = append(sidebar.rows, hostsSidebarRow{host: &node.Hosts[i], parent: node, depth: depth})
+	}
+}
+
+func rowHeight(row hostsSidebarRow) int {
+	if row.group != nil {
+		return groupRowHeight
+	}
+	return hostRowHeight
+}
+
+// pages splits the rows into pages that fit the sidebar, returning the
+// index of the first row of each
+func (sidebar *HostsSidebar) pages() []int {
+	availableHeight := shared.GlobalState.ScreenHeight - 8
+	pageHeight := max(availableHeight/hostRowHeight, 1) * hostRowHeight
+
+	starts := []int{0}
+	used := 0
+	for i, row := range sidebar.rows {
+		height := rowHeight(row)
+		if used > 0 && used+height > pageHeight {
+			starts = append(starts, i)
+			used = 0
+		}
+		used += height
+	}
+	return starts
+}
+
+// pageBounds returns the rows of the page holding the selection
+func (sidebar *HostsSidebar) pageBounds() (int, int) {
+	starts := sidebar.pages()
+	page := 0
+	for i, start := range starts {
+		if sidebar.selectedIdx >= start {
+			page = i
+		}
+	}
+	end := len(sidebar.rows)
+	if page+1 < len(starts) {
+		end = starts[page+1]
+	}
+	return starts[page], end
+}
+
 func (sidebar *HostsSidebar) Update(event interface{}) {
 	if msg, ok := event.(tea.Msg); ok {
 		switch key := msg.(type) {
@@ -79,36 +475,26 @@ func (sidebar *HostsSidebar) Update(event interface{}) {
 			if sidebar.filterActive {
 				switch key.Type {
 				case tea.KeyEscape:
-					var selectedHost *models.Host
-					if sidebar.selectedIdx >= 0 && sidebar.selectedIdx < len(sidebar.filteredHosts) {
-						selectedHost = &sidebar.filteredHosts[sidebar.selectedIdx]
-					}
+					selectedHost := sidebar.GetSelected()
 
 					sidebar.filterActive = false
-					sidebar.filterText = ""
+					sidebar.filterText = nil
 					sidebar.filterCursorPos = 0
 					sidebar.applyFilter()
 
 					if selectedHost != nil {
-						for i, host := range sidebar.allHosts {
-							if host.ID == selectedHost.ID {
-								sidebar.selectedIdx = i
-								break
-							}
-						}
+						sidebar.SelectHost(selectedHost.ID)
 					}
 				case tea.KeyBackspace:
 					if sidebar.filterCursorPos > 0 {
-						sidebar.filterText = sidebar.filterText[:sidebar.filterCursorPos-1] + sidebar.filterText[sidebar.filterCursorPos:]
+						sidebar.filterText = slices.Delete(sidebar.filterText, sidebar.filterCursorPos-1, sidebar.filterCursorPos)
 						sidebar.filterCursorPos--
 						sidebar.applyFilter()
-						sidebar.selectedIdx = 0
 					}
 				case tea.KeyDelete:
 					if sidebar.filterCursorPos < len(sidebar.filterText) {
-						sidebar.filterText = sidebar.filterText[:sidebar.filterCursorPos] + sidebar.filterText[sidebar.filterCursorPos+1:]
+						sidebar.filterText = slices.Delete(sidebar.filterText, sidebar.filterCursorPos, sidebar.filterCursorPos+1)
 						sidebar.applyFilter()
-						sidebar.selectedIdx = 0
 					}
 				case tea.KeyLeft:
 					if sidebar.filterCursorPos > 0 {
@@ -127,38 +513,50 @@ func (sidebar *HostsSidebar) Update(event interface{}) {
 						sidebar.selectedIdx--
 					}
 				case tea.KeyDown:
-					if sidebar.selectedIdx < len(sidebar.filteredHosts)-1 {
+					if sidebar.selectedIdx < len(sidebar.rows)-1 {
 						sidebar.selectedIdx++
 					}
 				default:
-					if len(key.Runes) > 0 && key.Runes[0] >= 32 && key.Runes[0] < 127 {
-						sidebar.filterText = sidebar.filterText[:sidebar.filterCursorPos] + string(key.Runes[0]) + sidebar.filterText[sidebar.filterCursorPos:]
-						sidebar.filterCursorPos++
+					// Pasted text arrives as several runes at once
+					var typed []rune
+					for _, r := range key.Runes {
+						if unicode.IsPrint(r) {
+							typed = append(typed, r)
+						}
+					}
+					if len(typed) > 0 {
+						sidebar.filterText = slices.Insert(sidebar.filterText, sidebar.filterCursorPos, typed...)
+						sidebar.filterCursorPos += len(typed)
 						sidebar.applyFilter()
-						sidebar.selectedIdx = 0
 					}
 				}
 			} else {
-				itemsPerPage := (shared.GlobalState.ScreenHeight - 5) / 3
-				if itemsPerPage < 1 {
-					itemsPerPage = 1
-				}
-
 				switch key.String() {
 				case "/":
 					sidebar.filterActive = true
-					sidebar.filterText = ""
+					sidebar.filterText = nil
 					sidebar.filterCursorPos = 0
 				case "up":
 					if sidebar.selectedIdx > 0 {
 						sidebar.selectedIdx--
 					}
 				case "down":
-					if sidebar.selectedIdx < len(sidebar.filteredHosts)-1 {
+					if sidebar.selectedIdx < len(sidebar.rows)-1 {
 						sidebar.selectedIdx++
 					}
 				}
 			}
+		case tea.MouseMsg:
+			switch key.Button {
+			case tea.MouseButtonWheelUp:
+				if sidebar.selectedIdx > 0 {
+					sidebar.selectedIdx--
+				}
+			case tea.MouseButtonWheelDown:
+				if sidebar.selectedIdx < len(sidebar.rows)-1 {
+					sidebar.selectedIdx++
+				}
+			}
 		}
 	}
 }
@@ -166,14 +564,12 @@ func (sidebar *HostsSidebar) Update(event interface{}) {
 func (sidebar *HostsSidebar) Render() string {
 	availableHeight := shared.GlobalState.ScreenHeight - 8
 
-	itemsPerPage := max((availableHeight)/3, 1)
-
 	var filterPart string
 	if sidebar.filterActive {
-		before := sidebar.filterText[:sidebar.filterCursorPos]
+		before := string(sidebar.filterText[:sidebar.filterCursorPos])
 		after := ""
 		if sidebar.filterCursorPos < len(sidebar.filterText) {
-			after = sidebar.filterText[sidebar.filterCursorPos+1:]
+			after = string(sidebar.filterText[sidebar.filterCursorPos+1:])
 		}
 		cursorChar := " "
 		if sidebar.filterCursorPos < len(sidebar.filterText) {
@@ -191,7 +587,7 @@ func (sidebar *HostsSidebar) Render() string {
 	var content string
 	var bottomContent string
 
-	if len(sidebar.filteredHosts) == 0 {
+	if len(sidebar.rows) == 0 {
 		noHostsPart := styles.SidebarNormalDesc.Render("No Hosts Found!")
 		content = lipgloss.JoinVertical(lipgloss.Left, filterPart, noHostsPart)
 
@@ -202,28 +598,24 @@ func (sidebar *HostsSidebar) Render() string {
 			content = lipgloss.JoinVertical(lipgloss.Left, content, spacer)
 		}
 	} else {
-		totalPages := (len(sidebar.filteredHosts) + itemsPerPage - 1) / itemsPerPage
-		if totalPages == 0 {
-			totalPages = 1
-		}
+		starts := sidebar.pages()
+		totalPages := len(starts)
+		pageStartIdx, pageEndIdx := sidebar.pageBounds()
+		currentPage := slices.Index(starts, pageStartIdx)
 
-		currentPage := (sidebar.selectedIdx / itemsPerPage)
-		pageStartIdx := currentPage * itemsPerPage
-		pageEndIdx := pageStartIdx + itemsPerPage
-		if pageEndIdx > len(sidebar.filteredHosts) {
-			pageEndIdx = len(sidebar.filteredHosts)
-		}
-
-		var hostItems []string
+		var rowItems []string
 		for i := pageStartIdx; i < pageEndIdx; i++ {
-			host := sidebar.filteredHosts[i]
+			row := sidebar.rows[i]
 			isSelected := i == sidebar.selectedIdx
-			line := sidebar.formatHostLine(host, isSelected)
-			hostItems = append(hostItems, line)
+			if row.group != nil {
+				rowItems = append(rowItems, sidebar.formatGroupLine(row, isSelected))
+			} else {
+				rowItems = append(rowItems, sidebar.formatHostLine(*row.host, row.depth, isSelected))
+			}
 		}
 
-		if len(hostItems) > 0 {
-			itemsContent := lipgloss.JoinVertical(lipgloss.Left, hostItems...)
+		if len(rowItems) > 0 {
+			itemsContent := lipgloss.JoinVertical(lipgloss.Left, rowItems...)
 			content = lipgloss.JoinVertical(lipgloss.Left, filterPart, itemsContent)
 		} else {
 			content = filterPart
@@ -231,7 +623,7 @@ func (sidebar *HostsSidebar) Render() string {
 
 		start := pageStartIdx + 1
 		end := pageEndIdx
-		totalItems := len(sidebar.filteredHosts)
+		totalItems := len(sidebar.rows)
 		pageNum := currentPage + 1
 
 		var dots strings.Builder
@@ -270,26 +662,107 @@ func (sidebar *HostsSidebar) Render() string {
 	return content
 }
 
-func (sidebar *HostsSidebar) formatHostLine(host models.Host, isSelected bool) string {
+func (sidebar *HostsSidebar) formatGroupLine(row hostsSidebarRow, isSelected bool) string {
+	arrow := "▾ "
+	if sidebar.collapsed[row.group.Path] {
+		arrow = "▸ "
+	}
+	indent := strings.Repeat(" ", row.depth*groupIndent)
+	count := fmt.Sprintf(" %d", row.group.Count())
+	name := truncate(row.group.Name, sidebarTextWidth-len(indent)-len(arrow)-len(count))
+
+	style := styles.SidebarGroup
+	if isSelected {
+		style = styles.SidebarGroupSelected
+	}
+	return indent + style.Render(arrow+name) + styles.SidebarGroupCount.Render(count)
+}
+
+func (sidebar *HostsSidebar) formatHostLine(host models.Host, depth int, isSelected bool) string {
 	title := host.Name
 	desc := ""
 	if host.Hostname == "" {
 		desc = fmt.Sprintf(":%d", host.Port)
 	} else {
-		desc = fmt.Sprintf("%s:%d", host.Hostname, host.Port)
+		desc = network.JoinHostPort(host.Hostname, host.Port)
 	}
 
+	// Tags follow the address as far as they fit
+	tagWidth := sidebarTextWidth - depth*groupIndent - lipgloss.Width(desc) - 1
+	var tags string
+	if tagList := hostgroup.ParseTags(host.Tags); len(tagList) > 0 && tagWidth > 1 {
+		tags = " " + styles.SidebarTag.Render(truncate("#"+strings.Join(tagList, " #"), tagWidth))
+	}
+
+	// The hostname sits inside brackets in IPv6 addresses
+	match := sidebar.matches[host.ID]
+	hostnamePositions := match.hostname
+	if offset := strings.Index(desc, host.Hostname); offset > 0 && host.Hostname != "" {
+		shift := utf8.RuneCountInString(desc[:offset])
+		hostnamePositions = make([]int, len(match.hostname))
+		for i, position := range match.hostname {
+			hostnamePositions[i] = position + shift
+		}
+	}
+
+	var item string
 	if isSelected {
-		styledTitle := styles.SidebarSelectedTitle.Render(title)
-		styledDesc := styles.SidebarSelectedDesc.Render(desc)
-		item := lipgloss.JoinVertical(lipgloss.Left, styledTitle, styledDesc)
-		bordered := styles.SidebarSelectedBorder.Render(item)
-		return styles.SidebarItemMargin.Render(bordered)
-	}
-
-	styledTitle := styles.SidebarNormalTitle.Render(title)
-	styledDesc := styles.SidebarNormalDesc.Render(desc)
-	item := lipgloss.JoinVertical(lipgloss.Left, styledTitle, styledDesc)
-	padded := styles.SidebarNormalPadding.Render(item)
-	return styles.SidebarItemMargin.Render(padded)
+		styledTitle := highlight(title, match.name, styles.SidebarSelectedTitle)
+		styledDesc := highlight(desc, hostnamePositions, styles.SidebarSelectedDesc) + tags
+		item = styles.SidebarSelectedBorder.Render(lipgloss.JoinVertical(lipgloss.Left, styledTitle, styledDesc))
+	} else {
+		styledTitle := highlight(title, match.name, styles.SidebarNormalTitle)
+		styledDesc := highlight(desc, hostnamePositions, styles.SidebarNormalDesc) + tags
+		item = styles.SidebarNormalPadding.Render(lipgloss.JoinVertical(lipgloss.Left, styledTitle, styledDesc))
+	}
+	return styles.SidebarItemMargin.MarginLeft(depth * groupIndent).Render(item)
+}
+
+// highlight renders text in style, marking the runes at positions as
+// matched by the filter
+func highlight(text string, positions []int, style lipgloss.Style) string {
+	if len(positions) == 0 {
+		return style.Render(text)
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		matched[position] = true
+	}
+
+	var builder strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			builder.WriteString(styles.SidebarMatch.Inherit(style).Render(string(run)))
+		} else {
+			builder.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return builder.String()
+}
+
+// truncate shortens text to width cells, ending it with an ellipsis
+func truncate(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
 }
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"fmt"
 	"yoru/screens/styles"
 	"yoru/shared"
 	"yoru/types"
@@ -8,6 +9,11 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+const (
+	tabNameMaxWidth  = 24
+	tabOverflowWidth = 6
+)
+
 var TabBar = &tabBar{}
 
 func (tabBar *tabBar) AddTab(tab types.Tab) {
@@ -17,6 +23,14 @@ func (tabBar *tabBar) AddTab(tab types.Tab) {
 	}
 }
 
+func (tabBar *tabBar) GetTabs() []types.Tab {
+	return tabBar.tabs
+}
+
+func (tabBar *tabBar) GetActiveIndex() int {
+	return tabBar.activeIndex
+}
+
 func (tabBar *tabBar) GetCurrentScreen() types.Screen {
 	if tabBar.activeIndex < 0 || tabBar.activeIndex >= len(tabBar.tabs) {
 		return nil
@@ -31,6 +45,41 @@ func (tabBar *tabBar) UpdateCurrentScreen(screen types.Screen) {
 	}
 }
 
+func (tabBar *tabBar) UpdateScreen(index int, screen types.Screen) {
+	if index >= 0 && index < len(tabBar.tabs) {
+		tabBar.tabs[index].Screen = screen
+	}
+}
+
+func (tabBar *tabBar) RenameTab(index int, name string) {
+	if index >= 0 && index < len(tabBar.tabs) {
+		tabBar.tabs[index].Name = name
+		tabBar.tabs[index].Renamed = true
+	}
+}
+
+// MoveTab moves the tab at index by delta places, keeping it active if it
+// was. The home tab stays first.
+func (tabBar *tabBar) MoveTab(index int, delta int) {
+	target := index + delta
+	if index <= 0 || index >= len(tabBar.tabs) || target <= 0 || target >= len(tabBar.tabs) {
+		return
+	}
+
+	tab := tabBar.tabs[index]
+	tabBar.tabs = append(tabBar.tabs[:index], tabBar.tabs[index+1:]...)
+	tabBar.tabs = append(tabBar.tabs[:target], append([]types.Tab{tab}, tabBar.tabs[target:]...)...)
+
+	switch {
+	case tabBar.activeIndex == index:
+		tabBar.activeIndex = target
+	case index < tabBar.activeIndex && tabBar.activeIndex <= target:
+		tabBar.activeIndex--
+	case target <= tabBar.activeIndex && tabBar.activeIndex < index:
+		tabBar.activeIndex++
+	}
+}
+
 func (tabBar *tabBar) SwitchToTab(index int) {
 	if index < 0 || index >= len(tabBar.tabs) {
 		return
@@ -63,10 +112,17 @@ func (tabBar *tabBar) SwitchToLastTab() {
 }
 
 func (tabBar *tabBar) RemoveCurrentTab() {
-	if len(tabBar.tabs) <= 1 {
-		return // never remove the last tab
+	tabBar.RemoveTab(tabBar.activeIndex)
+}
+
+func (tabBar *tabBar) RemoveTab(index int) {
+	if index <= 0 || index >= len(tabBar.tabs) {
+		return // never remove the home tab
+	}
+	tabBar.tabs = append(tabBar.tabs[:index], tabBar.tabs[index+1:]...)
+	if index < tabBar.activeIndex {
+		tabBar.activeIndex--
 	}
-	tabBar.tabs = append(tabBar.tabs[:tabBar.activeIndex], tabBar.tabs[tabBar.activeIndex+1:]...)
 	if tabBar.activeIndex >= len(tabBar.tabs) {
 		tabBar.activeIndex = len(tabBar.tabs) - 1
 	}
@@ -78,29 +134,117 @@ func (tabBar *tabBar) Render() string {
 	}
 
 	measureWidth := lipgloss.Width
-	var renderedTabs []string
+	screenWidth := shared.GlobalState.ScreenWidth
+	renderedTabs := make([]string, len(tabBar.tabs))
+	widths := make([]int, len(tabBar.tabs))
 
 	for index, tab := range tabBar.tabs {
-		name := tab.Name
+		name := tab.Title()
+		if runes := []rune(name); len(runes) > tabNameMaxWidth {
+			name = string(runes[:tabNameMaxWidth-1]) + "…"
+		}
 		if kc, ok := tab.Screen.(types.KeyCapturer); ok && kc.GetKeyCaptureMode() == types.KeyCaptureTerminal {
 			name = "*" + name
 		}
+		if recorder, ok := tab.Screen.(types.SessionRecorder); ok && recorder.IsRecording() {
+			name = "● " + name
+		}
+		syncing := false
+		if syncer, ok := tab.Screen.(types.InputSyncer); ok && syncer.IsSyncingInput() {
+			name = "⇉ " + name
+			syncing = true
+		}
+		activity := types.ActivityNone
+		if reporter, ok := tab.Screen.(types.ActivityReporter); ok && index != tabBar.activeIndex {
+			activity = reporter.Activity()
+		}
+		switch activity {
+		case types.ActivityOutput:
+			name = "+ " + name
+		case types.ActivityBell:
+			name = "! " + name
+		case types.ActivityExited:
+			name = "✗ " + name
+		}
+
 		if index == tabBar.activeIndex {
-			content := styles.ActiveTab.Render(" " + name + " ")
-			renderedTabs = append(renderedTabs, content)
+			renderedTabs[index] = styles.ActiveTab.Render(" " + name + " ")
+		} else if activity >= types.ActivityBell {
+			// What needs a look stands out more than anything else
+			renderedTabs[index] = styles.AlertTab.Render(" " + name + " ")
+		} else if syncing {
+			// Typing in a synchronized tab also goes here
+			renderedTabs[index] = styles.SyncedTab.Render(" " + name + " ")
+		} else if activity == types.ActivityOutput {
+			renderedTabs[index] = styles.ActivityTab.Render(" " + name + " ")
 		} else {
-			content := styles.InactiveTab.Render(" " + name + " ")
-			renderedTabs = append(renderedTabs, content)
+			renderedTabs[index] = styles.InactiveTab.Render(" " + name + " ")
 		}
+		widths[index] = measureWidth(renderedTabs[index])
 	}
 
-	tabsContent := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
-	remainingWidth := shared.GlobalState.ScreenWidth - measureWidth(tabsContent)
+	first, end := tabBar.visibleTabs(widths, screenWidth)
+	shown := append([]string(nil), renderedTabs[first:end]...)
+	if first > 0 || end < len(renderedTabs) {
+		// Say how many tabs are out of view on each side
+		var before, after string
+		if first > 0 {
+			before = fmt.Sprintf("‹ %d", first)
+		}
+		if end < len(renderedTabs) {
+			after = fmt.Sprintf("%d ›", len(renderedTabs)-end)
+		}
+		shown = append([]string{styles.TabOverflow.Width(tabOverflowWidth).Render(before)}, shown...)
+		shown = append(shown, styles.TabOverflow.Width(tabOverflowWidth).Render(after))
+	}
+
+	tabsContent := lipgloss.JoinHorizontal(lipgloss.Top, shown...)
+	remainingWidth := screenWidth - measureWidth(tabsContent)
 
 	if remainingWidth > 0 {
 		gap := styles.TabBarBackground.Width(remainingWidth).Render("")
 		tabsContent = lipgloss.JoinHorizontal(lipgloss.Top, tabsContent, gap)
 	}
 
-	return styles.TabBarBackground.Width(shared.GlobalState.ScreenWidth).Render(tabsContent)
+	return styles.TabBarBackground.Width(screenWidth).MaxWidth(screenWidth).Render(tabsContent)
+}
+
+// visibleTabs picks the tabs to show when they do not all fit in width,
+// scrolling only as far as needed to keep the active tab in view
+func (tabBar *tabBar) visibleTabs(widths []int, width int) (int, int) {
+	total := 0
+	for _, tabWidth := range widths {
+		total += tabWidth
+	}
+	if total <= width {
+		tabBar.scroll = 0
+		return 0, len(widths)
+	}
+
+	room := width - 2*tabOverflowWidth
+	active := max(0, min(tabBar.activeIndex, len(widths)-1))
+	first := min(tabBar.scroll, active)
+
+	used := 0
+	for index := first; index <= active; index++ {
+		used += widths[index]
+	}
+	for used > room && first < active {
+		used -= widths[first]
+		first++
+	}
+
+	end := active + 1
+	for end < len(widths) && used+widths[end] <= room {
+		used += widths[end]
+		end++
+	}
+	// Fill what is left with the tabs before
+	for first > 0 && used+widths[first-1] <= room {
+		first--
+		used += widths[first]
+	}
+
+	tabBar.scroll = first
+	return first, end
 }
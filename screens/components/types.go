@@ -6,6 +6,7 @@ type tabBar struct {
 	types.TabBar
 	tabs        []types.Tab
 	activeIndex int
+	scroll      int // first tab shown when they do not all fit
 }
 
 type navBar struct {
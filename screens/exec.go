@@ -0,0 +1,291 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"yoru/models"
+	"yoru/screens/styles"
+	"yoru/shared"
+	"yoru/ssh"
+	"yoru/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const execNameWidth = 24
+
+// execResultMsg reports one host of a run; run discards results of a
+// previous run of the same screen
+type execResultMsg struct {
+	screen *execScreen
+	run    int
+	index  int
+	result ssh.ExecResult
+}
+
+// NewExecScreen creates a tab that runs command on hosts and lists how it
+// went on each
+func NewExecScreen(hosts []models.Host, command string) *execScreen {
+	return &execScreen{
+		hosts:    hosts,
+		command:  command,
+		results:  make([]*ssh.ExecResult, len(hosts)),
+		expanded: make(map[int]bool),
+	}
+}
+
+func (screen *execScreen) Init() tea.Cmd {
+	return screen.start()
+}
+
+// start runs the command on every host again
+func (screen *execScreen) start() tea.Cmd {
+	screen.run++
+	screen.results = make([]*ssh.ExecResult, len(screen.hosts))
+	screen.finished = 0
+	screen.startedAt = time.Now()
+
+	run := screen.run
+	hosts := append([]models.Host(nil), screen.hosts...)
+	command := screen.command
+	return func() tea.Msg {
+		go ssh.ExecAll(hosts, command, ssh.DefaultExecConcurrency, ssh.DefaultExecTimeout, func(index int, result ssh.ExecResult) {
+			shared.SendMessage(execResultMsg{screen: screen, run: run, index: index, result: result})
+		})
+		return nil
+	}
+}
+
+func (screen *execScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
+	switch message := msg.(type) {
+	case execResultMsg:
+		if message.screen != screen || message.run != screen.run {
+			return screen, nil
+		}
+		result := message.result
+		screen.results[message.index] = &result
+		screen.finished++
+		if screen.finished == len(screen.hosts) {
+			screen.elapsed = time.Since(screen.startedAt)
+		}
+		// Failures open up by themselves, they are what needs reading
+		if result.Failed() {
+			screen.expanded[message.index] = true
+		}
+	case tea.KeyMsg:
+		if cmd := screen.OnKeyPress(message); cmd != nil {
+			return screen, cmd
+		}
+	case tea.MouseMsg:
+		switch message.Button {
+		case tea.MouseButtonWheelUp:
+			screen.moveSelection(-1)
+		case tea.MouseButtonWheelDown:
+			screen.moveSelection(1)
+		}
+	}
+
+	return screen, nil
+}
+
+func (screen *execScreen) OnKeyPress(key tea.KeyMsg) tea.Cmd {
+	switch key.String() {
+	case "q", "esc":
+		return func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
+	case "up", "k":
+		screen.moveSelection(-1)
+	case "down", "j":
+		screen.moveSelection(1)
+	case "pgup":
+		screen.moveSelection(-10)
+	case "pgdown":
+		screen.moveSelection(10)
+	case "home":
+		screen.moveSelection(-len(screen.hosts))
+	case "end":
+		screen.moveSelection(len(screen.hosts))
+	case "enter", " ":
+		screen.expanded[screen.selectedIdx] = !screen.expanded[screen.selectedIdx]
+	case "e":
+		// Expand everything, or collapse everything if it already is
+		all := true
+		for index := range screen.hosts {
+			all = all && screen.expanded[index]
+		}
+		for index := range screen.hosts {
+			screen.expanded[index] = !all
+		}
+	case "r":
+		if screen.finished == len(screen.hosts) {
+			screen.expanded = make(map[int]bool)
+			return screen.start()
+		}
+	}
+	return nil
+}
+
+func (screen *execScreen) moveSelection(delta int) {
+	screen.selectedIdx = max(0, min(screen.selectedIdx+delta, len(screen.hosts)-1))
+}
+
+func (screen *execScreen) View() string {
+	width := shared.GlobalState.ScreenWidth
+	height := shared.GlobalState.ScreenHeight - 1
+
+	header := styles.ExecCommand.Render(ansi.Truncate("$ "+screen.command, width, "…"))
+
+	// Lay out every row, then show the window around the selected one
+	var lines []string
+	selectedStart, selectedEnd := 0, 0
+	for index := range screen.hosts {
+		if index == screen.selectedIdx {
+			selectedStart = len(lines)
+		}
+		lines = append(lines, screen.renderRow(index, width))
+		if screen.expanded[index] {
+			lines = append(lines, screen.renderOutput(index, width)...)
+		}
+		if index == screen.selectedIdx {
+			selectedEnd = len(lines)
+		}
+	}
+
+	visible := max(height-3, 1)
+	if selectedStart < screen.scroll {
+		screen.scroll = selectedStart
+	} else if selectedEnd > screen.scroll+visible {
+		screen.scroll = max(selectedStart, selectedEnd-visible)
+	}
+	screen.scroll = max(0, min(screen.scroll, len(lines)-1))
+	end := min(screen.scroll+visible, len(lines))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines[screen.scroll:end]...)
+	content = lipgloss.Place(width, visible, lipgloss.Left, lipgloss.Top, content)
+
+	status := styles.ExecStatus.Width(width).Render(ansi.Truncate(" "+screen.summary()+
+		"  |  ↑↓: Select  Enter: Output  e: Expand all  r: Run again  q: Close", width, "…"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", content, status)
+}
+
+func (screen *execScreen) summary() string {
+	failed := 0
+	for _, result := range screen.results {
+		if result != nil && result.Failed() {
+			failed++
+		}
+	}
+
+	if screen.finished < len(screen.hosts) {
+		return fmt.Sprintf("Running: %d/%d done, %d failed", screen.finished, len(screen.hosts), failed)
+	}
+	return fmt.Sprintf("Done in %s: %d succeeded, %d failed",
+		screen.elapsed.Round(100*time.Millisecond), len(screen.hosts)-failed, failed)
+}
+
+// renderRow is the one line summary of a host
+func (screen *execScreen) renderRow(index, width int) string {
+	host := screen.hosts[index]
+	result := screen.results[index]
+
+	marker := "  "
+	if index == screen.selectedIdx {
+		marker = "▸ "
+	}
+	name := host.Name
+	if len([]rune(name)) > execNameWidth {
+		name = string([]rune(name)[:execNameWidth-1]) + "…"
+	}
+
+	var status, detail string
+	switch {
+	case result == nil:
+		status = styles.ExecPending.Render("…")
+		detail = styles.ExecPending.Render("running")
+	case result.Error != nil:
+		status = styles.ExecFailure.Render("✗")
+		detail = styles.ExecFailure.Render(fmt.Sprintf("%-8s %7s  %v", "error", formatExecDuration(result.Duration), result.Error))
+	case result.ExitCode != 0:
+		status = styles.ExecFailure.Render("✗")
+		detail = styles.ExecFailure.Render(fmt.Sprintf("exit %-3d %7s", result.ExitCode, formatExecDuration(result.Duration))) +
+			"  " + firstLine(result.Stderr, result.Stdout)
+	default:
+		status = styles.ExecSuccess.Render("✓")
+		detail = fmt.Sprintf("exit %-3d %7s", 0, formatExecDuration(result.Duration)) + "  " + firstLine(result.Stdout, result.Stderr)
+	}
+
+	line := fmt.Sprintf("%s%s %-*s %s", marker, status, execNameWidth, name, detail)
+	line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+	if index == screen.selectedIdx {
+		return styles.ExecSelected.Width(width).Render(line)
+	}
+	return line
+}
+
+// renderOutput shows what a host printed, stderr after stdout
+func (screen *execScreen) renderOutput(index, width int) []string {
+	result := screen.results[index]
+	if result == nil {
+		return nil
+	}
+
+	indent := strings.Repeat(" ", 4) + "│ "
+	clip := lipgloss.NewStyle().MaxWidth(width)
+	var lines []string
+	for _, stream := range []struct {
+		text  string
+		style lipgloss.Style
+	}{{result.Stdout, styles.ExecStdout}, {result.Stderr, styles.ExecStderr}} {
+		for _, line := range outputLines(stream.text) {
+			lines = append(lines, clip.Render(indent+stream.style.Render(line)))
+		}
+	}
+	if len(lines) == 0 && result.Error == nil {
+		lines = append(lines, clip.Render(indent+styles.ExecPending.Render("(no output)")))
+	}
+	return lines
+}
+
+// outputLines splits command output for display, dropping the escape
+// sequences and control characters that would upset the layout
+func outputLines(text string) []string {
+	text = strings.TrimRight(ansi.Strip(text), "\n")
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		// Progress bars redraw with carriage returns; the last draw wins
+		if cr := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); cr >= 0 {
+			line = line[cr+1:]
+		}
+		line = strings.ReplaceAll(line, "\t", "    ")
+		lines[i] = strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return lines
+}
+
+func firstLine(texts ...string) string {
+	for _, text := range texts {
+		if lines := outputLines(text); len(lines) > 0 {
+			return lines[0]
+		}
+	}
+	return ""
+}
+
+func formatExecDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return fmt.Sprintf("%dms", duration.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", duration.Seconds())
+}
@@ -1,13 +1,18 @@
 package forms
 
 import (
-	"net"
-	"regexp"
+	"context"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 	"yoru/models"
 	"yoru/repository"
 	"yoru/screens/styles"
 	"yoru/types"
+	"yoru/utils/hostgroup"
+	"yoru/utils/network"
+	"yoru/utils/notify"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,10 +24,42 @@ const (
 	FieldHostname
 	FieldPort
 	FieldMode
+	FieldGroup
+	FieldTags
 	FieldIdentity
+	FieldRecording
+	FieldTranscript
+	FieldStaticTitle
+	FieldNotifyBell
+	FieldNotifyExit
+	FieldNotifyPatterns
 	TotalFields
 )
 
+const (
+	// resolveTimeout bounds the address lookup behind the hostname preview
+	resolveTimeout = 5 * time.Second
+
+	maxPreviewAddresses = 3
+)
+
+// hostnameErrors maps validation failures to the messages shown under the field
+var hostnameErrors = map[error]string{
+	network.ErrHostnameRequired: "Hostname is required",
+	network.ErrInvalidHostname:  "Invalid hostname or IP address",
+	network.ErrHostnameTooLong:  "Hostname is too long (max 253)",
+	network.ErrLabelTooLong:     "Hostname label is too long (max 63)",
+	network.ErrInvalidIPv6:      "Invalid IPv6 address",
+	network.ErrInvalidZone:      "Zone ID needs an IPv6 address",
+}
+
+// HostnameResolvedMsg carries the result of a resolve-and-preview lookup
+type HostnameResolvedMsg struct {
+	Hostname  string
+	Addresses []string
+	Err       error
+}
+
 const (
 	ModeSSH int = iota
 	ModeTelnet
@@ -40,10 +77,23 @@ type HostForm struct {
 	nameInput     textinput.Model
 	hostnameInput textinput.Model
 	portInput     textinput.Model
+	groupInput    textinput.Model
+	tagsInput     textinput.Model
+	patternsInput textinput.Model
 	modeIndex     int
+	alwaysRecord  bool
+	transcript    bool
+	staticTitle   bool
+	notifyBell    bool
+	notifyExit    bool
 
 	fieldErrors        map[int]string
 	lastSelectedHostID uint
+
+	resolving     bool
+	resolvedFor   string
+	resolved      []string
+	resolveFailed error
 }
 
 func NewHostForm() *HostForm {
@@ -54,8 +104,9 @@ func NewHostForm() *HostForm {
 	nameInput.Blur()
 
 	hostnameInput := textinput.New()
-	hostnameInput.Placeholder = "192.168.1.1"
-	hostnameInput.CharLimit = 15
+	hostnameInput.Placeholder = "host.example.com"
+	// 253 characters for a DNS name plus room for IPv6 brackets
+	hostnameInput.CharLimit = 255
 	hostnameInput.Width = 30
 	hostnameInput.Blur()
 
@@ -65,11 +116,32 @@ func NewHostForm() *HostForm {
 	portInput.Width = 8
 	portInput.Blur()
 
+	groupInput := textinput.New()
+	groupInput.Placeholder = "prod/eu-west"
+	groupInput.CharLimit = 255
+	groupInput.Width = 30
+	groupInput.Blur()
+
+	tagsInput := textinput.New()
+	tagsInput.Placeholder = "web, db"
+	tagsInput.CharLimit = 255
+	tagsInput.Width = 30
+	tagsInput.Blur()
+
+	patternsInput := textinput.New()
+	patternsInput.Placeholder = "BUILD SUCCESS, password:"
+	patternsInput.CharLimit = 1024
+	patternsInput.Width = 30
+	patternsInput.Blur()
+
 	return &HostForm{
 		activeMode:    types.ModeSSH,
 		nameInput:     nameInput,
 		hostnameInput: hostnameInput,
 		portInput:     portInput,
+		groupInput:    groupInput,
+		tagsInput:     tagsInput,
+		patternsInput: patternsInput,
 		fieldErrors:   make(map[int]string),
 	}
 }
@@ -81,6 +153,7 @@ func (form *HostForm) LoadHost(host *models.Host) {
 	form.fieldIndex = FieldName
 	form.modeIndex = ModeSSH
 	form.fieldErrors = make(map[int]string)
+	form.clearResolution()
 
 	if host.Mode == types.ModeTelnet {
 		form.modeIndex = ModeTelnet
@@ -94,13 +167,25 @@ func (form *HostForm) LoadHost(host *models.Host) {
 		form.selectedCredID = 0
 	}
 
+	form.alwaysRecord = host.AlwaysRecord
+	form.transcript = host.Transcript
+	form.staticTitle = host.StaticTitle
+	form.notifyBell = host.NotifyBell
+	form.notifyExit = host.NotifyExit
+
 	form.nameInput.SetValue(host.Name)
 	form.hostnameInput.SetValue(host.Hostname)
 	form.portInput.SetValue(strconv.Itoa(host.Port))
+	form.groupInput.SetValue(host.Group)
+	form.tagsInput.SetValue(strings.Join(hostgroup.ParseTags(host.Tags), ", "))
+	form.patternsInput.SetValue(host.NotifyPatterns)
 
 	form.nameInput.CursorEnd()
 	form.hostnameInput.CursorEnd()
 	form.portInput.CursorEnd()
+	form.groupInput.CursorEnd()
+	form.tagsInput.CursorEnd()
+	form.patternsInput.CursorEnd()
 
 	form.setFieldFocus()
 }
@@ -110,16 +195,28 @@ func (form *HostForm) Clear() {
 	form.lastSelectedHostID = 0
 	form.selectedCredType = ""
 	form.selectedCredID = 0
+	form.alwaysRecord = false
+	form.transcript = false
+	form.staticTitle = false
+	form.notifyBell = false
+	form.notifyExit = false
 	form.fieldErrors = make(map[int]string)
+	form.clearResolution()
 	form.nameInput.SetValue("")
 	form.hostnameInput.SetValue("")
 	form.portInput.SetValue("")
+	form.groupInput.SetValue("")
+	form.tagsInput.SetValue("")
+	form.patternsInput.SetValue("")
 }
 
 func (form *HostForm) setFieldFocus() {
 	form.nameInput.Blur()
 	form.hostnameInput.Blur()
 	form.portInput.Blur()
+	form.groupInput.Blur()
+	form.tagsInput.Blur()
+	form.patternsInput.Blur()
 
 	if !form.focused {
 		return
@@ -132,13 +229,19 @@ func (form *HostForm) setFieldFocus() {
 		form.hostnameInput.Focus()
 	case FieldPort:
 		form.portInput.Focus()
+	case FieldGroup:
+		form.groupInput.Focus()
+	case FieldTags:
+		form.tagsInput.Focus()
+	case FieldNotifyPatterns:
+		form.patternsInput.Focus()
 	}
 }
 
 func (form *HostForm) Save() {
 	if form.currentHost != nil {
 		form.currentHost.Name = form.nameInput.Value()
-		form.currentHost.Hostname = form.hostnameInput.Value()
+		form.currentHost.Hostname = network.NormalizeHostname(form.hostnameInput.Value())
 
 		if port, err := strconv.Atoi(form.portInput.Value()); err == nil && port > 0 && port <= 65535 {
 			form.currentHost.Port = port
@@ -158,19 +261,28 @@ func (form *HostForm) Save() {
 			form.currentHost.CredentialType = ""
 		}
 
+		form.currentHost.AlwaysRecord = form.alwaysRecord
+		form.currentHost.Transcript = form.transcript
+		form.currentHost.StaticTitle = form.staticTitle
+		form.currentHost.NotifyBell = form.notifyBell
+		form.currentHost.NotifyExit = form.notifyExit
+		form.currentHost.NotifyPatterns = notify.NormalizePatterns(form.patternsInput.Value())
+
+		group := hostgroup.NormalizePath(form.groupInput.Value())
+		if group != form.currentHost.Group {
+			// A host moved to another group goes to its top
+			form.currentHost.SortOrder = -1
+		}
+		form.currentHost.Group = group
+		form.currentHost.Tags = hostgroup.NormalizeTags(form.tagsInput.Value())
+
 		repository.UpdateHost(form.currentHost)
 	}
 }
 
 func (form *HostForm) validateHostname() {
-	value := form.hostnameInput.Value()
-	if value == "" {
-		form.fieldErrors[FieldHostname] = "Hostname is required"
-		return
-	}
-
-	if !isValidIP(value) {
-		form.fieldErrors[FieldHostname] = "Invalid IP format"
+	if err := network.ValidateHostname(form.hostnameInput.Value()); err != nil {
+		form.fieldErrors[FieldHostname] = hostnameErrors[err]
 	}
 }
 
@@ -208,24 +320,73 @@ func (form *HostForm) GetError(fieldIndex int) string {
 	return form.fieldErrors[fieldIndex]
 }
 
-func isValidIP(ip string) bool {
-	if net.ParseIP(ip) != nil {
-		return true
+// ResolveHostname starts a lookup of the hostname being edited so its
+// addresses can be previewed under the field
+func (form *HostForm) ResolveHostname() tea.Cmd {
+	if form.currentHost == nil || form.fieldIndex != FieldHostname {
+		return nil
 	}
 
-	ipRegex := regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}$`)
-	if !ipRegex.MatchString(ip) {
-		return false
+	delete(form.fieldErrors, FieldHostname)
+	form.validateHostname()
+	if _, invalid := form.fieldErrors[FieldHostname]; invalid {
+		return nil
 	}
 
-	parts := regexp.MustCompile(`\.`).Split(ip, -1)
-	for _, part := range parts {
-		if num, err := strconv.Atoi(part); err != nil || num > 255 {
-			return false
+	hostname := network.NormalizeHostname(form.hostnameInput.Value())
+	form.resolving = true
+	form.resolvedFor = hostname
+	form.resolved = nil
+	form.resolveFailed = nil
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+		defer cancel()
+		addresses, err := network.ResolveAddresses(ctx, hostname)
+		return HostnameResolvedMsg{Hostname: hostname, Addresses: addresses, Err: err}
+	}
+}
+
+// SetResolution records a finished lookup unless the hostname was edited meanwhile
+func (form *HostForm) SetResolution(msg HostnameResolvedMsg) {
+	if msg.Hostname != form.resolvedFor {
+		return
+	}
+	form.resolving = false
+	form.resolved = msg.Addresses
+	form.resolveFailed = msg.Err
+}
+
+func (form *HostForm) clearResolution() {
+	form.resolving = false
+	form.resolvedFor = ""
+	form.resolved = nil
+	form.resolveFailed = nil
+}
+
+// renderResolution shows the preview for the current hostname, or a hint
+// on how to request one while the field is focused
+func (form *HostForm) renderResolution() string {
+	if form.resolvedFor == "" || form.resolvedFor != network.NormalizeHostname(form.hostnameInput.Value()) {
+		if form.focused && form.fieldIndex == FieldHostname {
+			return styles.FormHint.Render("Ctrl+R: Resolve addresses")
 		}
+		return ""
 	}
 
-	return true
+	switch {
+	case form.resolving:
+		return styles.FormHint.Render("Resolving…")
+	case form.resolveFailed != nil:
+		return renderError("Unable to resolve " + form.resolvedFor)
+	case len(form.resolved) == 0:
+		return renderError("No addresses found")
+	}
+	addresses := form.resolved
+	if len(addresses) > maxPreviewAddresses {
+		addresses = append(addresses[:maxPreviewAddresses:maxPreviewAddresses], fmt.Sprintf("+%d more", len(form.resolved)-maxPreviewAddresses))
+	}
+	return styles.FormHint.Render("→ " + strings.Join(addresses, ", "))
 }
 
 func (form *HostForm) Update(event interface{}) {
@@ -247,7 +408,7 @@ func (form *HostForm) Update(event interface{}) {
 		}
 		return
 	case tea.KeyDown:
-		if form.fieldIndex < FieldIdentity {
+		if form.fieldIndex < TotalFields-1 {
 			form.validateCurrentField()
 			form.fieldIndex++
 			form.setFieldFocus()
@@ -263,6 +424,26 @@ func (form *HostForm) Update(event interface{}) {
 			}
 			return
 		}
+		if form.fieldIndex == FieldRecording {
+			form.alwaysRecord = !form.alwaysRecord
+			return
+		}
+		if form.fieldIndex == FieldTranscript {
+			form.transcript = !form.transcript
+			return
+		}
+		if form.fieldIndex == FieldStaticTitle {
+			form.staticTitle = !form.staticTitle
+			return
+		}
+		if form.fieldIndex == FieldNotifyBell {
+			form.notifyBell = !form.notifyBell
+			return
+		}
+		if form.fieldIndex == FieldNotifyExit {
+			form.notifyExit = !form.notifyExit
+			return
+		}
 	case tea.KeyLeft, tea.KeyRight:
 		switch form.fieldIndex {
 		case FieldName:
@@ -271,6 +452,12 @@ func (form *HostForm) Update(event interface{}) {
 			form.hostnameInput, _ = form.hostnameInput.Update(keyMsg)
 		case FieldPort:
 			form.portInput, _ = form.portInput.Update(keyMsg)
+		case FieldGroup:
+			form.groupInput, _ = form.groupInput.Update(keyMsg)
+		case FieldTags:
+			form.tagsInput, _ = form.tagsInput.Update(keyMsg)
+		case FieldNotifyPatterns:
+			form.patternsInput, _ = form.patternsInput.Update(keyMsg)
 		}
 		return
 	}
@@ -280,6 +467,12 @@ func (form *HostForm) Update(event interface{}) {
 		form.nameInput, _ = form.nameInput.Update(keyMsg)
 	case FieldHostname:
 		form.hostnameInput, _ = form.hostnameInput.Update(keyMsg)
+	case FieldGroup:
+		form.groupInput, _ = form.groupInput.Update(keyMsg)
+	case FieldTags:
+		form.tagsInput, _ = form.tagsInput.Update(keyMsg)
+	case FieldNotifyPatterns:
+		form.patternsInput, _ = form.patternsInput.Update(keyMsg)
 	case FieldPort:
 		if keyMsg.Type == tea.KeyBackspace || keyMsg.Type == tea.KeyDelete ||
 			keyMsg.Type == tea.KeyLeft || keyMsg.Type == tea.KeyRight ||
@@ -318,12 +511,15 @@ func (form *HostForm) SetFocused(focused bool) {
 		form.nameInput.Blur()
 		form.hostnameInput.Blur()
 		form.portInput.Blur()
+		form.groupInput.Blur()
+		form.tagsInput.Blur()
+		form.patternsInput.Blur()
 	}
 }
 
 func (form *HostForm) Render() string {
 	if form.currentHost == nil {
-		emptyMsg := styles.FormEmpty.Render("← Nothing to see here! Press Ctrl+N to add a new host first.")
+		emptyMsg := styles.FormEmpty.Render("← Nothing to see here! Press Ctrl+N to add a new host, or i to import ~/.ssh/config.")
 		return lipgloss.Place(
 			lipgloss.Width(emptyMsg)+4,
 			lipgloss.Height(emptyMsg)+4,
@@ -375,6 +571,8 @@ func (form *HostForm) renderEditableForm() string {
 	fields = append(fields, styles.FormFieldContainer.Render(hostnameLine))
 	if errMsg, ok := form.fieldErrors[FieldHostname]; ok {
 		fields = append(fields, renderError(errMsg))
+	} else if resolution := form.renderResolution(); resolution != "" {
+		fields = append(fields, resolution)
 	}
 
 	var portLabel string
@@ -405,6 +603,16 @@ func (form *HostForm) renderEditableForm() string {
 	modeLine := lipgloss.JoinHorizontal(lipgloss.Left, modeLabel, modeView)
 	fields = append(fields, styles.FormFieldContainer.Render(modeLine))
 
+	fields = append(fields, styles.FormSectionTitle.Render("Organization"))
+	fields = append(fields, form.renderTextField(FieldGroup, "Group", form.groupInput))
+	if form.focused && form.fieldIndex == FieldGroup {
+		fields = append(fields, styles.FormHint.Render("Separate folders with /"))
+	}
+	fields = append(fields, form.renderTextField(FieldTags, "Tags", form.tagsInput))
+	if form.focused && form.fieldIndex == FieldTags {
+		fields = append(fields, styles.FormHint.Render("Filter by tag with /#tag"))
+	}
+
 	fields = append(fields, styles.FormSectionTitle.Render("Authentication"))
 
 	var identityLabel string
@@ -452,14 +660,95 @@ func (form *HostForm) renderEditableForm() string {
 	identityLine := lipgloss.JoinHorizontal(lipgloss.Left, identityLabel, identityView)
 	fields = append(fields, styles.FormFieldContainer.Render(identityLine))
 
+	fields = append(fields, styles.FormSectionTitle.Render("Session"))
+
+	var recordLabel string
+	if form.focused && form.fieldIndex == FieldRecording {
+		recordLabel = styles.FormLabelFocused.Render("Record")
+	} else {
+		recordLabel = styles.FormLabel.Render("Record")
+	}
+	recordView := renderCheckbox(form.alwaysRecord, "Always record sessions", form.focused && form.fieldIndex == FieldRecording)
+	recordLine := lipgloss.JoinHorizontal(lipgloss.Left, recordLabel, recordView)
+	fields = append(fields, styles.FormFieldContainer.Render(recordLine))
+
+	var transcriptLabel string
+	if form.focused && form.fieldIndex == FieldTranscript {
+		transcriptLabel = styles.FormLabelFocused.Render("Transcript")
+	} else {
+		transcriptLabel = styles.FormLabel.Render("Transcript")
+	}
+	transcriptView := renderCheckbox(form.transcript, "Write text transcripts", form.focused && form.fieldIndex == FieldTranscript)
+	transcriptLine := lipgloss.JoinHorizontal(lipgloss.Left, transcriptLabel, transcriptView)
+	fields = append(fields, styles.FormFieldContainer.Render(transcriptLine))
+
+	var titleLabel string
+	if form.focused && form.fieldIndex == FieldStaticTitle {
+		titleLabel = styles.FormLabelFocused.Render("Tab title")
+	} else {
+		titleLabel = styles.FormLabel.Render("Tab title")
+	}
+	titleView := renderCheckbox(form.staticTitle, "Keep name@hostname", form.focused && form.fieldIndex == FieldStaticTitle)
+	titleLine := lipgloss.JoinHorizontal(lipgloss.Left, titleLabel, titleView)
+	fields = append(fields, styles.FormFieldContainer.Render(titleLine))
+
+	fields = append(fields, styles.FormSectionTitle.Render("Notify in background"))
+
+	var bellLabel string
+	if form.focused && form.fieldIndex == FieldNotifyBell {
+		bellLabel = styles.FormLabelFocused.Render("Bell")
+	} else {
+		bellLabel = styles.FormLabel.Render("Bell")
+	}
+	bellView := renderCheckbox(form.notifyBell, "When the session rings the bell", form.focused && form.fieldIndex == FieldNotifyBell)
+	bellLine := lipgloss.JoinHorizontal(lipgloss.Left, bellLabel, bellView)
+	fields = append(fields, styles.FormFieldContainer.Render(bellLine))
+
+	var exitLabel string
+	if form.focused && form.fieldIndex == FieldNotifyExit {
+		exitLabel = styles.FormLabelFocused.Render("Disconnect")
+	} else {
+		exitLabel = styles.FormLabel.Render("Disconnect")
+	}
+	exitView := renderCheckbox(form.notifyExit, "When the session ends", form.focused && form.fieldIndex == FieldNotifyExit)
+	exitLine := lipgloss.JoinHorizontal(lipgloss.Left, exitLabel, exitView)
+	fields = append(fields, styles.FormFieldContainer.Render(exitLine))
+
+	fields = append(fields, form.renderTextField(FieldNotifyPatterns, "Output", form.patternsInput))
+
 	formContent := lipgloss.JoinVertical(lipgloss.Left, fields...)
 	return styles.FormContainer.Render(formContent)
 }
 
+func (form *HostForm) renderTextField(fieldIndex int, label string, input textinput.Model) string {
+	labelStyle, inputStyle := styles.FormLabel, styles.FormInput
+	if form.focused && form.fieldIndex == fieldIndex {
+		labelStyle, inputStyle = styles.FormLabelFocused, styles.FormInputFocused
+	}
+	line := lipgloss.JoinHorizontal(lipgloss.Left, labelStyle.Render(label), inputStyle.Render(input.View()))
+	return styles.FormFieldContainer.Render(line)
+}
+
 func renderError(errMsg string) string {
 	return styles.FormError.Render("✗ " + errMsg)
 }
 
+func renderCheckbox(checked bool, label string, focused bool) string {
+	box := "[ ]"
+	if checked {
+		box = "[x]"
+	}
+
+	checkboxStyle := styles.FormCheckbox
+	labelStyle := styles.FormCheckboxLabel
+	if focused {
+		checkboxStyle = styles.FormCheckboxFocused
+		labelStyle = styles.FormCheckboxLabelFocused
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Left, checkboxStyle.Render(box), labelStyle.Render(label))
+}
+
 func (form *HostForm) renderModeChooser() string {
 	sshBox := "[ ]"
 	telnetBox := "[ ]"
@@ -2,6 +2,7 @@ package screens
 
 import (
 	"yoru/screens/components"
+	"yoru/screens/forms"
 	"yoru/screens/styles"
 	"yoru/shared"
 	"yoru/types"
@@ -27,6 +28,10 @@ func (screen *home) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 		if cmd := screen.OnKeyPress(message); cmd != nil {
 			return screen, cmd
 		}
+	case forms.HostnameResolvedMsg, types.SSHKeyInstalledMsg:
+		// These finish even if the user moved to another section meanwhile
+		_, cmd := hostsScreen.Update(message)
+		return screen, cmd
 	}
 
 	currentIndex, _ := screen.navBar.GetActiveTab()
@@ -81,13 +86,13 @@ func (screen *home) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 	currentIndex, _ := screen.navBar.GetActiveTab()
 
 	if currentIndex == 0 && (key.Type == tea.KeyLeft || key.Type == tea.KeyRight) {
-		if hostsScreen.focusedArea == formFocus || hostsScreen.sidebar.IsFilterActive() || hostsScreen.deletePopup.IsVisible() {
+		if hostsScreen.focusedArea == formFocus || hostsScreen.sidebar.IsFilterActive() || hostsScreen.deletePopup.IsVisible() || hostsScreen.importPopup.IsVisible() || hostsScreen.installKeyPopup.IsVisible() || hostsScreen.execPopup.IsVisible() {
 			return nil
 		}
 	}
 
 	if currentIndex == 2 && (key.Type == tea.KeyLeft || key.Type == tea.KeyRight) {
-		if keychainScreen.focusedArea == keychainFormFocus || keychainScreen.sidebar.IsFilterActive() || keychainScreen.deletePopup.IsVisible() {
+		if keychainScreen.focusedArea == keychainFormFocus || keychainScreen.sidebar.IsFilterActive() || keychainScreen.deletePopup.IsVisible() || keychainScreen.generatePopup.IsVisible() || keychainScreen.exportPopup.IsVisible() || keychainScreen.importPopup.IsVisible() {
 			return nil
 		}
 	}
@@ -97,6 +102,13 @@ func (screen *home) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 		screen.navBar.PrevTab()
 	case tea.KeyRight:
 		screen.navBar.NextTab()
+	default:
+		return nil
+	}
+
+	// Sessions may have been logged since the logs were last loaded
+	if index, _ := screen.navBar.GetActiveTab(); index == 4 {
+		logsScreen.Init()
 	}
 
 	return nil
@@ -1,6 +1,9 @@
 package screens
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"yoru/models"
 	"yoru/repository"
 	"yoru/screens/components"
@@ -8,7 +11,10 @@ import (
 	"yoru/screens/popups"
 	"yoru/screens/styles"
 	"yoru/shared"
+	"yoru/ssh"
+	"yoru/sshconfig"
 	"yoru/types"
+	"yoru/utils/hostgroup"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -30,20 +36,31 @@ var hostsScreen = &hosts{
 	focusedArea:          sidebarFocus,
 	deletePopup:          popups.NewDeleteHostPopup(),
 	identityChooserPopup: popups.NewIdentityChooserPopup(),
+	importPopup:          popups.NewImportHostsPopup(),
+	installKeyPopup:      popups.NewInstallKeyPopup(),
+	execPopup:            popups.NewExecPopup(),
 }
 
 func (screen *hosts) Init() tea.Cmd {
-	allHosts, _ := repository.GetAllHosts()
-	screen.sidebar.SetHosts(allHosts)
+	if collapsed := repository.GetPreference(types.PrefCollapsedGroups, ""); collapsed != "" {
+		screen.sidebar.SetCollapsedGroups(strings.Split(collapsed, "\n"))
+	}
+
+	screen.loadHosts()
 
-	if len(allHosts) > 0 {
-		screen.form.LoadHost(&allHosts[0])
+	if selected := screen.sidebar.GetSelected(); selected != nil {
+		screen.form.LoadHost(selected)
 	}
 
 	return nil
 }
 
 func (screen *hosts) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
+	if message, ok := msg.(types.SSHKeyInstalledMsg); ok {
+		screen.keyInstalled(message)
+		return screen, nil
+	}
+
 	if screen.deletePopup.IsVisible() {
 		screen.deletePopup.Update(msg)
 		return screen, nil
@@ -54,12 +71,39 @@ func (screen *hosts) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 		return screen, nil
 	}
 
+	if screen.importPopup.IsVisible() {
+		screen.importPopup.Update(msg)
+		return screen, nil
+	}
+
+	if screen.installKeyPopup.IsVisible() {
+		screen.installKeyPopup.Update(msg)
+		return screen, nil
+	}
+
+	if screen.execPopup.IsVisible() {
+		return screen, screen.execPopup.Update(msg)
+	}
+
 	switch message := msg.(type) {
+	case forms.HostnameResolvedMsg:
+		screen.form.SetResolution(message)
+		return screen, nil
+
+	case tea.MouseMsg:
+		if screen.focusedArea == sidebarFocus {
+			screen.handleSidebarMouse(message)
+		}
+
 	case tea.KeyMsg:
 		switch message.Type {
 		case tea.KeyEnter:
 			switch screen.focusedArea {
 			case sidebarFocus:
+				if screen.sidebar.GetSelected() == nil {
+					screen.toggleGroup()
+					return screen, nil
+				}
 				if cmd := screen.OnKeyPress(message); cmd != nil {
 					return screen, cmd
 				}
@@ -102,6 +146,10 @@ func (screen *hosts) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 				}
 				return screen, nil
 			}
+		case tea.KeyCtrlR:
+			if screen.focusedArea == formFocus {
+				return screen, screen.form.ResolveHostname()
+			}
 		case tea.KeyCtrlN:
 			if cmd := screen.OnKeyPress(message); cmd != nil {
 				return screen, cmd
@@ -116,13 +164,9 @@ func (screen *hosts) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 					selectedHost.Name,
 					func(dontAskAgain bool) {
 						if err := repository.DeleteHost(selectedHost.ID); err == nil {
-							allHosts, _ := repository.GetAllHosts()
-							screen.sidebar.SetHosts(allHosts)
-							if len(allHosts) > 0 {
-								selected := screen.sidebar.GetSelected()
-								if selected != nil {
-									screen.form.LoadHost(selected)
-								}
+							screen.loadHosts()
+							if selected := screen.sidebar.GetSelected(); selected != nil {
+								screen.form.LoadHost(selected)
 							} else {
 								screen.form.Clear()
 							}
@@ -133,6 +177,29 @@ func (screen *hosts) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 			}
 			return screen, nil
 		}
+
+		if screen.focusedArea == sidebarFocus && !screen.sidebar.IsFilterActive() {
+			switch message.String() {
+			case "i":
+				screen.showImport()
+				return screen, nil
+			case "k":
+				screen.showInstallKey()
+				return screen, nil
+			case "x":
+				screen.showExec()
+				return screen, nil
+			case " ":
+				screen.toggleGroup()
+				return screen, nil
+			case "shift+up":
+				screen.moveHost(-1)
+				return screen, nil
+			case "shift+down":
+				screen.moveHost(1)
+				return screen, nil
+			}
+		}
 	}
 
 	if screen.deletePopup.IsVisible() {
@@ -195,6 +262,18 @@ func (screen *hosts) View() string {
 		return popupView
 	}
 
+	if screen.importPopup.IsVisible() {
+		return screen.importPopup.Render()
+	}
+
+	if screen.installKeyPopup.IsVisible() {
+		return screen.installKeyPopup.Render()
+	}
+
+	if screen.execPopup.IsVisible() {
+		return screen.execPopup.Render()
+	}
+
 	return content
 }
 
@@ -215,6 +294,8 @@ func (screen *hosts) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 			screen.form.SetFocused(false)
 			screen.form.Save()
 			screen.sidebar.SetFilterActive(screen.filterWasActive)
+			// The group or tags may have changed
+			screen.reloadHosts()
 			return nil
 		}
 		return nil
@@ -229,10 +310,10 @@ func (screen *hosts) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 			CredentialType: "",
 		}
 		if err := repository.CreateHost(newHost); err == nil {
-			allHosts, _ := repository.GetAllHosts()
-			screen.sidebar.SetHosts(allHosts)
-			if len(allHosts) > 0 {
-				screen.form.LoadHost(&allHosts[0])
+			screen.loadHosts()
+			screen.sidebar.SelectHost(newHost.ID)
+			if selected := screen.sidebar.GetSelected(); selected != nil {
+				screen.form.LoadHost(selected)
 			}
 			screen.focusedArea = formFocus
 			screen.form.SetFocused(true)
@@ -242,3 +323,269 @@ func (screen *hosts) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 
 	return nil
 }
+
+// loadHosts refreshes the sidebar, along with the usernames searched by
+// its filter, which come from each host's user or else its credential
+func (screen *hosts) loadHosts() {
+	allHosts, _ := repository.GetAllHosts()
+	identities, _ := repository.GetAllIdentities()
+	keys, _ := repository.GetAllKeys()
+
+	identityUsers := make(map[uint]string)
+	for _, identity := range identities {
+		identityUsers[identity.ID] = identity.Username
+	}
+	keyUsers := make(map[uint]string)
+	for _, key := range keys {
+		keyUsers[key.ID] = key.Username
+	}
+
+	usernames := make(map[uint]string)
+	for _, host := range allHosts {
+		switch {
+		case host.User != "":
+			usernames[host.ID] = host.User
+		case host.CredentialType == types.CredentialIdentity:
+			usernames[host.ID] = identityUsers[host.CredentialID]
+		case host.CredentialType == types.CredentialKey:
+			usernames[host.ID] = keyUsers[host.CredentialID]
+		}
+	}
+
+	screen.sidebar.SetUsernames(usernames)
+	screen.sidebar.SetHosts(allHosts)
+}
+
+func (screen *hosts) reloadHosts() {
+	screen.loadHosts()
+	if selected := screen.sidebar.GetSelected(); selected != nil {
+		screen.form.LoadHost(selected)
+	}
+}
+
+// toggleGroup collapses or expands the selected group and remembers it
+func (screen *hosts) toggleGroup() {
+	if screen.sidebar.ToggleSelectedGroup() {
+		repository.SetPreference(types.PrefCollapsedGroups, strings.Join(screen.sidebar.CollapsedGroups(), "\n"))
+	}
+}
+
+// moveHost moves the selected host up or down within its group
+func (screen *hosts) moveHost(delta int) {
+	if ids := screen.sidebar.MoveSelected(delta); ids != nil {
+		if err := repository.ReorderHosts(ids); err == nil {
+			screen.reloadHosts()
+		}
+	}
+}
+
+// handleSidebarMouse selects the row clicked and moves a host dragged
+// onto another host or a group. Clicking a group opens or closes it.
+func (screen *hosts) handleSidebarMouse(message tea.MouseMsg) {
+	if message.Button != tea.MouseButtonLeft && message.Action != tea.MouseActionRelease {
+		return
+	}
+
+	// The sidebar starts below the navigation bar and the content border
+	row := -1
+	if message.X < sidebarWidth+2 && !screen.sidebar.IsFilterActive() {
+		row = screen.sidebar.RowAt(message.Y - lipgloss.Height(components.NavBar.Render()) - 1)
+	}
+
+	switch message.Action {
+	case tea.MouseActionPress:
+		screen.dragging = row >= 0
+		screen.dragRow = row
+		screen.sidebar.Select(row)
+	case tea.MouseActionRelease:
+		if !screen.dragging || row < 0 {
+			screen.dragging = false
+			return
+		}
+		screen.dragging = false
+
+		if row == screen.dragRow {
+			if screen.sidebar.GetSelected() == nil {
+				screen.toggleGroup()
+			}
+			return
+		}
+		selected := screen.sidebar.GetSelected()
+		group, ids, ok := screen.sidebar.DropSelected(row)
+		if selected == nil || !ok {
+			return
+		}
+
+		host, err := repository.GetHostByID(selected.ID)
+		if err != nil {
+			return
+		}
+		if host.Group != group {
+			host.Group = group
+			if err := repository.UpdateHost(host); err != nil {
+				return
+			}
+		}
+		if err := repository.ReorderHosts(ids); err != nil {
+			return
+		}
+		screen.reloadHosts()
+	}
+}
+
+// showImport previews the hosts in the user's ssh config for import
+func (screen *hosts) showImport() {
+	path := sshconfig.DefaultPath()
+	entries, err := sshconfig.Parse(path)
+	if err == nil {
+		var candidates []sshconfig.Candidate
+		if candidates, err = sshconfig.Plan(entries); err == nil {
+			screen.importPopup.Show(path, candidates, screen.importHosts)
+			return
+		}
+	}
+	screen.importPopup.ShowError(path, err)
+}
+
+func (screen *hosts) importHosts(candidates []sshconfig.Candidate) (sshconfig.Result, error) {
+	result, err := sshconfig.Apply(candidates)
+
+	screen.loadHosts()
+	if selected := screen.sidebar.GetSelected(); selected != nil {
+		screen.form.LoadHost(selected)
+	}
+	return result, err
+}
+
+// showInstallKey offers to authorize a keychain key on the selected host,
+// logging in with the host's current credential
+func (screen *hosts) showInstallKey() {
+	selectedHost := screen.sidebar.GetSelected()
+	if selectedHost == nil {
+		return
+	}
+	host := *selectedHost
+
+	keys, _ := repository.GetAllKeys()
+	screen.installKeyPopup.Show(host.Name, keys, func(key *models.Key, useKey bool) {
+		go func() {
+			installed := false
+			credential, err := ssh.LoadCredential(&host)
+			if err == nil {
+				installed, err = ssh.InstallPublicKey(&host, credential, key)
+			}
+			shared.SendMessage(types.SSHKeyInstalledMsg{
+				HostID:    host.ID,
+				KeyID:     key.ID,
+				UseKey:    useKey,
+				Installed: installed,
+				Error:     err,
+			})
+		}()
+	})
+}
+
+// showExec asks for a command to run on the selected host or group
+func (screen *hosts) showExec() {
+	var selector string
+	if host := screen.sidebar.GetSelected(); host != nil {
+		selector = host.Name
+	} else if path, ok := screen.sidebar.GetSelectedGroup(); ok {
+		selector = hostgroup.Separator + path
+	}
+
+	allHosts, _ := repository.GetAllHosts()
+	screen.execPopup.Show(selector,
+		func(selector string) ([]string, error) {
+			hosts, err := hostgroup.Select(allHosts, selector)
+			names := make([]string, len(hosts))
+			for i, host := range hosts {
+				names[i] = host.Name
+			}
+			return names, err
+		},
+		func(selector, command string) (tea.Cmd, error) {
+			hosts, err := hostgroup.Select(allHosts, selector)
+			if err != nil {
+				return nil, err
+			}
+			if len(hosts) == 0 {
+				return nil, errors.New("no hosts match")
+			}
+			if strings.TrimSpace(command) == "" {
+				return nil, errors.New("command is required")
+			}
+
+			execScreen := NewExecScreen(hosts, command)
+			tabName := "$ " + command
+			return func() tea.Msg {
+				return types.AddTabMsg{TabName: tabName, Screen: execScreen}
+			}, nil
+		},
+	)
+}
+
+func (screen *hosts) keyInstalled(message types.SSHKeyInstalledMsg) {
+	if message.Error != nil {
+		screen.installKeyPopup.SetResult("", message.Error)
+		return
+	}
+
+	result := "Key installed."
+	if !message.Installed {
+		result = "Key was already installed."
+	}
+
+	if message.UseKey {
+		if err := useKeyForHost(message.HostID, message.KeyID); err != nil {
+			screen.installKeyPopup.SetResult("", fmt.Errorf("key installed, but the host was not updated: %w", err))
+			return
+		}
+		result += " The host now logs in with it."
+
+		screen.loadHosts()
+		if selected := screen.sidebar.GetSelected(); selected != nil && selected.ID == message.HostID {
+			screen.form.LoadHost(selected)
+		}
+	}
+
+	screen.installKeyPopup.SetResult(result, nil)
+}
+
+// useKeyForHost switches a host to key authentication. The host keeps
+// logging in to the account the key was installed for, the one of the
+// credential it replaces, whatever the key's own username; a key without
+// one takes it too.
+func useKeyForHost(hostID, keyID uint) error {
+	host, err := repository.GetHostByID(hostID)
+	if err != nil {
+		return err
+	}
+	key, err := repository.GetKeyByID(keyID)
+	if err != nil {
+		return err
+	}
+
+	var account string
+	credential, _ := ssh.LoadCredential(host)
+	switch credential := credential.(type) {
+	case *models.Identity:
+		account = credential.Username
+	case *models.Key:
+		account = credential.Username
+	}
+
+	if key.Username == "" && account != "" {
+		key.Username = account
+		if err := repository.UpdateKey(key); err != nil {
+			return err
+		}
+	}
+
+	if host.User == "" {
+		host.User = account
+	}
+	host.CredentialType = types.CredentialKey
+	host.CredentialID = key.ID
+	return repository.UpdateHost(host)
+}
@@ -1,6 +1,7 @@
 package screens
 
 import (
+	"strings"
 	"yoru/models"
 	"yoru/repository"
 	"yoru/screens/components"
@@ -8,6 +9,7 @@ import (
 	"yoru/screens/popups"
 	"yoru/screens/styles"
 	"yoru/shared"
+	"yoru/ssh"
 	"yoru/types"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,6 +21,10 @@ type keychain struct {
 	form        *forms.KeychainForm
 	focusedArea focusArea
 	deletePopup *popups.DeleteKeychainPopup
+
+	generatePopup *popups.GenerateKeyPopup
+	exportPopup   *popups.ExportKeyPopup
+	importPopup   *popups.ImportKeyPopup
 }
 
 const (
@@ -36,6 +42,10 @@ var keychainScreen = &keychain{
 	form:        forms.NewKeychainForm(),
 	focusedArea: keychainSidebarFocus,
 	deletePopup: popups.NewDeleteKeychainPopup(),
+
+	generatePopup: popups.NewGenerateKeyPopup(),
+	exportPopup:   popups.NewExportKeyPopup(),
+	importPopup:   popups.NewImportKeyPopup(),
 }
 
 func (screen *keychain) Init() tea.Cmd {
@@ -57,6 +67,18 @@ func (screen *keychain) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 		screen.deletePopup.Update(msg)
 		return screen, nil
 	}
+	if screen.generatePopup.IsVisible() {
+		screen.generatePopup.Update(msg)
+		return screen, nil
+	}
+	if screen.exportPopup.IsVisible() {
+		screen.exportPopup.Update(msg)
+		return screen, nil
+	}
+	if screen.importPopup.IsVisible() {
+		screen.importPopup.Update(msg)
+		return screen, nil
+	}
 
 	switch message := msg.(type) {
 	case tea.KeyMsg:
@@ -86,6 +108,20 @@ func (screen *keychain) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 			return screen, nil
 		}
 
+		if screen.focusedArea == keychainSidebarFocus && !screen.sidebar.IsFilterActive() {
+			switch message.String() {
+			case "g":
+				screen.showGenerate()
+				return screen, nil
+			case "e":
+				screen.showExport()
+				return screen, nil
+			case "i":
+				screen.showImport()
+				return screen, nil
+			}
+		}
+
 		if screen.focusedArea == keychainSidebarFocus && (message.String() == "d" || message.String() == "D") {
 			selectedItem := screen.sidebar.GetSelected()
 			if selectedItem != nil {
@@ -204,9 +240,109 @@ func (screen *keychain) View() string {
 		popupView := screen.deletePopup.Render()
 		return popupView
 	}
+	if screen.generatePopup.IsVisible() {
+		return screen.generatePopup.Render()
+	}
+	if screen.exportPopup.IsVisible() {
+		return screen.exportPopup.Render()
+	}
+	if screen.importPopup.IsVisible() {
+		return screen.importPopup.Render()
+	}
 	return content
 }
 
+// showGenerate creates a key from a new key pair and opens it in the form,
+// where the username still has to be filled in
+func (screen *keychain) showGenerate() {
+	screen.generatePopup.Show(func(spec ssh.KeySpec) error {
+		generated, err := ssh.GenerateKey(spec)
+		if err != nil {
+			return err
+		}
+
+		name := spec.Comment
+		if name == "" {
+			name = "New " + strings.ToUpper(string(spec.Algorithm)[:1]) + string(spec.Algorithm)[1:] + " Key"
+		}
+		newKey := &models.Key{
+			Name:       name,
+			PrivateKey: generated.PrivateKey,
+			PublicKey:  generated.PublicKey,
+		}
+		if err := repository.CreateKey(newKey); err != nil {
+			return err
+		}
+
+		keys, _ := repository.GetAllKeys()
+		identities, _ := repository.GetAllIdentities()
+		screen.sidebar.SetItems(keys, identities)
+		screen.sidebar.SelectItemByID(newKey.ID, "Key")
+		screen.form.LoadKey(newKey)
+
+		screen.focusedArea = keychainFormFocus
+		screen.form.SetFocused(true)
+		return nil
+	})
+}
+
+// showImport adds a private key file to the keychain, converting PuTTY and
+// PEM keys to OpenSSH
+func (screen *keychain) showImport() {
+	screen.importPopup.Show(func(path, passphrase string) error {
+		newKey, _, err := ssh.ImportKeyFile(path, passphrase)
+		if err != nil {
+			return err
+		}
+		if err := repository.CreateKey(newKey); err != nil {
+			return err
+		}
+
+		keys, _ := repository.GetAllKeys()
+		identities, _ := repository.GetAllIdentities()
+		screen.sidebar.SetItems(keys, identities)
+		screen.sidebar.SelectItemByID(newKey.ID, "Key")
+		screen.form.LoadKey(newKey)
+
+		screen.focusedArea = keychainFormFocus
+		screen.form.SetFocused(true)
+		return nil
+	})
+}
+
+// showExport offers to write the selected key to ~/.ssh
+func (screen *keychain) showExport() {
+	selectedItem := screen.sidebar.GetSelected()
+	if selectedItem == nil || selectedItem.ItemType != "Key" {
+		return
+	}
+	key, err := repository.GetKeyByID(selectedItem.ID)
+	if err != nil {
+		return
+	}
+
+	screen.exportPopup.Show(key.Name, defaultExportPath(key), func(path, passphrase string) ([]string, error) {
+		return ssh.ExportKey(key, path, passphrase)
+	})
+}
+
+// defaultExportPath follows ssh-keygen's naming for the key type
+func defaultExportPath(key *models.Key) string {
+	fields := strings.Fields(key.PublicKey)
+	name := "id_key"
+	if len(fields) > 0 {
+		switch {
+		case fields[0] == "ssh-ed25519":
+			name = "id_ed25519"
+		case strings.HasPrefix(fields[0], "ecdsa-"):
+			name = "id_ecdsa"
+		case fields[0] == "ssh-rsa":
+			name = "id_rsa"
+		}
+	}
+	return "~/.ssh/" + name
+}
+
 func (screen *keychain) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 	if key.Type == tea.KeyCtrlN {
 		newIdentity := &models.Identity{
@@ -37,6 +37,18 @@ func (screen *logs) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 			if screen.selectedIdx < len(screen.logs)-1 {
 				screen.selectedIdx++
 			}
+		case "enter":
+			if screen.selectedIdx < len(screen.logs) && screen.logs[screen.selectedIdx].RecordingPath != "" {
+				log := screen.logs[screen.selectedIdx]
+				player := NewPlayerScreen(log.RecordingPath)
+				tabName := "▶ " + log.RemoteHostname
+				return screen, func() tea.Msg {
+					return types.AddTabMsg{
+						TabName: tabName,
+						Screen:  player,
+					}
+				}
+			}
 		}
 	}
 
@@ -57,8 +69,8 @@ func (screen *logs) View() string {
 		)
 	}
 
-	headers := []string{"ID", "Started At", "Ended At", "Local", "Remote", "Mode", "Duration"}
-	colWidths := []int{6, 20, 20, 20, 20, 10, 12}
+	headers := []string{"ID", "Started At", "Ended At", "Local", "Remote", "Mode", "Duration", "Rec"}
+	colWidths := []int{6, 20, 20, 20, 20, 10, 12, 5}
 
 	var headerCells []string
 	for i, header := range headers {
@@ -93,6 +105,11 @@ func (screen *logs) View() string {
 			duration = log.EndedAt.Sub(log.StartedAt).Round(time.Second).String()
 		}
 
+		recorded := ""
+		if log.RecordingPath != "" {
+			recorded = "●"
+		}
+
 		cells := []string{
 			fmt.Sprintf("%d", log.ID),
 			log.StartedAt.Format("2006-01-02 15:04:05"),
@@ -101,6 +118,7 @@ func (screen *logs) View() string {
 			log.RemoteHostname,
 			string(log.Mode),
 			duration,
+			recorded,
 		}
 
 		var rowCells []string
@@ -128,7 +146,7 @@ func (screen *logs) View() string {
 
 	info := lipgloss.NewStyle().
 		Foreground(lipgloss.Color(types.Subtext0)).
-		Render(fmt.Sprintf("Showing %d of last %d logs | ↑↓: Navigate | g/G: Top/Bottom", len(screen.logs), logsLimit))
+		Render(fmt.Sprintf("Showing %d of last %d logs | ↑↓: Navigate | g/G: Top/Bottom | Enter: Play recording", len(screen.logs), logsLimit))
 
 	return lipgloss.JoinVertical(lipgloss.Left, bordered, info)
 }
@@ -1,16 +1,33 @@
 package screens
 
 import (
+	"yoru/models"
 	"yoru/screens/components"
+	"yoru/screens/popups"
 	"yoru/shared"
 	"yoru/types"
+	"yoru/utils/notify"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var ScreenManager = &manager{
-	tabBar: components.TabBar,
+	tabBar:       components.TabBar,
+	palette:      popups.NewCommandPalette(),
+	namePopup:    popups.NewNamePopup(),
+	confirmPopup: popups.NewConfirmPopup(),
+}
+
+// Run starts the terminal interface and blocks until it quits
+func Run() error {
+	// Notifications share the output so they are not written mid-frame
+	program := tea.NewProgram(ScreenManager, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(notify.Output))
+	shared.SetProgram(program)
+	if _, err := program.Run(); err != nil {
+		return err
+	}
+	return ScreenManager.saveLastSession()
 }
 
 func (manager *manager) Init() tea.Cmd {
@@ -19,7 +36,34 @@ func (manager *manager) Init() tea.Cmd {
 		Screen: homeScreen,
 	})
 
-	return homeScreen.Init()
+	commands := []tea.Cmd{homeScreen.Init()}
+	for _, tab := range manager.startTabs {
+		manager.tabBar.AddTab(tab)
+		manager.tabBar.SwitchToLastTab()
+		commands = append(commands, tab.Screen.Init())
+	}
+	manager.startTabs = nil
+
+	switch {
+	case manager.startWorkspace != nil:
+		commands = append(commands, manager.openWorkspace(manager.startWorkspace))
+		manager.startWorkspace = nil
+	case len(manager.tabBar.GetTabs()) == 1:
+		// Nothing was asked for on the command line
+		manager.offerRestore()
+	}
+
+	return tea.Batch(commands...)
+}
+
+// OpenOnStart queues a terminal tab for host, opened as the interface
+// starts. quickConnect marks a host that is not saved.
+func (manager *manager) OpenOnStart(host *models.Host, quickConnect bool) {
+	tab := types.Tab{Name: host.Name + "@" + host.Hostname, Screen: NewTerminalScreen(host)}
+	if quickConnect {
+		tab = types.Tab{Name: host.Name, Screen: NewQuickConnectScreen(host)}
+	}
+	manager.startTabs = append(manager.startTabs, tab)
 }
 
 func (manager *manager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -34,13 +78,76 @@ func (manager *manager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Initialize the new screen
 		return manager, message.Screen.Init()
 	case types.CloseTabMsg:
-		manager.tabBar.RemoveCurrentTab()
+		if message.Screen == nil {
+			manager.tabBar.RemoveCurrentTab()
+			return manager, nil
+		}
+		for index, tab := range manager.tabBar.GetTabs() {
+			if tab.Screen == message.Screen {
+				manager.tabBar.RemoveTab(index)
+				break
+			}
+			// A pane closes within its tab, which goes back to a plain
+			// terminal once one pane is left
+			if split, ok := tab.Screen.(*splitScreen); ok && split.remove(message.Screen) {
+				if remaining := split.single(); remaining != nil {
+					manager.tabBar.UpdateScreen(index, remaining)
+					remaining.resize(shared.GlobalState.ScreenWidth, shared.GlobalState.ScreenHeight-1)
+				} else if split.focusedPane() == nil {
+					manager.tabBar.RemoveTab(index)
+				}
+				break
+			}
+		}
 		return manager, nil
+	case splitRequestMsg:
+		manager.showSplitPalette(message.vertical)
+		return manager, nil
+	case splitPaneMsg:
+		split, ok := manager.tabBar.GetCurrentScreen().(*splitScreen)
+		if !ok {
+			current, isTerminal := manager.tabBar.GetCurrentScreen().(*terminalScreen)
+			if !isTerminal {
+				return manager, nil
+			}
+			split = NewSplitScreen(current)
+			manager.tabBar.UpdateCurrentScreen(split)
+		}
+		split.split(message.screen, message.vertical)
+		return manager, message.screen.Init()
+	case types.SSHConnectingMsg, types.SSHAuthenticatingMsg, types.SSHHostKeyMsg, types.SSHConnectedMsg,
+		types.SSHOutputMsg, types.SSHErrorMsg, types.SSHDisconnectedMsg, playerTickMsg, execResultMsg:
+		// These belong to a specific session, so tabs in the background must see them too
+		return manager, manager.broadcast(msg)
+	case types.SSHKeyInstalledMsg:
+		// Installs run in the background and report to the hosts section
+		// whichever tab is active by then
+		_, command := homeScreen.Update(msg)
+		return manager, command
 	case tea.KeyMsg:
+		if manager.palette.IsVisible() {
+			if message.Type == tea.KeyCtrlC {
+				return manager, tea.Quit
+			}
+			return manager, manager.palette.Update(msg)
+		}
+		if manager.namePopup.IsVisible() {
+			manager.namePopup.Update(msg)
+			return manager, nil
+		}
+		if manager.confirmPopup.IsVisible() {
+			command := manager.confirmPopup.Update(msg)
+			if !manager.confirmPopup.IsVisible() {
+				// Answered either way, the last session can be replaced
+				manager.restorePending = false
+			}
+			return manager, command
+		}
+
 		// Check if current screen is in terminal key capture mode
 		screen := manager.tabBar.GetCurrentScreen()
-		if termScreen, ok := screen.(*terminalScreen); ok {
-			if termScreen.GetKeyCaptureMode() == types.KeyCaptureTerminal {
+		if capturer, ok := screen.(types.KeyCapturer); ok {
+			if capturer.GetKeyCaptureMode() == types.KeyCaptureTerminal {
 				// In terminal mode, pass ALL keys to terminal screen
 				// (terminal.go handles Ctrl+] to release capture)
 				current, command := screen.Update(msg)
@@ -56,14 +163,9 @@ func (manager *manager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		shared.GlobalState.ScreenWidth = message.Width
 		shared.GlobalState.ScreenHeight = message.Height
-		// Pass window size to current screen too
-		screen := manager.tabBar.GetCurrentScreen()
-		if screen != nil {
-			current, command := screen.Update(msg)
-			manager.tabBar.UpdateCurrentScreen(current)
-			return manager, command
-		}
-		return manager, nil
+		// Tabs in the background are resized too, so they are drawn at the
+		// right size when shown; restored tabs open before the size is known
+		return manager, manager.broadcast(msg)
 	}
 
 	screen := manager.tabBar.GetCurrentScreen()
@@ -80,7 +182,13 @@ func (manager *manager) View() string {
 	activeScreen := manager.tabBar.GetCurrentScreen()
 
 	var contentView string
-	if activeScreen != nil {
+	if manager.palette.IsVisible() {
+		contentView = manager.palette.Render()
+	} else if manager.namePopup.IsVisible() {
+		contentView = manager.namePopup.Render()
+	} else if manager.confirmPopup.IsVisible() {
+		contentView = manager.confirmPopup.Render()
+	} else if activeScreen != nil {
 		contentView = activeScreen.View()
 	}
 
@@ -88,6 +196,17 @@ func (manager *manager) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, contentView, tabBarView)
 }
 
+// broadcast passes a message to the screens of all tabs, not only the active one
+func (manager *manager) broadcast(msg tea.Msg) tea.Cmd {
+	var commands []tea.Cmd
+	for index, tab := range manager.tabBar.GetTabs() {
+		current, command := tab.Screen.Update(msg)
+		manager.tabBar.UpdateScreen(index, current)
+		commands = append(commands, command)
+	}
+	return tea.Batch(commands...)
+}
+
 func (manager *manager) SwitchScreen(screen types.Screen) tea.Cmd {
 	return nil
 }
@@ -96,6 +215,17 @@ func (manager *manager) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 	switch key.Type {
 	case tea.KeyCtrlC, tea.KeyCtrlQ:
 		return tea.Quit
+	case tea.KeyCtrlP:
+		manager.showPalette()
+		// Redraw right away, there is nothing to run
+		return func() tea.Msg { return nil }
+	case tea.KeyCtrlT:
+		manager.showTabs()
+		return func() tea.Msg { return nil }
+	case tea.KeyCtrlW:
+		if command := manager.closeTab(manager.tabBar.GetActiveIndex()); command != nil {
+			return command
+		}
 	case tea.KeyTab:
 		manager.tabBar.NextTab()
 	case tea.KeyShiftTab:
@@ -103,6 +233,14 @@ func (manager *manager) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 	default:
 		if key.Alt {
 			switch key.String() {
+			case "alt+r":
+				if manager.showRenameTab() {
+					return func() tea.Msg { return nil }
+				}
+			case "alt+left":
+				manager.moveTab(-1)
+			case "alt+right":
+				manager.moveTab(1)
 			case "alt+0":
 				manager.tabBar.SwitchToTab(0)
 			case "alt+1":
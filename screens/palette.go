@@ -0,0 +1,409 @@
+package screens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/screens/popups"
+	"yoru/ssh"
+	"yoru/types"
+	"yoru/utils/fuzzy"
+	"yoru/utils/network"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const paletteHostLimit = 8
+
+type paletteCredential struct {
+	credentialType types.CredentialType
+	id             uint
+	name           string
+	username       string
+}
+
+// paletteOpen opens a terminal picked in the palette
+type paletteOpen func(screen *terminalScreen, tabName string) tea.Cmd
+
+// openInTab opens a terminal in a tab of its own
+func openInTab(screen *terminalScreen, tabName string) tea.Cmd {
+	return func() tea.Msg {
+		return types.AddTabMsg{TabName: tabName, Screen: screen}
+	}
+}
+
+// showPalette opens the command palette, loading what it searches once
+// so typing does not hit the database
+func (manager *manager) showPalette() {
+	hosts, credentials := loadPaletteTargets()
+	workspaces, _ := repository.GetNamedWorkspaces()
+	lastSession, _ := repository.GetWorkspace("")
+
+	manager.palette.Show("Command Palette", "user@host:port, ssh:// URI, host name or action", func(query string) []popups.PaletteItem {
+		query = strings.TrimSpace(query)
+		items := paletteTargets(hosts, credentials, query, openInTab)
+		return append(items, manager.paletteActions(query, workspaces, lastSession)...)
+	})
+}
+
+// showSplitPalette opens the palette on the hosts alone, to pick the one
+// to open in a new pane next to the focused terminal
+func (manager *manager) showSplitPalette(vertical bool) {
+	hosts, credentials := loadPaletteTargets()
+	open := func(screen *terminalScreen, _ string) tea.Cmd {
+		return func() tea.Msg {
+			return splitPaneMsg{screen: screen, vertical: vertical}
+		}
+	}
+
+	title := "Split Down"
+	if vertical {
+		title = "Split Right"
+	}
+	manager.palette.Show(title, "user@host:port or host name to open in the new pane", func(query string) []popups.PaletteItem {
+		return paletteTargets(hosts, credentials, strings.TrimSpace(query), open)
+	})
+}
+
+func loadPaletteTargets() ([]models.Host, []paletteCredential) {
+	hosts, _ := repository.GetAllHosts()
+	identities, _ := repository.GetAllIdentities()
+	keys, _ := repository.GetAllKeys()
+
+	var credentials []paletteCredential
+	for _, identity := range identities {
+		credentials = append(credentials, paletteCredential{types.CredentialIdentity, identity.ID, identity.Name, identity.Username})
+	}
+	for _, key := range keys {
+		credentials = append(credentials, paletteCredential{types.CredentialKey, key.ID, key.Name, key.Username})
+	}
+	return hosts, credentials
+}
+
+// paletteTargets lists the saved hosts and addresses query could connect to
+func paletteTargets(hosts []models.Host, credentials []paletteCredential, query string, open paletteOpen) []popups.PaletteItem {
+	saved := paletteHosts(hosts, query, open)
+	quick := paletteQuickConnect(credentials, query, open)
+
+	// Something that looks like an address is most likely a new target
+	if strings.ContainsAny(query, "@:/.") {
+		return append(quick, saved...)
+	}
+	return append(saved, quick...)
+}
+
+// paletteHosts lists the saved hosts matching query, or the most recently
+// used ones when nothing has been typed
+func paletteHosts(hosts []models.Host, query string, open paletteOpen) []popups.PaletteItem {
+	type rankedHost struct {
+		host  models.Host
+		score int
+	}
+	var ranked []rankedHost
+	for _, host := range hosts {
+		if query == "" {
+			if host.LastConnectedAt != nil {
+				ranked = append(ranked, rankedHost{host, int(host.LastConnectedAt.Unix())})
+			}
+			continue
+		}
+		best, found := fuzzy.Match(query, host.Name)
+		if result, ok := fuzzy.Match(query, host.Hostname); ok && (!found || result.Score > best.Score) {
+			best, found = result, true
+		}
+		if found {
+			ranked = append(ranked, rankedHost{host, best.Score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	var items []popups.PaletteItem
+	for i, entry := range ranked {
+		if i == paletteHostLimit {
+			break
+		}
+		host := entry.host
+		detail := network.JoinHostPort(host.Hostname, host.Port)
+		if host.Group != "" {
+			detail += "  " + host.Group
+		}
+		items = append(items, popups.PaletteItem{
+			Title:  "Connect: " + host.Name,
+			Detail: detail,
+			Run: func() tea.Cmd {
+				return open(NewTerminalScreen(&host), host.Name+"@"+host.Hostname)
+			},
+		})
+	}
+	return items
+}
+
+// paletteQuickConnect offers to connect to query as an address, once for
+// each credential that could log in
+func paletteQuickConnect(credentials []paletteCredential, query string, open paletteOpen) []popups.PaletteItem {
+	if query == "" {
+		return nil
+	}
+	target, err := network.ParseTarget(query)
+	if err != nil {
+		return nil
+	}
+
+	// Credentials for the user typed come first; without a user, only
+	// credentials that name one can log in
+	var matching, others []paletteCredential
+	for _, credential := range credentials {
+		switch {
+		case target.User != "" && credential.username == target.User:
+			matching = append(matching, credential)
+		case target.User != "" || credential.username != "":
+			others = append(others, credential)
+		}
+	}
+	candidates := append(matching, others...)
+
+	if len(candidates) == 0 {
+		return []popups.PaletteItem{{
+			Title:  "Connect: " + target.String(),
+			Detail: "add an identity or key to the keychain first",
+		}}
+	}
+
+	var items []popups.PaletteItem
+	for _, credential := range candidates {
+		user := target.User
+		if user == "" {
+			user = credential.username
+		}
+		items = append(items, popups.PaletteItem{
+			Title:  "Connect: " + target.String(),
+			Detail: fmt.Sprintf("as %s with %s", user, credential.name),
+			Run: func() tea.Cmd {
+				host := ssh.NewQuickConnectHost(target, credential.credentialType, credential.id)
+				return open(NewQuickConnectScreen(host), host.Name)
+			},
+		})
+	}
+	return items
+}
+
+// paletteActions lists the app actions matching query, best match first.
+// workspaces are the saved ones, lastSession the tabs open when the
+// interface last quit, if any.
+func (manager *manager) paletteActions(query string, workspaces []models.Workspace, lastSession *models.Workspace) []popups.PaletteItem {
+	actions := []popups.PaletteItem{
+		{Title: "New host", Run: func() tea.Cmd {
+			manager.goToSection(sectionHosts)
+			return hostsScreen.OnKeyPress(tea.KeyMsg{Type: tea.KeyCtrlN})
+		}},
+		{Title: "Import hosts from ~/.ssh/config", Run: func() tea.Cmd {
+			manager.goToSection(sectionHosts)
+			hostsScreen.showImport()
+			return nil
+		}},
+		{Title: "Run a command on hosts", Run: func() tea.Cmd {
+			manager.goToSection(sectionHosts)
+			hostsScreen.showExec()
+			return nil
+		}},
+		{Title: "Generate key pair", Run: func() tea.Cmd {
+			manager.goToSection(sectionKeychain)
+			keychainScreen.showGenerate()
+			return nil
+		}},
+		{Title: "Import private key", Run: func() tea.Cmd {
+			manager.goToSection(sectionKeychain)
+			keychainScreen.showImport()
+			return nil
+		}},
+	}
+
+	for index, section := range homeScreen.navBar.GetItems() {
+		actions = append(actions, popups.PaletteItem{
+			Title: "Go to " + section,
+			Run: func() tea.Cmd {
+				manager.goToSection(index)
+				return nil
+			},
+		})
+	}
+
+	current := manager.tabBar.GetCurrentScreen()
+	for index, tab := range manager.tabBar.GetTabs() {
+		if tab.Screen == current {
+			continue
+		}
+		var detail string
+		if index <= 9 {
+			detail = fmt.Sprintf("alt+%d", index)
+		}
+		actions = append(actions, popups.PaletteItem{
+			Title:  "Switch to tab: " + tab.Title(),
+			Detail: detail,
+			Run: func() tea.Cmd {
+				manager.tabBar.SwitchToTab(index)
+				return nil
+			},
+		})
+	}
+
+	actions = append(actions, popups.PaletteItem{Title: "Show all tabs", Detail: "Ctrl+T", Run: func() tea.Cmd {
+		manager.showTabs()
+		return nil
+	}})
+	if active := manager.tabBar.GetActiveIndex(); active > 0 {
+		actions = append(actions,
+			popups.PaletteItem{Title: "Rename tab", Detail: "alt+r", Run: func() tea.Cmd {
+				manager.showRenameTab()
+				return nil
+			}},
+			popups.PaletteItem{Title: "Close tab", Detail: "Ctrl+W", Run: func() tea.Cmd {
+				return manager.closeTab(active)
+			}},
+		)
+		if active > 1 {
+			actions = append(actions, popups.PaletteItem{Title: "Move tab left", Detail: "alt+←", Run: func() tea.Cmd {
+				manager.moveTab(-1)
+				return nil
+			}})
+		}
+		if active < len(manager.tabBar.GetTabs())-1 {
+			actions = append(actions, popups.PaletteItem{Title: "Move tab right", Detail: "alt+→", Run: func() tea.Cmd {
+				manager.moveTab(1)
+				return nil
+			}})
+		}
+	}
+
+	if termScreen := focusedTerminal(current); termScreen != nil {
+		actions = append(actions,
+			popups.PaletteItem{Title: "Split right", Detail: "Ctrl+] |", Run: func() tea.Cmd {
+				manager.showSplitPalette(true)
+				return nil
+			}},
+			popups.PaletteItem{Title: "Split down", Detail: "Ctrl+] -", Run: func() tea.Cmd {
+				manager.showSplitPalette(false)
+				return nil
+			}},
+		)
+
+		title := "Synchronize input with other tabs"
+		if termScreen.IsSyncingInput() {
+			title = "Stop synchronizing input in this tab"
+		}
+		actions = append(actions, popups.PaletteItem{
+			Title:  title,
+			Detail: "Ctrl+] b",
+			Run: func() tea.Cmd {
+				termScreen.ToggleSyncInput()
+				return nil
+			},
+		})
+	}
+	for _, syncer := range terminalScreens() {
+		if syncer.IsSyncingInput() {
+			actions = append(actions, popups.PaletteItem{
+				Title: "Stop synchronizing input in all tabs",
+				Run: func() tea.Cmd {
+					for _, termScreen := range terminalScreens() {
+						termScreen.syncInput = false
+					}
+					return nil
+				},
+			})
+			break
+		}
+	}
+
+	if termScreen := focusedTerminal(current); termScreen != nil && termScreen.CanSaveHost() {
+		actions = append(actions, popups.PaletteItem{
+			Title: "Save this session as a host",
+			Run: func() tea.Cmd {
+				termScreen.ShowSaveHost()
+				return nil
+			},
+		})
+	}
+
+	actions = append(actions, popups.PaletteItem{Title: "Save tabs as workspace", Run: func() tea.Cmd {
+		manager.showSaveWorkspace()
+		return nil
+	}})
+	for _, workspace := range workspaces {
+		actions = append(actions,
+			popups.PaletteItem{
+				Title:  "Open workspace: " + workspace.Name,
+				Detail: workspaceDetail(&workspace),
+				Run: func() tea.Cmd {
+					return manager.openWorkspace(&workspace)
+				},
+			},
+			popups.PaletteItem{
+				Title: "Delete workspace: " + workspace.Name,
+				Run: func() tea.Cmd {
+					manager.confirmPopup.Show("Delete Workspace", fmt.Sprintf("Delete the workspace %q?", workspace.Name), func() tea.Cmd {
+						repository.DeleteWorkspace(workspace.Name)
+						return nil
+					})
+					return nil
+				},
+			},
+		)
+	}
+	if lastSession != nil && len(lastSession.Tabs) > 0 {
+		actions = append(actions, popups.PaletteItem{
+			Title:  "Restore last session",
+			Detail: workspaceDetail(lastSession),
+			Run: func() tea.Cmd {
+				return manager.openWorkspace(lastSession)
+			},
+		})
+	}
+
+	actions = append(actions, popups.PaletteItem{Title: "Quit", Run: func() tea.Cmd { return tea.Quit }})
+
+	if query == "" {
+		return actions
+	}
+
+	type rankedAction struct {
+		item  popups.PaletteItem
+		score int
+	}
+	var ranked []rankedAction
+	for _, action := range actions {
+		if result, ok := fuzzy.Match(query, action.Title); ok {
+			ranked = append(ranked, rankedAction{action, result.Score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	items := make([]popups.PaletteItem, 0, len(ranked))
+	for _, entry := range ranked {
+		items = append(items, entry.item)
+	}
+	return items
+}
+
+// Sections of the home tab, in nav bar order
+const (
+	sectionHosts    = 0
+	sectionKeychain = 2
+	sectionLogs     = 4
+)
+
+// goToSection shows a section of the home tab
+func (manager *manager) goToSection(index int) {
+	manager.tabBar.SwitchToTab(0)
+	homeScreen.navBar.SwitchToTab(index)
+	// Sessions may have been logged since the logs were last loaded
+	if index == sectionLogs {
+		logsScreen.Init()
+	}
+}
@@ -0,0 +1,198 @@
+package screens
+
+import (
+	"fmt"
+	"time"
+	"yoru/recording"
+	"yoru/screens/styles"
+	"yoru/shared"
+	"yoru/terminal"
+	"yoru/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	playerTickInterval = 50 * time.Millisecond
+	playerSeekStep     = 5 * time.Second
+	playerMinSpeed     = 0.25
+	playerMaxSpeed     = 16
+)
+
+// playerTickMsg advances a player; generation discards ticks from a
+// previous play/pause cycle
+type playerTickMsg struct {
+	player     *playerScreen
+	generation int
+}
+
+// NewPlayerScreen creates a tab that replays an asciicast recording
+func NewPlayerScreen(path string) *playerScreen {
+	screen := &playerScreen{
+		path:  path,
+		speed: 1,
+	}
+
+	cast, err := recording.Load(path)
+	if err != nil {
+		screen.err = err
+		return screen
+	}
+
+	screen.cast = cast
+	screen.duration = cast.Duration()
+	screen.resetEmulator()
+	return screen
+}
+
+func (screen *playerScreen) Init() tea.Cmd {
+	if screen.cast == nil {
+		return nil
+	}
+	return screen.play()
+}
+
+func (screen *playerScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
+	switch message := msg.(type) {
+	case playerTickMsg:
+		if message.player != screen || message.generation != screen.generation || screen.paused {
+			return screen, nil
+		}
+
+		now := time.Now()
+		elapsed := now.Sub(screen.lastTick)
+		screen.lastTick = now
+		screen.advanceTo(screen.position + time.Duration(float64(elapsed)*screen.speed))
+
+		if screen.position >= screen.duration {
+			screen.paused = true
+			return screen, nil
+		}
+		return screen, screen.tick()
+
+	case tea.KeyMsg:
+		if cmd := screen.OnKeyPress(message); cmd != nil {
+			return screen, cmd
+		}
+	}
+
+	return screen, nil
+}
+
+func (screen *playerScreen) View() string {
+	width := shared.GlobalState.ScreenWidth
+	height := shared.GlobalState.ScreenHeight - 1
+
+	if screen.cast == nil {
+		message := styles.PopupError.Render(fmt.Sprintf("Unable to load recording: %v", screen.err))
+		return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, message)
+	}
+
+	state := "▶"
+	if screen.paused {
+		state = "⏸"
+	}
+	status := styles.PlayerStatus.Width(width).Render(fmt.Sprintf(
+		" %s %s / %s  %gx  |  Space: Play/Pause  ←→: Seek  +/-: Speed  Home: Restart  q: Close",
+		state, formatPlayerTime(screen.position), formatPlayerTime(screen.duration), screen.speed))
+
+	content := lipgloss.NewStyle().
+		MaxWidth(width).
+		MaxHeight(height - 1).
+		Render(screen.emulator.Render())
+	content = lipgloss.Place(width, height-1, lipgloss.Left, lipgloss.Top, content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, content, status)
+}
+
+func (screen *playerScreen) OnKeyPress(key tea.KeyMsg) tea.Cmd {
+	switch key.String() {
+	case "q", "esc":
+		return func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
+	}
+
+	if screen.cast == nil {
+		return nil
+	}
+
+	switch key.String() {
+	case " ":
+		if screen.paused {
+			if screen.position >= screen.duration {
+				screen.seek(0)
+			}
+			return screen.play()
+		}
+		screen.paused = true
+	case "right":
+		screen.seek(screen.position + playerSeekStep)
+	case "left":
+		screen.seek(screen.position - playerSeekStep)
+	case "home":
+		screen.seek(0)
+	case "end":
+		screen.seek(screen.duration)
+	case "+", "=":
+		screen.speed = min(screen.speed*2, playerMaxSpeed)
+	case "-", "_":
+		screen.speed = max(screen.speed/2, playerMinSpeed)
+	}
+	return nil
+}
+
+func (screen *playerScreen) play() tea.Cmd {
+	screen.paused = false
+	screen.generation++
+	screen.lastTick = time.Now()
+	return screen.tick()
+}
+
+func (screen *playerScreen) tick() tea.Cmd {
+	generation := screen.generation
+	return tea.Tick(playerTickInterval, func(time.Time) tea.Msg {
+		return playerTickMsg{player: screen, generation: generation}
+	})
+}
+
+// seek moves playback to target; going backwards replays from the start
+// since the emulator state cannot be rewound
+func (screen *playerScreen) seek(target time.Duration) {
+	target = max(0, min(target, screen.duration))
+	if target < screen.position {
+		screen.resetEmulator()
+	}
+	screen.advanceTo(target)
+}
+
+func (screen *playerScreen) advanceTo(target time.Duration) {
+	events := screen.cast.Events
+	for screen.nextEvent < len(events) {
+		event := events[screen.nextEvent]
+		if time.Duration(event.Time*float64(time.Second)) > target {
+			break
+		}
+
+		switch event.Type {
+		case recording.EventOutput:
+			screen.emulator.Write([]byte(event.Data))
+		case recording.EventResize:
+			if width, height, ok := recording.ParseResize(event.Data); ok {
+				screen.emulator.Resize(width, height)
+			}
+		}
+		screen.nextEvent++
+	}
+	screen.position = min(target, screen.duration)
+}
+
+func (screen *playerScreen) resetEmulator() {
+	screen.emulator = terminal.NewEmulator(screen.cast.Header.Width, screen.cast.Header.Height)
+	screen.nextEvent = 0
+	screen.position = 0
+}
+
+func formatPlayerTime(duration time.Duration) string {
+	total := int(duration.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
@@ -0,0 +1,115 @@
+package popups
+
+import (
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ConfirmPopup struct {
+	popup          *components.Popup
+	title          string
+	message        string
+	selectedButton int // 0 = No, 1 = Yes
+	onConfirm      func() tea.Cmd
+	command        tea.Cmd
+}
+
+func NewConfirmPopup() *ConfirmPopup {
+	return &ConfirmPopup{
+		popup: components.NewPopup(),
+	}
+}
+
+// Show asks a yes or no question; onConfirm returns the command to run
+// on a yes
+func (cp *ConfirmPopup) Show(title, message string, onConfirm func() tea.Cmd) {
+	cp.title = title
+	cp.message = message
+	cp.onConfirm = onConfirm
+	cp.command = nil
+	cp.selectedButton = 0 // Default to No
+
+	cp.popup.Show(cp.buildContent(), cp.handleInput)
+}
+
+func (cp *ConfirmPopup) Hide() {
+	cp.popup.Hide()
+}
+
+func (cp *ConfirmPopup) IsVisible() bool {
+	return cp.popup.IsVisible()
+}
+
+// Update handles a key while the popup is open, returning the command of
+// onConfirm once the question is answered yes
+func (cp *ConfirmPopup) Update(msg tea.Msg) tea.Cmd {
+	cp.popup.Update(msg)
+	command := cp.command
+	cp.command = nil
+	return command
+}
+
+func (cp *ConfirmPopup) Render() string {
+	return cp.popup.Render()
+}
+
+func (cp *ConfirmPopup) confirm() {
+	cp.Hide()
+	if cp.onConfirm != nil {
+		cp.command = cp.onConfirm()
+	}
+}
+
+func (cp *ConfirmPopup) handleInput(msg tea.Msg) bool {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "left":
+			cp.selectedButton = 1 // Yes
+			cp.popup.SetContent(cp.buildContent())
+			return true
+		case "right":
+			cp.selectedButton = 0 // No
+			cp.popup.SetContent(cp.buildContent())
+			return true
+		case "enter":
+			if cp.selectedButton == 1 {
+				cp.confirm()
+			} else {
+				cp.Hide()
+			}
+			return true
+		case "y", "Y":
+			cp.confirm()
+			return true
+		case "n", "N", "esc":
+			cp.Hide()
+			return true
+		}
+	}
+	return false
+}
+
+func (cp *ConfirmPopup) buildContent() string {
+	title := styles.PopupTitle.Render(cp.title)
+	message := styles.PopupMessage.Render(cp.message)
+
+	yesPrefix := "  "
+	noPrefix := "  "
+	if cp.selectedButton == 1 {
+		yesPrefix = "> "
+	} else {
+		noPrefix = "> "
+	}
+
+	yesButton := styles.PopupButtonYes.Render(yesPrefix + "Yes (y)")
+	noButton := styles.PopupButtonNo.Render(noPrefix + "No (n)")
+
+	buttons := lipgloss.JoinHorizontal(lipgloss.Top, yesButton, "  ", noButton)
+	buttonsContainer := lipgloss.NewStyle().Width(56).Align(lipgloss.Right).Render(buttons)
+	buttonsWithMargin := styles.PopupButtonsContainer.Render(buttonsContainer)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, message, buttonsWithMargin)
+}
@@ -7,6 +7,7 @@ import (
 	"yoru/screens/components"
 	"yoru/screens/styles"
 	"yoru/shared"
+	"yoru/utils/network"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -35,6 +36,7 @@ type ConnectionPopup struct {
 	keyType     string
 	fingerprint string
 	serverKey   sshlib.PublicKey
+	keyChanged  bool
 
 	logBoxMinWidth int
 
@@ -62,7 +64,7 @@ func (cp *ConnectionPopup) Show(hostID uint, onRetry func(), onCancel func()) {
 	host, _ := repository.GetHostByID(hostID)
 	minWidth := 0
 	if host != nil {
-		verifyWidth := len(fmt.Sprintf("Host '%s:%d' is not in known hosts.", host.Hostname, host.Port))
+		verifyWidth := len(fmt.Sprintf("Host '%s' is not in known hosts.", network.JoinHostPort(host.Hostname, host.Port)))
 		if verifyWidth > minWidth {
 			minWidth = verifyWidth
 		}
@@ -100,11 +102,15 @@ func (cp *ConnectionPopup) ShowError(err error) {
 	cp.popup.SetContent(cp.buildContent())
 }
 
+// ShowHostKeyVerification asks whether to trust a host key. A changed key
+// replaces the trusted one only when the user explicitly says so; saying
+// no to it ends the connection.
 func (cp *ConnectionPopup) ShowHostKeyVerification(
 	hostname string, port int, keyType string, fingerprint string,
-	serverKey sshlib.PublicKey, onAccept func(), onReject func(),
+	serverKey sshlib.PublicKey, changed bool, onAccept func(), onReject func(),
 ) {
 	cp.state = StateVerifyingHost
+	cp.keyChanged = changed
 	cp.hostname = hostname
 	cp.port = port
 	cp.keyType = keyType
@@ -199,7 +205,12 @@ func (cp *ConnectionPopup) handleHostKeyInput(keyMsg tea.KeyMsg) bool {
 		}
 		cp.state = StateConnecting
 		cp.popup.SetContent(cp.buildContent())
-	case "y", "Y":
+	case "y", "Y", "r", "R":
+		// A changed key is replaced with "r" only, so a habitual "y" does
+		// not accept it
+		if cp.keyChanged != (keyMsg.String() == "r" || keyMsg.String() == "R") {
+			return true
+		}
 		if cp.onAcceptHostKey != nil {
 			cp.onAcceptHostKey()
 		}
@@ -272,7 +283,7 @@ func (cp *ConnectionPopup) buildContent() string {
 		}
 	}
 	if host != nil {
-		verifyWidth := len(fmt.Sprintf("Host '%s:%d' is not in known hosts.", host.Hostname, host.Port))
+		verifyWidth := len(fmt.Sprintf("Host '%s' is not in known hosts.", network.JoinHostPort(host.Hostname, host.Port)))
 		if verifyWidth > maxWidth {
 			maxWidth = verifyWidth
 		}
@@ -309,8 +320,18 @@ func (cp *ConnectionPopup) buildContent() string {
 			styles.PopupButtonsContainer.Render(cp.buildButtons("Retry", "Cancel")))
 
 	case StateVerifyingHost:
+		if cp.keyChanged {
+			parts = append(parts,
+				styles.PopupError.Render(fmt.Sprintf("WARNING: the host key of '%s' has changed!", network.JoinHostPort(cp.hostname, cp.port))),
+				styles.PopupText.Render("The host may have been reinstalled, or the connection intercepted."),
+				styles.PopupText.Render(fmt.Sprintf("%s key fingerprint is now:", cp.keyType)),
+				styles.PopupTextBold.Render(cp.fingerprint),
+				styles.PopupSection.Render("Replace the trusted key? Only if you expected the change."),
+				styles.PopupButtonsContainer.Render(cp.buildButtons("Replace (r)", "Disconnect (n)")))
+			break
+		}
 		parts = append(parts,
-			styles.PopupText.Render(fmt.Sprintf("Host '%s:%d' is not in known hosts.", cp.hostname, cp.port)),
+			styles.PopupText.Render(fmt.Sprintf("Host '%s' is not in known hosts.", network.JoinHostPort(cp.hostname, cp.port))),
 			styles.PopupText.Render(fmt.Sprintf("%s key fingerprint is:", cp.keyType)),
 			styles.PopupTextBold.Render(cp.fingerprint),
 			styles.PopupSection.Render("Do you want to add this host to known hosts?"),
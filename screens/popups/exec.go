@@ -0,0 +1,181 @@
+package popups
+
+import (
+	"fmt"
+	"strings"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const execPreviewWidth = 60
+
+type ExecPopup struct {
+	popup        *components.Popup
+	hostsInput   textinput.Model
+	commandInput textinput.Model
+	hostsFocus   bool
+	resolve      func(selector string) ([]string, error)
+	onRun        func(selector, command string) (tea.Cmd, error)
+	command      tea.Cmd
+	err          error
+}
+
+func NewExecPopup() *ExecPopup {
+	hostsInput := textinput.New()
+	hostsInput.Placeholder = "web-1, #tag, /group"
+	hostsInput.CharLimit = 255
+	hostsInput.Width = 48
+
+	commandInput := textinput.New()
+	commandInput.Placeholder = "df -h /"
+	commandInput.CharLimit = 1024
+	commandInput.Width = 48
+
+	ep := &ExecPopup{
+		popup:        components.NewPopup(),
+		hostsInput:   hostsInput,
+		commandInput: commandInput,
+	}
+	ep.popup.SetWidth(72)
+	return ep
+}
+
+// Show asks for a command to run on the hosts selector picks. resolve
+// names the hosts a selector picks, for the preview; onRun returns the
+// command that starts the run.
+func (ep *ExecPopup) Show(selector string, resolve func(selector string) ([]string, error), onRun func(selector, command string) (tea.Cmd, error)) {
+	ep.resolve = resolve
+	ep.onRun = onRun
+	ep.command = nil
+	ep.err = nil
+	ep.hostsInput.SetValue(selector)
+	ep.hostsInput.CursorEnd()
+	ep.commandInput.SetValue("")
+	ep.setHostsFocus(selector == "")
+
+	ep.popup.Show(ep.buildContent(), ep.handleInput)
+}
+
+func (ep *ExecPopup) Hide() {
+	ep.hostsInput.Blur()
+	ep.commandInput.Blur()
+	ep.popup.Hide()
+}
+
+func (ep *ExecPopup) IsVisible() bool {
+	return ep.popup.IsVisible()
+}
+
+// Update handles a key while the popup is open, returning the command
+// that starts the run once it is confirmed
+func (ep *ExecPopup) Update(msg tea.Msg) tea.Cmd {
+	ep.popup.Update(msg)
+	command := ep.command
+	ep.command = nil
+	return command
+}
+
+func (ep *ExecPopup) Render() string {
+	return ep.popup.Render()
+}
+
+func (ep *ExecPopup) setHostsFocus(focused bool) {
+	ep.hostsFocus = focused
+	if focused {
+		ep.commandInput.Blur()
+		ep.hostsInput.Focus()
+	} else {
+		ep.hostsInput.Blur()
+		ep.commandInput.Focus()
+	}
+}
+
+func (ep *ExecPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		ep.Hide()
+		return true
+	case "enter":
+		if ep.hostsFocus {
+			ep.setHostsFocus(false)
+			break
+		}
+		if ep.onRun != nil {
+			if ep.command, ep.err = ep.onRun(ep.hostsInput.Value(), ep.commandInput.Value()); ep.err != nil {
+				break
+			}
+		}
+		ep.Hide()
+		return true
+	case "up":
+		ep.setHostsFocus(true)
+	case "down", "tab":
+		ep.setHostsFocus(false)
+	default:
+		ep.err = nil
+		if ep.hostsFocus {
+			ep.hostsInput, _ = ep.hostsInput.Update(keyMsg)
+		} else {
+			ep.commandInput, _ = ep.commandInput.Update(keyMsg)
+		}
+	}
+
+	ep.popup.SetContent(ep.buildContent())
+	return true
+}
+
+func (ep *ExecPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Run Command")
+	message := styles.PopupMessage.Render("Runs without a terminal on each host, several at a time.")
+
+	label := func(focused bool, text string) string {
+		text = fmt.Sprintf("%-11s", text)
+		if focused {
+			return styles.PopupItemSelected.Render(text)
+		}
+		return styles.PopupItemNormal.Render(text)
+	}
+	hostsLine := lipgloss.JoinHorizontal(lipgloss.Left, label(ep.hostsFocus, "Hosts"), " ", ep.hostsInput.View())
+	commandLine := lipgloss.JoinHorizontal(lipgloss.Left, label(!ep.hostsFocus, "Command"), " ", ep.commandInput.View())
+
+	lines := []string{title, message, "", hostsLine, commandLine, "", ep.preview()}
+	if ep.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(ep.err.Error()))
+	}
+	lines = append(lines, "", styles.PopupText.Render("↑/↓: Field  Enter: Run  Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// preview names the hosts the selector picks
+func (ep *ExecPopup) preview() string {
+	if ep.resolve == nil || strings.TrimSpace(ep.hostsInput.Value()) == "" {
+		return styles.PopupText.Render("Hosts: names, #tags or /groups, separated by commas")
+	}
+
+	names, err := ep.resolve(ep.hostsInput.Value())
+	if err != nil {
+		return styles.PopupError.Render(err.Error())
+	}
+	if len(names) == 0 {
+		return styles.PopupError.Render("No hosts match.")
+	}
+
+	summary := fmt.Sprintf("%d hosts: %s", len(names), strings.Join(names, ", "))
+	if len(names) == 1 {
+		summary = "1 host: " + names[0]
+	}
+	if len([]rune(summary)) > execPreviewWidth {
+		summary = string([]rune(summary)[:execPreviewWidth-1]) + "…"
+	}
+	return styles.PopupText.Render(summary)
+}
@@ -0,0 +1,164 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ExportKeyPopup struct {
+	popup           *components.Popup
+	keyName         string
+	pathInput       textinput.Model
+	passphraseInput textinput.Model
+	passphraseFocus bool
+	written         []string
+	err             error
+	onExport        func(path, passphrase string) ([]string, error)
+}
+
+func NewExportKeyPopup() *ExportKeyPopup {
+	pathInput := textinput.New()
+	pathInput.CharLimit = 255
+	pathInput.Width = 44
+
+	passphraseInput := textinput.New()
+	passphraseInput.Placeholder = "optional, encrypts the private key"
+	passphraseInput.CharLimit = 100
+	passphraseInput.Width = 36
+	passphraseInput.EchoMode = textinput.EchoPassword
+
+	ekp := &ExportKeyPopup{
+		popup:           components.NewPopup(),
+		pathInput:       pathInput,
+		passphraseInput: passphraseInput,
+	}
+	ekp.popup.SetWidth(64)
+	return ekp
+}
+
+// Show asks where to write a key's files, starting from defaultPath
+func (ekp *ExportKeyPopup) Show(keyName, defaultPath string, onExport func(path, passphrase string) ([]string, error)) {
+	ekp.keyName = keyName
+	ekp.onExport = onExport
+	ekp.written = nil
+	ekp.err = nil
+	ekp.pathInput.SetValue(defaultPath)
+	ekp.pathInput.CursorEnd()
+	ekp.passphraseInput.SetValue("")
+	ekp.setPassphraseFocus(false)
+
+	ekp.popup.Show(ekp.buildContent(), ekp.handleInput)
+}
+
+func (ekp *ExportKeyPopup) Hide() {
+	ekp.popup.Hide()
+}
+
+func (ekp *ExportKeyPopup) IsVisible() bool {
+	return ekp.popup.IsVisible()
+}
+
+func (ekp *ExportKeyPopup) Update(msg tea.Msg) {
+	ekp.popup.Update(msg)
+}
+
+func (ekp *ExportKeyPopup) Render() string {
+	return ekp.popup.Render()
+}
+
+func (ekp *ExportKeyPopup) setPassphraseFocus(focused bool) {
+	ekp.passphraseFocus = focused
+	if focused {
+		ekp.pathInput.Blur()
+		ekp.passphraseInput.Focus()
+	} else {
+		ekp.passphraseInput.Blur()
+		ekp.pathInput.Focus()
+	}
+}
+
+func (ekp *ExportKeyPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	// Once exported any of these keys dismisses the popup
+	if ekp.written != nil {
+		switch keyMsg.String() {
+		case "enter", "esc":
+			ekp.Hide()
+			return true
+		}
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		ekp.Hide()
+		return true
+	case "enter":
+		if ekp.onExport == nil {
+			ekp.Hide()
+			return true
+		}
+		ekp.written, ekp.err = ekp.onExport(ekp.pathInput.Value(), ekp.passphraseInput.Value())
+		if ekp.err != nil {
+			ekp.written = nil
+		}
+	case "up":
+		ekp.setPassphraseFocus(false)
+	case "down":
+		ekp.setPassphraseFocus(true)
+	default:
+		ekp.err = nil
+		if ekp.passphraseFocus {
+			ekp.passphraseInput, _ = ekp.passphraseInput.Update(keyMsg)
+		} else {
+			ekp.pathInput, _ = ekp.pathInput.Update(keyMsg)
+		}
+	}
+
+	ekp.popup.SetContent(ekp.buildContent())
+	return true
+}
+
+func (ekp *ExportKeyPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Export Key")
+	message := styles.PopupMessage.Render(fmt.Sprintf("Write \"%s\" to files:", ekp.keyName))
+
+	if ekp.written != nil {
+		lines := []string{title, styles.PopupMessage.Render(fmt.Sprintf("%d files written:", len(ekp.written)))}
+		for _, path := range ekp.written {
+			lines = append(lines, styles.PopupText.Render("  "+path))
+		}
+		lines = append(lines, "", styles.PopupText.Render("Enter: Close"))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	label := func(focused bool, text string) string {
+		text = fmt.Sprintf("%-11s", text)
+		if focused {
+			return styles.PopupItemSelected.Render(text)
+		}
+		return styles.PopupItemNormal.Render(text)
+	}
+	pathLine := lipgloss.JoinHorizontal(lipgloss.Left, label(!ekp.passphraseFocus, "Path"), " ", ekp.pathInput.View())
+	passphraseLine := lipgloss.JoinHorizontal(lipgloss.Left, label(ekp.passphraseFocus, "Passphrase"), " ", ekp.passphraseInput.View())
+
+	lines := []string{title, message, "", pathLine, passphraseLine}
+	if ekp.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(fmt.Sprintf("Export failed: %v", ekp.err)))
+	}
+	lines = append(lines, "",
+		styles.PopupText.Render("The .pub and -cert.pub files are written alongside."),
+		styles.PopupText.Render("End the path with .ppk to write a PuTTY key instead."),
+		styles.PopupText.Render("↑/↓: Field  Enter: Export  Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
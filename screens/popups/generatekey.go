@@ -0,0 +1,174 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+	"yoru/ssh"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	generateFieldType = iota
+	generateFieldComment
+	totalGenerateFields
+)
+
+type keyPreset struct {
+	algorithm ssh.KeyAlgorithm
+	bits      int
+	label     string
+}
+
+var keyPresets = []keyPreset{
+	{ssh.KeyEd25519, 0, "Ed25519"},
+	{ssh.KeyECDSA, 256, "ECDSA P-256"},
+	{ssh.KeyECDSA, 384, "ECDSA P-384"},
+	{ssh.KeyECDSA, 521, "ECDSA P-521"},
+	{ssh.KeyRSA, 2048, "RSA 2048"},
+	{ssh.KeyRSA, 3072, "RSA 3072"},
+	{ssh.KeyRSA, 4096, "RSA 4096"},
+}
+
+type GenerateKeyPopup struct {
+	popup        *components.Popup
+	fieldIndex   int
+	presetIdx    int
+	commentInput textinput.Model
+	err          error
+	onGenerate   func(ssh.KeySpec) error
+}
+
+func NewGenerateKeyPopup() *GenerateKeyPopup {
+	commentInput := textinput.New()
+	commentInput.Placeholder = "user@host"
+	commentInput.CharLimit = 100
+	commentInput.Width = 36
+
+	gkp := &GenerateKeyPopup{
+		popup:        components.NewPopup(),
+		commentInput: commentInput,
+	}
+	return gkp
+}
+
+// Show asks for the type and comment of a new key pair; onGenerate creates
+// it and the popup stays open if that fails. Like imported keys, it is
+// kept unencrypted, so it can be used without prompting; a passphrase can
+// be set on export.
+func (gkp *GenerateKeyPopup) Show(onGenerate func(ssh.KeySpec) error) {
+	gkp.onGenerate = onGenerate
+	gkp.fieldIndex = generateFieldType
+	gkp.presetIdx = 0
+	gkp.err = nil
+	gkp.commentInput.SetValue("")
+	gkp.setFieldFocus()
+
+	gkp.popup.Show(gkp.buildContent(), gkp.handleInput)
+}
+
+func (gkp *GenerateKeyPopup) Hide() {
+	gkp.popup.Hide()
+}
+
+func (gkp *GenerateKeyPopup) IsVisible() bool {
+	return gkp.popup.IsVisible()
+}
+
+func (gkp *GenerateKeyPopup) Update(msg tea.Msg) {
+	gkp.popup.Update(msg)
+}
+
+func (gkp *GenerateKeyPopup) Render() string {
+	return gkp.popup.Render()
+}
+
+func (gkp *GenerateKeyPopup) setFieldFocus() {
+	gkp.commentInput.Blur()
+	if gkp.fieldIndex == generateFieldComment {
+		gkp.commentInput.Focus()
+	}
+}
+
+func (gkp *GenerateKeyPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		gkp.Hide()
+		return true
+	case "up":
+		if gkp.fieldIndex > generateFieldType {
+			gkp.fieldIndex--
+			gkp.setFieldFocus()
+		}
+	case "down":
+		if gkp.fieldIndex < totalGenerateFields-1 {
+			gkp.fieldIndex++
+			gkp.setFieldFocus()
+		}
+	case "enter":
+		preset := keyPresets[gkp.presetIdx]
+		spec := ssh.KeySpec{
+			Algorithm: preset.algorithm,
+			Bits:      preset.bits,
+			Comment:   gkp.commentInput.Value(),
+		}
+		if gkp.onGenerate != nil {
+			if gkp.err = gkp.onGenerate(spec); gkp.err != nil {
+				break
+			}
+		}
+		gkp.Hide()
+		return true
+	default:
+		switch gkp.fieldIndex {
+		case generateFieldType:
+			switch keyMsg.String() {
+			case "left":
+				gkp.presetIdx = (gkp.presetIdx + len(keyPresets) - 1) % len(keyPresets)
+			case "right", " ":
+				gkp.presetIdx = (gkp.presetIdx + 1) % len(keyPresets)
+			default:
+				return false
+			}
+		case generateFieldComment:
+			gkp.commentInput, _ = gkp.commentInput.Update(keyMsg)
+		}
+	}
+
+	gkp.popup.SetContent(gkp.buildContent())
+	return true
+}
+
+func (gkp *GenerateKeyPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Generate Key Pair")
+
+	label := func(field int, text string) string {
+		text = fmt.Sprintf("%-8s", text)
+		if gkp.fieldIndex == field {
+			return styles.PopupItemSelected.Render(text)
+		}
+		return styles.PopupItemNormal.Render(text)
+	}
+
+	typeLine := lipgloss.JoinHorizontal(lipgloss.Left,
+		label(generateFieldType, "Type"),
+		styles.PopupText.Render(" ◀ "+keyPresets[gkp.presetIdx].label+" ▶"))
+	commentLine := lipgloss.JoinHorizontal(lipgloss.Left,
+		label(generateFieldComment, "Comment"), " ", gkp.commentInput.View())
+
+	lines := []string{title, "", typeLine, commentLine}
+	if gkp.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(fmt.Sprintf("Generation failed: %v", gkp.err)))
+	}
+	lines = append(lines, "", styles.PopupText.Render("←/→: Type  ↑/↓: Field  Enter: Generate  Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,230 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+	"yoru/sshconfig"
+	"yoru/utils/network"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const importVisibleItems = 8
+
+type ImportHostsPopup struct {
+	popup         *components.Popup
+	path          string
+	candidates    []sshconfig.Candidate
+	selectedIdx   int
+	viewportStart int
+	result        *sshconfig.Result
+	err           error
+	onImport      func([]sshconfig.Candidate) (sshconfig.Result, error)
+}
+
+func NewImportHostsPopup() *ImportHostsPopup {
+	ihp := &ImportHostsPopup{
+		popup: components.NewPopup(),
+	}
+	ihp.popup.SetWidth(76)
+	return ihp
+}
+
+// Show previews the hosts found in an ssh config and lets the user pick
+// which to import and how to resolve conflicts with saved hosts
+func (ihp *ImportHostsPopup) Show(path string, candidates []sshconfig.Candidate, onImport func([]sshconfig.Candidate) (sshconfig.Result, error)) {
+	ihp.path = path
+	ihp.candidates = candidates
+	ihp.onImport = onImport
+	ihp.selectedIdx = 0
+	ihp.viewportStart = 0
+	ihp.result = nil
+	ihp.err = nil
+
+	ihp.popup.Show(ihp.buildContent(), ihp.handleInput)
+}
+
+// ShowError reports a config that could not be read or parsed
+func (ihp *ImportHostsPopup) ShowError(path string, err error) {
+	ihp.path = path
+	ihp.candidates = nil
+	ihp.result = nil
+	ihp.err = err
+
+	ihp.popup.Show(ihp.buildContent(), ihp.handleInput)
+}
+
+func (ihp *ImportHostsPopup) Hide() {
+	ihp.popup.Hide()
+}
+
+func (ihp *ImportHostsPopup) IsVisible() bool {
+	return ihp.popup.IsVisible()
+}
+
+func (ihp *ImportHostsPopup) Update(msg tea.Msg) {
+	ihp.popup.Update(msg)
+}
+
+func (ihp *ImportHostsPopup) Render() string {
+	return ihp.popup.Render()
+}
+
+func (ihp *ImportHostsPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	// Once finished (or failed) any of these keys dismisses the popup
+	if ihp.result != nil || ihp.err != nil {
+		switch keyMsg.String() {
+		case "enter", "esc", "q":
+			ihp.Hide()
+			return true
+		}
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if ihp.selectedIdx > 0 {
+			ihp.selectedIdx--
+		}
+	case "down":
+		if ihp.selectedIdx < len(ihp.candidates)-1 {
+			ihp.selectedIdx++
+		}
+	case " ":
+		if len(ihp.candidates) > 0 {
+			candidate := &ihp.candidates[ihp.selectedIdx]
+			candidate.Selected = !candidate.Selected
+		}
+	case "a":
+		allSelected := true
+		for _, candidate := range ihp.candidates {
+			allSelected = allSelected && candidate.Selected
+		}
+		for i := range ihp.candidates {
+			ihp.candidates[i].Selected = !allSelected
+		}
+	case "c":
+		if len(ihp.candidates) > 0 {
+			candidate := &ihp.candidates[ihp.selectedIdx]
+			if candidate.Existing != nil {
+				candidate.Action = (candidate.Action + 1) % (sshconfig.ActionRename + 1)
+			}
+		}
+	case "enter":
+		if ihp.onImport == nil {
+			ihp.Hide()
+			return true
+		}
+		result, err := ihp.onImport(ihp.candidates)
+		ihp.result = &result
+		ihp.err = err
+	case "esc":
+		ihp.Hide()
+		return true
+	default:
+		return false
+	}
+
+	ihp.popup.SetContent(ihp.buildContent())
+	return true
+}
+
+func (ihp *ImportHostsPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Import from SSH Config")
+	source := styles.PopupMessage.Render(ihp.path)
+
+	if ihp.err != nil {
+		message := styles.PopupError.Render(fmt.Sprintf("Import failed: %v", ihp.err))
+		hint := styles.PopupText.Render("Enter: Close")
+		return lipgloss.JoinVertical(lipgloss.Left, title, source, "", message, "", hint)
+	}
+
+	if ihp.result != nil {
+		return ihp.buildSummary(title, source)
+	}
+
+	if len(ihp.candidates) == 0 {
+		message := styles.PopupMessage.Render("No Host entries found.")
+		hint := styles.PopupText.Render("Esc: Close")
+		return lipgloss.JoinVertical(lipgloss.Left, title, source, "", message, "", hint)
+	}
+
+	if ihp.selectedIdx < ihp.viewportStart {
+		ihp.viewportStart = ihp.selectedIdx
+	} else if ihp.selectedIdx >= ihp.viewportStart+importVisibleItems {
+		ihp.viewportStart = ihp.selectedIdx - importVisibleItems + 1
+	}
+
+	var rows []string
+	endIdx := min(ihp.viewportStart+importVisibleItems, len(ihp.candidates))
+	for i := ihp.viewportStart; i < endIdx; i++ {
+		candidate := ihp.candidates[i]
+
+		box := "[ ]"
+		if candidate.Selected {
+			box = "[x]"
+		}
+		status := "new"
+		if candidate.Existing != nil {
+			status = candidate.Action.String()
+		}
+		user := candidate.Entry.User
+		if user == "" {
+			user = "-"
+		}
+		address := network.JoinHostPort(candidate.Entry.HostName, candidate.Entry.Port)
+		row := fmt.Sprintf("%s %-16.16s %-28.28s %-10.10s %s", box, candidate.Entry.Alias, address, user, status)
+
+		if i == ihp.selectedIdx {
+			rows = append(rows, styles.PopupItemSelected.Render(row))
+		} else {
+			rows = append(rows, styles.PopupItemNormal.Render(row))
+		}
+	}
+
+	conflicts := 0
+	for _, candidate := range ihp.candidates {
+		if candidate.Existing != nil {
+			conflicts++
+		}
+	}
+	message := styles.PopupMessage.Render(fmt.Sprintf("%d hosts found, %d already saved.", len(ihp.candidates), conflicts))
+	hint := styles.PopupText.Render("Space: Toggle  a: All  c: On conflict  Enter: Import  Esc: Cancel")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		source,
+		message,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		hint,
+	)
+}
+
+func (ihp *ImportHostsPopup) buildSummary(title, source string) string {
+	summary := styles.PopupMessage.Render(fmt.Sprintf("%d created, %d updated, %d skipped, %d keys added.",
+		ihp.result.Created, ihp.result.Updated, ihp.result.Skipped, ihp.result.KeysCreated))
+
+	lines := []string{title, source, "", summary}
+	for i, warning := range ihp.result.Warnings {
+		if i == importVisibleItems {
+			lines = append(lines, styles.PopupText.Render(fmt.Sprintf("… %d more warnings", len(ihp.result.Warnings)-i)))
+			break
+		}
+		if runes := []rune(warning); len(runes) > 70 {
+			warning = string(runes[:69]) + "…"
+		}
+		lines = append(lines, styles.PopupError.Render(warning))
+	}
+	lines = append(lines, "", styles.PopupText.Render("Enter: Close"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
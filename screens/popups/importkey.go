@@ -0,0 +1,138 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ImportKeyPopup struct {
+	popup           *components.Popup
+	pathInput       textinput.Model
+	passphraseInput textinput.Model
+	passphraseFocus bool
+	err             error
+	onImport        func(path, passphrase string) error
+}
+
+func NewImportKeyPopup() *ImportKeyPopup {
+	pathInput := textinput.New()
+	pathInput.Placeholder = "~/.ssh/id_ed25519 or key.ppk"
+	pathInput.CharLimit = 255
+	pathInput.Width = 44
+
+	passphraseInput := textinput.New()
+	passphraseInput.Placeholder = "only for encrypted keys"
+	passphraseInput.CharLimit = 100
+	passphraseInput.Width = 36
+	passphraseInput.EchoMode = textinput.EchoPassword
+
+	ikp := &ImportKeyPopup{
+		popup:           components.NewPopup(),
+		pathInput:       pathInput,
+		passphraseInput: passphraseInput,
+	}
+	ikp.popup.SetWidth(64)
+	return ikp
+}
+
+// Show asks for a private key file to add to the keychain; onImport
+// converts and saves it, and the popup stays open if that fails
+func (ikp *ImportKeyPopup) Show(onImport func(path, passphrase string) error) {
+	ikp.onImport = onImport
+	ikp.err = nil
+	ikp.pathInput.SetValue("")
+	ikp.passphraseInput.SetValue("")
+	ikp.setPassphraseFocus(false)
+
+	ikp.popup.Show(ikp.buildContent(), ikp.handleInput)
+}
+
+func (ikp *ImportKeyPopup) Hide() {
+	ikp.popup.Hide()
+}
+
+func (ikp *ImportKeyPopup) IsVisible() bool {
+	return ikp.popup.IsVisible()
+}
+
+func (ikp *ImportKeyPopup) Update(msg tea.Msg) {
+	ikp.popup.Update(msg)
+}
+
+func (ikp *ImportKeyPopup) Render() string {
+	return ikp.popup.Render()
+}
+
+func (ikp *ImportKeyPopup) setPassphraseFocus(focused bool) {
+	ikp.passphraseFocus = focused
+	if focused {
+		ikp.pathInput.Blur()
+		ikp.passphraseInput.Focus()
+	} else {
+		ikp.passphraseInput.Blur()
+		ikp.pathInput.Focus()
+	}
+}
+
+func (ikp *ImportKeyPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		ikp.Hide()
+		return true
+	case "enter":
+		if ikp.onImport != nil {
+			if ikp.err = ikp.onImport(ikp.pathInput.Value(), ikp.passphraseInput.Value()); ikp.err != nil {
+				break
+			}
+		}
+		ikp.Hide()
+		return true
+	case "up":
+		ikp.setPassphraseFocus(false)
+	case "down":
+		ikp.setPassphraseFocus(true)
+	default:
+		ikp.err = nil
+		if ikp.passphraseFocus {
+			ikp.passphraseInput, _ = ikp.passphraseInput.Update(keyMsg)
+		} else {
+			ikp.pathInput, _ = ikp.pathInput.Update(keyMsg)
+		}
+	}
+
+	ikp.popup.SetContent(ikp.buildContent())
+	return true
+}
+
+func (ikp *ImportKeyPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Import Key")
+	message := styles.PopupMessage.Render("PuTTY, PKCS#8, PKCS#1 and SEC1 keys are converted to OpenSSH.")
+
+	label := func(focused bool, text string) string {
+		text = fmt.Sprintf("%-11s", text)
+		if focused {
+			return styles.PopupItemSelected.Render(text)
+		}
+		return styles.PopupItemNormal.Render(text)
+	}
+	pathLine := lipgloss.JoinHorizontal(lipgloss.Left, label(!ikp.passphraseFocus, "Path"), " ", ikp.pathInput.View())
+	passphraseLine := lipgloss.JoinHorizontal(lipgloss.Left, label(ikp.passphraseFocus, "Passphrase"), " ", ikp.passphraseInput.View())
+
+	lines := []string{title, message, "", pathLine, passphraseLine}
+	if ikp.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(fmt.Sprintf("Import failed: %v", ikp.err)))
+	}
+	lines = append(lines, "", styles.PopupText.Render("↑/↓: Field  Enter: Import  Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,198 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/models"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const installKeyVisibleItems = 8
+
+type installKeyState int
+
+const (
+	installKeyChoosing installKeyState = iota
+	installKeyRunning
+	installKeyDone
+)
+
+type InstallKeyPopup struct {
+	popup         *components.Popup
+	hostName      string
+	keys          []models.Key
+	selectedIdx   int
+	viewportStart int
+	switchToKey   bool
+	state         installKeyState
+	result        string
+	err           error
+	onInstall     func(key *models.Key, switchToKey bool)
+}
+
+func NewInstallKeyPopup() *InstallKeyPopup {
+	ikp := &InstallKeyPopup{
+		popup: components.NewPopup(),
+	}
+	ikp.popup.SetWidth(64)
+	return ikp
+}
+
+// Show lists the keychain keys that can be installed on a host. onInstall
+// starts the installation; its outcome is passed to SetResult.
+func (ikp *InstallKeyPopup) Show(hostName string, keys []models.Key, onInstall func(*models.Key, bool)) {
+	ikp.hostName = hostName
+	ikp.keys = keys
+	ikp.onInstall = onInstall
+	ikp.selectedIdx = 0
+	ikp.viewportStart = 0
+	ikp.switchToKey = true
+	ikp.state = installKeyChoosing
+	ikp.result = ""
+	ikp.err = nil
+
+	ikp.popup.Show(ikp.buildContent(), ikp.handleInput)
+}
+
+// SetResult shows how the installation went
+func (ikp *InstallKeyPopup) SetResult(result string, err error) {
+	ikp.state = installKeyDone
+	ikp.result = result
+	ikp.err = err
+	ikp.popup.SetContent(ikp.buildContent())
+}
+
+func (ikp *InstallKeyPopup) Hide() {
+	ikp.popup.Hide()
+}
+
+func (ikp *InstallKeyPopup) IsVisible() bool {
+	return ikp.popup.IsVisible()
+}
+
+func (ikp *InstallKeyPopup) Update(msg tea.Msg) {
+	ikp.popup.Update(msg)
+}
+
+func (ikp *InstallKeyPopup) Render() string {
+	return ikp.popup.Render()
+}
+
+func (ikp *InstallKeyPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch ikp.state {
+	case installKeyRunning:
+		// The installation carries on in the background and reports later
+		if keyMsg.String() == "esc" {
+			ikp.Hide()
+			return true
+		}
+		return false
+	case installKeyDone:
+		switch keyMsg.String() {
+		case "enter", "esc":
+			ikp.Hide()
+			return true
+		}
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "up":
+		if ikp.selectedIdx > 0 {
+			ikp.selectedIdx--
+		}
+	case "down":
+		if ikp.selectedIdx < len(ikp.keys)-1 {
+			ikp.selectedIdx++
+		}
+	case " ":
+		ikp.switchToKey = !ikp.switchToKey
+	case "enter":
+		if len(ikp.keys) == 0 || ikp.onInstall == nil {
+			ikp.Hide()
+			return true
+		}
+		ikp.state = installKeyRunning
+		ikp.onInstall(&ikp.keys[ikp.selectedIdx], ikp.switchToKey)
+	case "esc":
+		ikp.Hide()
+		return true
+	default:
+		return false
+	}
+
+	ikp.popup.SetContent(ikp.buildContent())
+	return true
+}
+
+func (ikp *InstallKeyPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Install Key")
+	message := styles.PopupMessage.Render(fmt.Sprintf("Add a public key to ~/.ssh/authorized_keys on \"%s\"", ikp.hostName))
+
+	switch ikp.state {
+	case installKeyRunning:
+		key := ikp.keys[ikp.selectedIdx]
+		status := styles.PopupText.Render(fmt.Sprintf("Installing \"%s\"…", key.Name))
+		return lipgloss.JoinVertical(lipgloss.Left, title, message, "", status, "", styles.PopupText.Render("Esc: Close"))
+	case installKeyDone:
+		status := styles.PopupMessage.Render(ikp.result)
+		if ikp.err != nil {
+			status = styles.PopupError.Render(fmt.Sprintf("Installation failed: %v", ikp.err))
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, title, message, "", status, "", styles.PopupText.Render("Enter: Close"))
+	}
+
+	if len(ikp.keys) == 0 {
+		status := styles.PopupMessage.Render("The keychain has no keys. Generate one with g in the keychain.")
+		return lipgloss.JoinVertical(lipgloss.Left, title, message, "", status, "", styles.PopupText.Render("Esc: Close"))
+	}
+
+	if ikp.selectedIdx < ikp.viewportStart {
+		ikp.viewportStart = ikp.selectedIdx
+	} else if ikp.selectedIdx >= ikp.viewportStart+installKeyVisibleItems {
+		ikp.viewportStart = ikp.selectedIdx - installKeyVisibleItems + 1
+	}
+
+	var rows []string
+	endIdx := min(ikp.viewportStart+installKeyVisibleItems, len(ikp.keys))
+	for i := ikp.viewportStart; i < endIdx; i++ {
+		key := ikp.keys[i]
+		username := key.Username
+		if username == "" {
+			username = "-"
+		}
+		row := fmt.Sprintf("%-32.32s %s", key.Name, username)
+		if i == ikp.selectedIdx {
+			rows = append(rows, styles.PopupItemSelected.Render(row))
+		} else {
+			rows = append(rows, styles.PopupItemNormal.Render(row))
+		}
+	}
+
+	checkboxIcon := "[ ]"
+	checkboxStyle := styles.PopupCheckbox
+	if ikp.switchToKey {
+		checkboxIcon = "[x]"
+		checkboxStyle = styles.PopupCheckboxChecked
+	}
+	checkbox := checkboxStyle.Render(checkboxIcon + " Use this key for the host afterwards")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		message,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		checkbox,
+		"",
+		styles.PopupText.Render("Space: Toggle  Enter: Install  Esc: Cancel"),
+	)
+}
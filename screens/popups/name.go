@@ -0,0 +1,103 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type NamePopup struct {
+	popup     *components.Popup
+	title     string
+	nameInput textinput.Model
+	err       error
+	onSubmit  func(name string) error
+}
+
+func NewNamePopup() *NamePopup {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Name"
+	nameInput.CharLimit = 100
+	nameInput.Width = 36
+
+	np := &NamePopup{
+		popup:     components.NewPopup(),
+		nameInput: nameInput,
+	}
+	np.popup.SetWidth(64)
+	return np
+}
+
+// Show asks for a name, starting from name; an error from onSubmit is
+// shown and keeps the popup open
+func (np *NamePopup) Show(title, name string, onSubmit func(name string) error) {
+	np.title = title
+	np.onSubmit = onSubmit
+	np.err = nil
+	np.nameInput.SetValue(name)
+	np.nameInput.CursorEnd()
+	np.nameInput.Focus()
+
+	np.popup.Show(np.buildContent(), np.handleInput)
+}
+
+func (np *NamePopup) Hide() {
+	np.nameInput.Blur()
+	np.popup.Hide()
+}
+
+func (np *NamePopup) IsVisible() bool {
+	return np.popup.IsVisible()
+}
+
+func (np *NamePopup) Update(msg tea.Msg) {
+	np.popup.Update(msg)
+}
+
+func (np *NamePopup) Render() string {
+	return np.popup.Render()
+}
+
+func (np *NamePopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		np.Hide()
+		return true
+	case "enter":
+		if np.onSubmit != nil {
+			if np.err = np.onSubmit(np.nameInput.Value()); np.err != nil {
+				break
+			}
+		}
+		np.Hide()
+		return true
+	default:
+		np.err = nil
+		np.nameInput, _ = np.nameInput.Update(keyMsg)
+	}
+
+	np.popup.SetContent(np.buildContent())
+	return true
+}
+
+func (np *NamePopup) buildContent() string {
+	title := styles.PopupTitle.Render(np.title)
+	nameLine := lipgloss.JoinHorizontal(lipgloss.Left, styles.PopupItemSelected.Render(fmt.Sprintf("%-11s", "Name")), " ", np.nameInput.View())
+
+	lines := []string{title, "", nameLine}
+	if np.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(np.err.Error()))
+	}
+	lines = append(lines, "", styles.PopupText.Render("Enter: Save  Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
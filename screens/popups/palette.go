@@ -0,0 +1,185 @@
+package popups
+
+import (
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	paletteVisibleItems = 10
+	paletteLineWidth    = 66
+)
+
+// PaletteItem is an entry of the command palette. An item without Run is
+// shown but cannot be chosen, to explain why something is unavailable.
+// Close, if set, is run by Ctrl+W, to close what the item stands for.
+type PaletteItem struct {
+	Title  string
+	Detail string
+	Run    func() tea.Cmd
+	Close  func() tea.Cmd
+}
+
+type CommandPalette struct {
+	popup         *components.Popup
+	title         string
+	input         textinput.Model
+	items         []PaletteItem
+	selectedIdx   int
+	viewportStart int
+	search        func(query string) []PaletteItem
+	command       tea.Cmd
+}
+
+func NewCommandPalette() *CommandPalette {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 255
+	input.Width = 62
+
+	cp := &CommandPalette{
+		popup: components.NewPopup(),
+		input: input,
+	}
+	cp.popup.SetWidth(72)
+	return cp
+}
+
+// Show opens the palette; search lists the items for what has been typed
+// and placeholder hints at what can be
+func (cp *CommandPalette) Show(title, placeholder string, search func(query string) []PaletteItem) {
+	cp.title = title
+	cp.input.Placeholder = placeholder
+	cp.search = search
+	cp.command = nil
+	cp.input.SetValue("")
+	cp.input.Focus()
+	cp.refresh()
+
+	cp.popup.Show(cp.buildContent(), cp.handleInput)
+}
+
+func (cp *CommandPalette) Hide() {
+	cp.input.Blur()
+	cp.popup.Hide()
+}
+
+func (cp *CommandPalette) IsVisible() bool {
+	return cp.popup.IsVisible()
+}
+
+// Update handles a key while the palette is open, returning the command
+// of the item chosen, if any
+func (cp *CommandPalette) Update(msg tea.Msg) tea.Cmd {
+	cp.popup.Update(msg)
+	command := cp.command
+	cp.command = nil
+	return command
+}
+
+func (cp *CommandPalette) Render() string {
+	return cp.popup.Render()
+}
+
+func (cp *CommandPalette) refresh() {
+	cp.items = nil
+	if cp.search != nil {
+		cp.items = cp.search(cp.input.Value())
+	}
+	cp.selectedIdx = 0
+	cp.viewportStart = 0
+}
+
+func (cp *CommandPalette) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		cp.Hide()
+		return true
+	case "enter":
+		if cp.selectedIdx < len(cp.items) && cp.items[cp.selectedIdx].Run != nil {
+			cp.Hide()
+			cp.command = cp.items[cp.selectedIdx].Run()
+			return true
+		}
+	case "ctrl+w":
+		if cp.selectedIdx < len(cp.items) && cp.items[cp.selectedIdx].Close != nil {
+			cp.Hide()
+			cp.command = cp.items[cp.selectedIdx].Close()
+			return true
+		}
+		cp.updateInput(keyMsg)
+	case "up":
+		if cp.selectedIdx > 0 {
+			cp.selectedIdx--
+		}
+	case "down":
+		if cp.selectedIdx < len(cp.items)-1 {
+			cp.selectedIdx++
+		}
+	default:
+		cp.updateInput(keyMsg)
+	}
+
+	if cp.selectedIdx < cp.viewportStart {
+		cp.viewportStart = cp.selectedIdx
+	} else if cp.selectedIdx >= cp.viewportStart+paletteVisibleItems {
+		cp.viewportStart = cp.selectedIdx - paletteVisibleItems + 1
+	}
+
+	cp.popup.SetContent(cp.buildContent())
+	return true
+}
+
+// updateInput passes a key to the query, searching again if it changed
+func (cp *CommandPalette) updateInput(keyMsg tea.KeyMsg) {
+	value := cp.input.Value()
+	cp.input, _ = cp.input.Update(keyMsg)
+	if cp.input.Value() != value {
+		cp.refresh()
+	}
+}
+
+func (cp *CommandPalette) buildContent() string {
+	lines := []string{styles.PopupTitle.Render(cp.title), cp.input.View(), ""}
+
+	if len(cp.items) == 0 {
+		lines = append(lines, styles.PopupText.Render("Nothing matches."))
+	}
+	end := min(cp.viewportStart+paletteVisibleItems, len(cp.items))
+	for i := cp.viewportStart; i < end; i++ {
+		item := cp.items[i]
+		title := "  " + item.Title
+		style := styles.PopupItemNormal
+		if i == cp.selectedIdx {
+			title = "▸ " + item.Title
+			style = styles.PopupItemSelected
+		}
+		line := style.Render(title)
+		if item.Detail != "" {
+			line = lipgloss.JoinHorizontal(lipgloss.Left, line, styles.PopupText.Render("  "+item.Detail))
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(paletteLineWidth).Render(line))
+	}
+	if len(cp.items) > paletteVisibleItems {
+		lines = append(lines, styles.PopupText.Render("  …"))
+	}
+
+	help := "↑/↓: Select  Enter: Run  Esc: Close"
+	for _, item := range cp.items {
+		if item.Close != nil {
+			help = "↑/↓: Select  Enter: Run  Ctrl+W: Close tab  Esc: Close"
+			break
+		}
+	}
+	lines = append(lines, "", styles.PopupText.Render(help))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,144 @@
+package popups
+
+import (
+	"fmt"
+	"strings"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const pastePreviewLines = 3
+
+type PasteConfirmPopup struct {
+	popup          *components.Popup
+	lines          []string
+	checkboxValue  bool
+	selectedButton int // 0 = No, 1 = Yes
+	onConfirm      func(dontAskAgain bool)
+	onCancel       func()
+}
+
+func NewPasteConfirmPopup() *PasteConfirmPopup {
+	pcp := &PasteConfirmPopup{
+		popup:         components.NewPopup(),
+		checkboxValue: false,
+	}
+	pcp.popup.SetHeightOffset(1)
+	return pcp
+}
+
+func (pcp *PasteConfirmPopup) Show(text string, onConfirm func(bool), onCancel func()) {
+	pcp.lines = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	pcp.onConfirm = onConfirm
+	pcp.onCancel = onCancel
+	pcp.checkboxValue = false
+	pcp.selectedButton = 0 // Default to No
+
+	pcp.popup.Show(pcp.buildContent(), pcp.handleInput)
+}
+
+func (pcp *PasteConfirmPopup) Hide() {
+	pcp.popup.Hide()
+}
+
+func (pcp *PasteConfirmPopup) IsVisible() bool {
+	return pcp.popup.IsVisible()
+}
+
+func (pcp *PasteConfirmPopup) Update(msg tea.Msg) {
+	pcp.popup.Update(msg)
+}
+
+func (pcp *PasteConfirmPopup) Render() string {
+	return pcp.popup.Render()
+}
+
+func (pcp *PasteConfirmPopup) handleInput(msg tea.Msg) bool {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case " ":
+			pcp.checkboxValue = !pcp.checkboxValue
+			pcp.popup.SetContent(pcp.buildContent())
+			return true
+		case "left":
+			pcp.selectedButton = 1 // Yes
+			pcp.popup.SetContent(pcp.buildContent())
+			return true
+		case "right":
+			pcp.selectedButton = 0 // No
+			pcp.popup.SetContent(pcp.buildContent())
+			return true
+		case "enter":
+			pcp.Hide()
+			if pcp.selectedButton == 1 {
+				if pcp.onConfirm != nil {
+					pcp.onConfirm(pcp.checkboxValue)
+				}
+			} else {
+				if pcp.onCancel != nil {
+					pcp.onCancel()
+				}
+			}
+			return true
+		case "y", "Y":
+			pcp.Hide()
+			if pcp.onConfirm != nil {
+				pcp.onConfirm(pcp.checkboxValue)
+			}
+			return true
+		case "n", "N", "esc":
+			pcp.Hide()
+			if pcp.onCancel != nil {
+				pcp.onCancel()
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (pcp *PasteConfirmPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Paste Text")
+	message := styles.PopupMessage.Render(fmt.Sprintf("You are about to paste %d lines. Paste them?", len(pcp.lines)))
+
+	preview := make([]string, 0, pastePreviewLines+1)
+	for i, line := range pcp.lines {
+		if i == pastePreviewLines {
+			preview = append(preview, fmt.Sprintf("… %d more", len(pcp.lines)-pastePreviewLines))
+			break
+		}
+		if runes := []rune(line); len(runes) > 50 {
+			line = string(runes[:49]) + "…"
+		}
+		preview = append(preview, line)
+	}
+	previewBox := styles.PopupLogBox.Width(54).Render(lipgloss.JoinVertical(lipgloss.Left, preview...))
+
+	checkboxIcon := "[ ]"
+	checkboxStyle := styles.PopupCheckbox
+	if pcp.checkboxValue {
+		checkboxIcon = "[x]"
+		checkboxStyle = styles.PopupCheckboxChecked
+	}
+	checkbox := checkboxStyle.Render(checkboxIcon + " Never ask this again (Space)")
+
+	yesPrefix := "  "
+	noPrefix := "  "
+	if pcp.selectedButton == 1 {
+		yesPrefix = "> "
+	} else {
+		noPrefix = "> "
+	}
+
+	yesButton := styles.PopupButtonYes.Render(yesPrefix + "Yes (y)")
+	noButton := styles.PopupButtonNo.Render(noPrefix + "No (n)")
+
+	buttons := lipgloss.JoinHorizontal(lipgloss.Top, yesButton, "  ", noButton)
+	buttonsContainer := lipgloss.NewStyle().Width(56).Align(lipgloss.Right).Render(buttons)
+	buttonsWithMargin := styles.PopupButtonsContainer.Render(buttonsContainer)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, message, previewBox, checkbox, buttonsWithMargin)
+}
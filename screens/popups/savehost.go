@@ -0,0 +1,150 @@
+package popups
+
+import (
+	"fmt"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type SaveHostPopup struct {
+	popup      *components.Popup
+	target     string
+	login      string
+	nameInput  textinput.Model
+	groupInput textinput.Model
+	groupFocus bool
+	err        error
+	onSave     func(name, group string) error
+	onClose    func()
+}
+
+func NewSaveHostPopup() *SaveHostPopup {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Host name"
+	nameInput.CharLimit = 100
+	nameInput.Width = 36
+
+	groupInput := textinput.New()
+	groupInput.Placeholder = "optional, e.g. prod/eu-west"
+	groupInput.CharLimit = 255
+	groupInput.Width = 36
+
+	shp := &SaveHostPopup{
+		popup:      components.NewPopup(),
+		nameInput:  nameInput,
+		groupInput: groupInput,
+	}
+	shp.popup.SetWidth(64)
+	return shp
+}
+
+// Show offers to save a quick connect target as a host; login describes
+// the credential it will use. onClose runs however the popup is left.
+func (shp *SaveHostPopup) Show(target, login string, onSave func(name, group string) error, onClose func()) {
+	shp.target = target
+	shp.login = login
+	shp.onSave = onSave
+	shp.onClose = onClose
+	shp.err = nil
+	shp.nameInput.SetValue(target)
+	shp.nameInput.CursorEnd()
+	shp.groupInput.SetValue("")
+	shp.setGroupFocus(false)
+
+	shp.popup.Show(shp.buildContent(), shp.handleInput)
+}
+
+func (shp *SaveHostPopup) Hide() {
+	shp.popup.Hide()
+	if shp.onClose != nil {
+		shp.onClose()
+	}
+}
+
+func (shp *SaveHostPopup) IsVisible() bool {
+	return shp.popup.IsVisible()
+}
+
+func (shp *SaveHostPopup) Update(msg tea.Msg) {
+	shp.popup.Update(msg)
+}
+
+func (shp *SaveHostPopup) Render() string {
+	return shp.popup.Render()
+}
+
+func (shp *SaveHostPopup) setGroupFocus(focused bool) {
+	shp.groupFocus = focused
+	if focused {
+		shp.nameInput.Blur()
+		shp.groupInput.Focus()
+	} else {
+		shp.groupInput.Blur()
+		shp.nameInput.Focus()
+	}
+}
+
+func (shp *SaveHostPopup) handleInput(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return false
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		shp.Hide()
+		return true
+	case "enter":
+		if shp.onSave != nil {
+			if shp.err = shp.onSave(shp.nameInput.Value(), shp.groupInput.Value()); shp.err != nil {
+				break
+			}
+		}
+		shp.Hide()
+		return true
+	case "up":
+		shp.setGroupFocus(false)
+	case "down":
+		shp.setGroupFocus(true)
+	default:
+		shp.err = nil
+		if shp.groupFocus {
+			shp.groupInput, _ = shp.groupInput.Update(keyMsg)
+		} else {
+			shp.nameInput, _ = shp.nameInput.Update(keyMsg)
+		}
+	}
+
+	shp.popup.SetContent(shp.buildContent())
+	return true
+}
+
+func (shp *SaveHostPopup) buildContent() string {
+	title := styles.PopupTitle.Render("Save Host")
+	message := styles.PopupMessage.Render(fmt.Sprintf("Save %s as a host?", shp.target))
+
+	label := func(focused bool, text string) string {
+		text = fmt.Sprintf("%-11s", text)
+		if focused {
+			return styles.PopupItemSelected.Render(text)
+		}
+		return styles.PopupItemNormal.Render(text)
+	}
+	nameLine := lipgloss.JoinHorizontal(lipgloss.Left, label(!shp.groupFocus, "Name"), " ", shp.nameInput.View())
+	groupLine := lipgloss.JoinHorizontal(lipgloss.Left, label(shp.groupFocus, "Group"), " ", shp.groupInput.View())
+
+	lines := []string{title, message, "", nameLine, groupLine}
+	if shp.login != "" {
+		lines = append(lines, "", styles.PopupText.Render(shp.login))
+	}
+	if shp.err != nil {
+		lines = append(lines, "", styles.PopupError.Render(fmt.Sprintf("Save failed: %v", shp.err)))
+	}
+	lines = append(lines, "", styles.PopupText.Render("↑/↓: Field  Enter: Save  Esc: Skip"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,567 @@
+package screens
+
+import (
+	"strings"
+	"yoru/screens/components"
+	"yoru/screens/styles"
+	"yoru/shared"
+	"yoru/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	splitResizeStep = 0.05
+	splitMinRatio   = 0.1
+	splitMaxRatio   = 0.9
+	paneMinWidth    = 10
+	paneMinHeight   = 3
+)
+
+// splitRequestMsg asks for a host to open next to the focused terminal
+type splitRequestMsg struct {
+	vertical bool
+}
+
+// splitPaneMsg opens a terminal next to the focused one in the current tab
+type splitPaneMsg struct {
+	screen   *terminalScreen
+	vertical bool
+}
+
+// paneNode is a node of a split layout: either a terminal (a leaf), or two
+// nodes side by side (vertical) or stacked, the first taking ratio of the
+// room
+type paneNode struct {
+	screen   *terminalScreen
+	vertical bool
+	ratio    float64
+	first    *paneNode
+	second   *paneNode
+	parent   *paneNode
+}
+
+type paneRect struct {
+	x, y, width, height int
+}
+
+// NewSplitScreen creates a tab that holds terminal panes, starting with
+// screen alone
+func NewSplitScreen(screen *terminalScreen) *splitScreen {
+	leaf := &paneNode{screen: screen}
+	return &splitScreen{root: leaf, focused: leaf}
+}
+
+func (screen *splitScreen) Init() tea.Cmd {
+	return nil
+}
+
+// split opens pane next to the focused one and focuses it
+func (screen *splitScreen) split(pane *terminalScreen, vertical bool) {
+	screen.zoomed = false
+
+	current := screen.focused
+	leaf := &paneNode{screen: pane}
+	moved := &paneNode{screen: current.screen}
+	current.screen = nil
+	current.vertical = vertical
+	current.ratio = 0.5
+	current.first, current.second = moved, leaf
+	moved.parent, leaf.parent = current, current
+
+	screen.focused = leaf
+	screen.layout()
+}
+
+// remove closes the pane of a terminal, reporting whether it was here
+func (screen *splitScreen) remove(pane types.Screen) bool {
+	var target *paneNode
+	screen.walk(screen.root, func(node *paneNode) {
+		if node.screen == pane {
+			target = node
+		}
+	})
+	if target == nil {
+		return false
+	}
+
+	screen.zoomed = false
+	parent := target.parent
+	if parent == nil {
+		screen.root = nil
+		screen.focused = nil
+		return true
+	}
+
+	// The sibling takes the parent's place
+	sibling := parent.first
+	if sibling == target {
+		sibling = parent.second
+	}
+	sibling.parent = parent.parent
+	switch {
+	case parent.parent == nil:
+		screen.root = sibling
+	case parent.parent.first == parent:
+		parent.parent.first = sibling
+	default:
+		parent.parent.second = sibling
+	}
+
+	if screen.focused == target {
+		screen.focused = firstLeaf(sibling)
+	}
+	screen.layout()
+	return true
+}
+
+// single returns the only pane left, if there is just one
+func (screen *splitScreen) single() *terminalScreen {
+	if screen.root != nil && screen.root.screen != nil {
+		return screen.root.screen
+	}
+	return nil
+}
+
+// panes lists the terminals of the split
+func (screen *splitScreen) panes() []*terminalScreen {
+	var panes []*terminalScreen
+	screen.walk(screen.root, func(node *paneNode) {
+		panes = append(panes, node.screen)
+	})
+	return panes
+}
+
+// focusedPane returns the terminal that gets the keys
+func (screen *splitScreen) focusedPane() *terminalScreen {
+	if screen.focused == nil {
+		return nil
+	}
+	return screen.focused.screen
+}
+
+func (screen *splitScreen) walk(node *paneNode, visit func(leaf *paneNode)) {
+	if node == nil {
+		return
+	}
+	if node.screen != nil {
+		visit(node)
+		return
+	}
+	screen.walk(node.first, visit)
+	screen.walk(node.second, visit)
+}
+
+func firstLeaf(node *paneNode) *paneNode {
+	for node.screen == nil {
+		node = node.first
+	}
+	return node
+}
+
+// rects computes where each pane goes in the tab. Each pane has a title
+// line on top, and side by side panes are divided by a column.
+func (screen *splitScreen) rects() map[*paneNode]paneRect {
+	rects := make(map[*paneNode]paneRect)
+	area := paneRect{0, 0, shared.GlobalState.ScreenWidth, shared.GlobalState.ScreenHeight - 1}
+	if screen.zoomed {
+		rects[screen.focused] = area
+		return rects
+	}
+
+	var place func(node *paneNode, rect paneRect)
+	place = func(node *paneNode, rect paneRect) {
+		if node.screen != nil {
+			rects[node] = rect
+			return
+		}
+		first, second := rect, rect
+		if node.vertical {
+			room := rect.width - 1
+			first.width = clampSize(int(float64(room)*node.ratio), paneMinWidth, room-paneMinWidth)
+			second.x = rect.x + first.width + 1
+			second.width = room - first.width
+		} else {
+			first.height = clampSize(int(float64(rect.height)*node.ratio), paneMinHeight, rect.height-paneMinHeight)
+			second.y = rect.y + first.height
+			second.height = rect.height - first.height
+		}
+		place(node.first, first)
+		place(node.second, second)
+	}
+	place(screen.root, area)
+	return rects
+}
+
+func clampSize(value, low, high int) int {
+	if high < low {
+		return max(high, 1)
+	}
+	return max(low, min(value, high))
+}
+
+// layout resizes each terminal, and its remote PTY, to its pane
+func (screen *splitScreen) layout() {
+	for node, rect := range screen.rects() {
+		node.screen.resize(rect.width, max(rect.height-1, 1))
+	}
+}
+
+func (screen *splitScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
+	focused := screen.focusedPane()
+
+	switch message := msg.(type) {
+	case tea.WindowSizeMsg:
+		screen.layout()
+		return screen, nil
+
+	case tea.KeyMsg:
+		if focused == nil {
+			return screen, nil
+		}
+		if focused.hasPopup() || focused.GetKeyCaptureMode() == types.KeyCaptureTerminal {
+			_, cmd := focused.Update(msg)
+			return screen, cmd
+		}
+		if screen.OnKeyPress(message) {
+			return screen, nil
+		}
+		_, cmd := focused.Update(msg)
+		return screen, cmd
+
+	case tea.MouseMsg:
+		return screen, screen.handleMouse(message)
+	}
+
+	// Session messages are for whichever pane has the host
+	var commands []tea.Cmd
+	for _, pane := range screen.panes() {
+		_, cmd := pane.Update(msg)
+		commands = append(commands, cmd)
+	}
+	return screen, tea.Batch(commands...)
+}
+
+// OnKeyPress handles the keys that move between and resize panes while the
+// focused one is in normal mode, reporting whether it used the key
+func (screen *splitScreen) OnKeyPress(key tea.KeyMsg) bool {
+	switch key.String() {
+	case "left":
+		screen.focusToward(-1, 0)
+	case "right":
+		screen.focusToward(1, 0)
+	case "up":
+		screen.focusToward(0, -1)
+	case "down":
+		screen.focusToward(0, 1)
+	case "o":
+		// Next pane, in layout order
+		panes := screen.leaves()
+		for index, leaf := range panes {
+			if leaf == screen.focused {
+				screen.focused = panes[(index+1)%len(panes)]
+				break
+			}
+		}
+		if screen.zoomed {
+			screen.layout()
+		}
+	case "shift+left":
+		screen.resizeFocused(true, -splitResizeStep)
+	case "shift+right":
+		screen.resizeFocused(true, splitResizeStep)
+	case "shift+up":
+		screen.resizeFocused(false, -splitResizeStep)
+	case "shift+down":
+		screen.resizeFocused(false, splitResizeStep)
+	case "z":
+		screen.zoomed = !screen.zoomed
+		screen.layout()
+	case "=":
+		screen.equalize(screen.root)
+		screen.layout()
+	default:
+		return false
+	}
+	return true
+}
+
+func (screen *splitScreen) leaves() []*paneNode {
+	var leaves []*paneNode
+	screen.walk(screen.root, func(node *paneNode) {
+		leaves = append(leaves, node)
+	})
+	return leaves
+}
+
+// focusToward focuses the nearest pane in a direction that overlaps the
+// focused one across it
+func (screen *splitScreen) focusToward(dx, dy int) {
+	if screen.zoomed {
+		return
+	}
+
+	rects := screen.rects()
+	from := rects[screen.focused]
+	var best *paneNode
+	bestDistance := 0
+	for node, rect := range rects {
+		var distance int
+		var overlaps bool
+		switch {
+		case dx < 0:
+			distance = from.x - (rect.x + rect.width)
+			overlaps = rect.y < from.y+from.height && from.y < rect.y+rect.height
+		case dx > 0:
+			distance = rect.x - (from.x + from.width)
+			overlaps = rect.y < from.y+from.height && from.y < rect.y+rect.height
+		case dy < 0:
+			distance = from.y - (rect.y + rect.height)
+			overlaps = rect.x < from.x+from.width && from.x < rect.x+rect.width
+		default:
+			distance = rect.y - (from.y + from.height)
+			overlaps = rect.x < from.x+from.width && from.x < rect.x+rect.width
+		}
+		if node == screen.focused || !overlaps || distance < 0 {
+			continue
+		}
+		// Ties go to the pane closest to the focused one's top left corner
+		if best == nil || distance < bestDistance ||
+			distance == bestDistance && abs(rect.x-from.x)+abs(rect.y-from.y) < abs(rects[best].x-from.x)+abs(rects[best].y-from.y) {
+			best, bestDistance = node, distance
+		}
+	}
+	if best != nil {
+		screen.focused = best
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// resizeFocused moves the divider of the nearest split around the focused
+// pane in the given orientation
+func (screen *splitScreen) resizeFocused(vertical bool, delta float64) {
+	if screen.zoomed {
+		return
+	}
+	for node := screen.focused.parent; node != nil; node = node.parent {
+		if node.vertical == vertical {
+			node.ratio = max(splitMinRatio, min(node.ratio+delta, splitMaxRatio))
+			screen.layout()
+			return
+		}
+	}
+}
+
+// equalize gives panes in a row or column the same room
+func (screen *splitScreen) equalize(node *paneNode) int {
+	if node == nil || node.screen != nil {
+		return 1
+	}
+	first := screen.equalize(node.first)
+	second := screen.equalize(node.second)
+	if node.first.screen == nil && node.first.vertical != node.vertical {
+		first = 1
+	}
+	if node.second.screen == nil && node.second.vertical != node.vertical {
+		second = 1
+	}
+	node.ratio = float64(first) / float64(first+second)
+	return first + second
+}
+
+// handleMouse focuses the pane clicked and passes the event on to it, in
+// its own coordinates
+func (screen *splitScreen) handleMouse(message tea.MouseMsg) tea.Cmd {
+	focused := screen.focusedPane()
+	if focused != nil && focused.hasPopup() {
+		_, cmd := focused.Update(message)
+		return cmd
+	}
+
+	for node, rect := range screen.rects() {
+		if message.X < rect.x || message.X >= rect.x+rect.width || message.Y < rect.y || message.Y >= rect.y+rect.height {
+			continue
+		}
+		if message.Action == tea.MouseActionPress && !tea.MouseEvent(message).IsWheel() {
+			screen.focused = node
+		}
+		if message.Y == rect.y {
+			return nil // title line
+		}
+		message.X -= rect.x
+		message.Y -= rect.y + 1
+		_, cmd := node.screen.Update(message)
+		return cmd
+	}
+	return nil
+}
+
+func (screen *splitScreen) View() string {
+	focused := screen.focusedPane()
+	if focused == nil {
+		return ""
+	}
+	// Showing the tab counts as seeing every pane, zoomed away or not
+	for _, pane := range screen.panes() {
+		pane.activity = types.ActivityNone
+	}
+
+	// Questions about the focused session take the whole tab
+	if focused.hasPopup() {
+		return focused.View()
+	}
+
+	rects := screen.rects()
+	if screen.zoomed {
+		return screen.renderPane(screen.focused, rects[screen.focused])
+	}
+
+	var render func(node *paneNode) string
+	render = func(node *paneNode) string {
+		if node.screen != nil {
+			return screen.renderPane(node, rects[node])
+		}
+		first, second := render(node.first), render(node.second)
+		if !node.vertical {
+			return lipgloss.JoinVertical(lipgloss.Left, first, second)
+		}
+		height := lipgloss.Height(first)
+		divider := styles.PaneDivider.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+		return lipgloss.JoinHorizontal(lipgloss.Top, first, divider, second)
+	}
+	return render(screen.root)
+}
+
+// renderPane draws a pane's title line and terminal within its rect
+func (screen *splitScreen) renderPane(node *paneNode, rect paneRect) string {
+	pane := node.screen
+	title := pane.host.Name
+	if sessionTitle := pane.Title(); sessionTitle != "" {
+		title = sessionTitle
+	}
+	if pane.IsSyncingInput() {
+		title = "⇉ " + title
+	}
+	if pane.IsRecording() {
+		title = "● " + title
+	}
+	if node == screen.focused && pane.GetKeyCaptureMode() == types.KeyCaptureTerminal {
+		title = "*" + title
+	}
+	if screen.zoomed {
+		title += " (zoomed, z to restore)"
+	}
+
+	titleStyle := styles.PaneTitle
+	if node == screen.focused {
+		titleStyle = styles.PaneTitleActive
+	}
+	titleLine := titleStyle.Width(rect.width).MaxWidth(rect.width).Render(" " + title)
+
+	var content string
+	if pane.hasPopup() {
+		content = lipgloss.Place(rect.width, rect.height-1, lipgloss.Center, lipgloss.Center,
+			styles.PaneNotice.Render("Waiting for an answer, focus this pane"))
+	} else {
+		content = lipgloss.NewStyle().MaxWidth(rect.width).MaxHeight(rect.height - 1).Render(pane.View())
+		content = lipgloss.Place(rect.width, rect.height-1, lipgloss.Left, lipgloss.Top, content)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, titleLine, content)
+}
+
+// GetKeyCaptureMode returns the mode of the focused pane, so the manager
+// passes it every key while it captures them
+func (screen *splitScreen) GetKeyCaptureMode() types.KeyCaptureMode {
+	if focused := screen.focusedPane(); focused != nil {
+		return focused.GetKeyCaptureMode()
+	}
+	return types.KeyCaptureNormal
+}
+
+// IsRecording reports whether any pane is being recorded
+func (screen *splitScreen) IsRecording() bool {
+	for _, pane := range screen.panes() {
+		if pane.IsRecording() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSyncingInput reports whether any pane takes part in synchronized input
+func (screen *splitScreen) IsSyncingInput() bool {
+	for _, pane := range screen.panes() {
+		if pane.IsSyncingInput() {
+			return true
+		}
+	}
+	return false
+}
+
+// Title returns the title the focused pane's session set
+func (screen *splitScreen) Title() string {
+	if focused := screen.focusedPane(); focused != nil {
+		return focused.Title()
+	}
+	return ""
+}
+
+// Activity reports the most worth a look of what happened in the panes
+func (screen *splitScreen) Activity() types.Activity {
+	activity := types.ActivityNone
+	for _, pane := range screen.panes() {
+		activity = max(activity, pane.Activity())
+	}
+	return activity
+}
+
+// IsConnected reports whether any pane has a live session
+func (screen *splitScreen) IsConnected() bool {
+	for _, pane := range screen.panes() {
+		if pane.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseSession ends the session of every pane
+func (screen *splitScreen) CloseSession() tea.Cmd {
+	var commands []tea.Cmd
+	for _, pane := range screen.panes() {
+		commands = append(commands, pane.CloseSession())
+	}
+	return tea.Batch(commands...)
+}
+
+// terminalScreens lists the terminals of every tab, including split panes
+func terminalScreens() []*terminalScreen {
+	var terminals []*terminalScreen
+	for _, tab := range components.TabBar.GetTabs() {
+		switch screen := tab.Screen.(type) {
+		case *terminalScreen:
+			terminals = append(terminals, screen)
+		case *splitScreen:
+			terminals = append(terminals, screen.panes()...)
+		}
+	}
+	return terminals
+}
+
+// focusedTerminal returns the terminal that gets the keys of a tab
+func focusedTerminal(screen types.Screen) *terminalScreen {
+	switch screen := screen.(type) {
+	case *terminalScreen:
+		return screen
+	case *splitScreen:
+		return screen.focusedPane()
+	}
+	return nil
+}
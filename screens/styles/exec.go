@@ -0,0 +1,35 @@
+package styles
+
+import (
+	"yoru/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	ExecStatus = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Surface0)).
+			Foreground(lipgloss.Color(types.Subtext1))
+
+	ExecCommand = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(types.Lavender))
+
+	ExecSelected = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Surface0))
+
+	ExecSuccess = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Green))
+
+	ExecFailure = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Red))
+
+	ExecPending = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Overlay1))
+
+	ExecStdout = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Text))
+
+	ExecStderr = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Maroon))
+)
@@ -86,4 +86,12 @@ var (
 	FormError = lipgloss.NewStyle().
 			Foreground(lipgloss.Color(types.Red)).
 			MarginLeft(12)
+
+	FormHint = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Subtext0)).
+			MarginLeft(12)
+
+	FormWarning = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Yellow)).
+			MarginLeft(12)
 )
@@ -18,6 +18,27 @@ var (
 			Foreground(lipgloss.Color(types.Subtext0)).
 			Padding(0, 3)
 
+	SyncedTab = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Peach)).
+			Foreground(lipgloss.Color(types.Base)).
+			Padding(0, 3)
+
+	ActivityTab = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Surface1)).
+			Foreground(lipgloss.Color(types.Text)).
+			Padding(0, 3).
+			Bold(true)
+
+	AlertTab = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Yellow)).
+			Foreground(lipgloss.Color(types.Base)).
+			Padding(0, 3)
+
+	TabOverflow = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Surface0)).
+			Foreground(lipgloss.Color(types.Subtext0)).
+			Align(lipgloss.Center)
+
 	TabBarBackground = lipgloss.NewStyle().
 				Background(lipgloss.Color(types.Surface0))
 
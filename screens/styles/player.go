@@ -0,0 +1,13 @@
+package styles
+
+import (
+	"yoru/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	PlayerStatus = lipgloss.NewStyle().
+		Background(lipgloss.Color(types.Surface0)).
+		Foreground(lipgloss.Color(types.Subtext1))
+)
@@ -33,6 +33,24 @@ var (
 	SidebarItemMargin = lipgloss.NewStyle().
 				MarginBottom(1)
 
+	SidebarGroup = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Subtext1)).
+			Bold(true)
+
+	SidebarGroupSelected = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(types.Lavender)).
+				Bold(true)
+
+	SidebarGroupCount = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(types.Overlay1))
+
+	SidebarTag = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Mauve))
+
+	SidebarMatch = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Peach)).
+			Underline(true)
+
 	SidebarFilterActive = lipgloss.NewStyle().
 				Foreground(lipgloss.Color(types.Lavender)).
 				Bold(true)
@@ -0,0 +1,24 @@
+package styles
+
+import (
+	"yoru/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	PaneTitle = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Surface0)).
+			Foreground(lipgloss.Color(types.Subtext0))
+
+	PaneTitleActive = lipgloss.NewStyle().
+			Background(lipgloss.Color(types.Mauve)).
+			Foreground(lipgloss.Color(types.Base)).
+			Bold(true)
+
+	PaneDivider = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Surface1))
+
+	PaneNotice = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(types.Subtext0))
+)
@@ -0,0 +1,14 @@
+package styles
+
+import (
+	"yoru/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	TerminalEnded = lipgloss.NewStyle().
+		Background(lipgloss.Color(types.Surface1)).
+		Foreground(lipgloss.Color(types.Text)).
+		Bold(true)
+)
@@ -0,0 +1,176 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"yoru/screens/popups"
+	"yoru/types"
+	"yoru/utils/fuzzy"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tabIndex finds the tab showing screen, or -1 once it has been closed
+func (manager *manager) tabIndex(screen types.Screen) int {
+	for index, tab := range manager.tabBar.GetTabs() {
+		if tab.Screen == screen {
+			return index
+		}
+	}
+	return -1
+}
+
+// closeTab closes the tab at index, asking first if its session is still
+// connected. It returns nil if there is nothing to close.
+func (manager *manager) closeTab(index int) tea.Cmd {
+	tabs := manager.tabBar.GetTabs()
+	if index <= 0 || index >= len(tabs) {
+		return nil
+	}
+
+	tab := tabs[index]
+	if holder, ok := tab.Screen.(types.SessionHolder); ok && holder.IsConnected() {
+		message := fmt.Sprintf("%q is still connected. Close it and disconnect?", tab.Title())
+		manager.confirmPopup.Show("Close Tab", message, func() tea.Cmd {
+			return manager.removeTab(tab.Screen)
+		})
+		return func() tea.Msg { return nil }
+	}
+	return manager.removeTab(tab.Screen)
+}
+
+// removeTab closes the tab of screen and ends its session
+func (manager *manager) removeTab(screen types.Screen) tea.Cmd {
+	index := manager.tabIndex(screen)
+	if index <= 0 {
+		return nil
+	}
+	manager.tabBar.RemoveTab(index)
+
+	var command tea.Cmd
+	if holder, ok := screen.(types.SessionHolder); ok {
+		command = holder.CloseSession()
+	}
+	// Redraw even when there is nothing else to run
+	return tea.Batch(command, func() tea.Msg { return nil })
+}
+
+// showRenameTab asks for a new name for the current tab, reporting whether
+// it can be renamed
+func (manager *manager) showRenameTab() bool {
+	index := manager.tabBar.GetActiveIndex()
+	tabs := manager.tabBar.GetTabs()
+	if index <= 0 || index >= len(tabs) {
+		return false
+	}
+
+	screen := tabs[index].Screen
+	manager.namePopup.Show("Rename Tab", tabs[index].Title(), func(name string) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("name is required")
+		}
+		// The tab may have moved, or closed, while the popup was open
+		if index := manager.tabIndex(screen); index > 0 {
+			manager.tabBar.RenameTab(index, name)
+		}
+		return nil
+	})
+	return true
+}
+
+// moveTab moves the current tab by delta places
+func (manager *manager) moveTab(delta int) {
+	manager.tabBar.MoveTab(manager.tabBar.GetActiveIndex(), delta)
+}
+
+// showTabs opens the palette on the open tabs, to find one among many
+func (manager *manager) showTabs() {
+	manager.palette.Show("Tabs", "tab name", func(query string) []popups.PaletteItem {
+		return manager.tabItems(strings.TrimSpace(query))
+	})
+}
+
+// tabItems lists the tabs matching query, in tab order when nothing has
+// been typed
+func (manager *manager) tabItems(query string) []popups.PaletteItem {
+	type rankedTab struct {
+		item  popups.PaletteItem
+		score int
+	}
+	var ranked []rankedTab
+	for index, tab := range manager.tabBar.GetTabs() {
+		score := 0
+		if query != "" {
+			best, found := fuzzy.Match(query, tab.Title())
+			if result, ok := fuzzy.Match(query, tab.Name); ok && (!found || result.Score > best.Score) {
+				best, found = result, true
+			}
+			if !found {
+				continue
+			}
+			score = best.Score
+		}
+		ranked = append(ranked, rankedTab{popups.PaletteItem{
+			Title:  fmt.Sprintf("%2d  %s", index, tab.Title()),
+			Detail: manager.tabDetail(index, tab),
+			Run: func() tea.Cmd {
+				manager.tabBar.SwitchToTab(index)
+				return nil
+			},
+		}, score})
+		if index > 0 {
+			// Asks first if the session is still connected, as Ctrl+W does
+			screen := tab.Screen
+			ranked[len(ranked)-1].item.Close = func() tea.Cmd {
+				return manager.closeTab(manager.tabIndex(screen))
+			}
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	items := make([]popups.PaletteItem, 0, len(ranked))
+	for _, entry := range ranked {
+		items = append(items, entry.item)
+	}
+	return items
+}
+
+// tabDetail sums up the state of a tab for the tab list
+func (manager *manager) tabDetail(index int, tab types.Tab) string {
+	var details []string
+	if index == manager.tabBar.GetActiveIndex() {
+		details = append(details, "current")
+	}
+	if holder, ok := tab.Screen.(types.SessionHolder); ok {
+		if holder.IsConnected() {
+			details = append(details, "connected")
+		} else {
+			details = append(details, "not connected")
+		}
+	}
+	if recorder, ok := tab.Screen.(types.SessionRecorder); ok && recorder.IsRecording() {
+		details = append(details, "recording")
+	}
+	if syncer, ok := tab.Screen.(types.InputSyncer); ok && syncer.IsSyncingInput() {
+		details = append(details, "synchronized")
+	}
+	if reporter, ok := tab.Screen.(types.ActivityReporter); ok {
+		switch reporter.Activity() {
+		case types.ActivityOutput:
+			details = append(details, "new output")
+		case types.ActivityBell:
+			details = append(details, "bell")
+		case types.ActivityExited:
+			details = append(details, "session ended")
+		}
+	}
+	if index <= 9 {
+		details = append(details, fmt.Sprintf("alt+%d", index))
+	}
+	return strings.Join(details, ", ")
+}
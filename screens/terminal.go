@@ -1,41 +1,82 @@
 package screens
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 	"yoru/models"
+	"yoru/recording"
+	"yoru/repository"
+	"yoru/screens/components"
 	"yoru/screens/popups"
+	"yoru/screens/styles"
 	"yoru/shared"
 	"yoru/ssh"
 	"yoru/terminal"
 	"yoru/types"
+	"yoru/utils/hostgroup"
+	"yoru/utils/notify"
+	"yoru/utils/storage"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// defaultPasteConfirmLines is used until the user changes the paste prompt preference
+	defaultPasteConfirmLines = 5
+
+	// defaultTranscriptMaxSizeMB is used until the user changes the transcript rotation preference
+	defaultTranscriptMaxSizeMB = 10
+
+	// notifyInterval keeps a chatty session from sending a stream of
+	// notifications; the end of a session is always notified
+	notifyInterval = 10 * time.Second
 )
 
 // NewTerminalScreen creates a new terminal screen for a host
 func NewTerminalScreen(host *models.Host) *terminalScreen {
-	// Calculate terminal dimensions (screen - tab bar)
+	// Calculate terminal dimensions (screen - tab bar); a split resizes
+	// it to its pane
 	width := shared.GlobalState.ScreenWidth
-	height := shared.GlobalState.ScreenHeight - 4 // Subtract tab bar height
+	height := shared.GlobalState.ScreenHeight - 1
 
 	return &terminalScreen{
+		sessionID:       ssh.NewSessionID(),
 		hostID:          host.ID,
 		host:            host,
 		emulator:        terminal.NewEmulator(width, height),
 		connectionPopup: popups.NewConnectionPopup(),
+		pastePopup:      popups.NewPasteConfirmPopup(),
+		saveHostPopup:   popups.NewSaveHostPopup(),
 		connecting:      true,
 		keyCaptureMode:  types.KeyCaptureNormal,
 	}
 }
 
+// NewQuickConnectScreen creates a terminal screen for a host that is not
+// saved; the user is offered to save it once the session ends
+func NewQuickConnectScreen(host *models.Host) *terminalScreen {
+	screen := NewTerminalScreen(host)
+	screen.quickConnect = true
+	return screen
+}
+
 func (screen *terminalScreen) Init() tea.Cmd {
 	// Show connection popup and start SSH connection
 	screen.connectionPopup.Show(
 		screen.hostID,
 		func() {
 			screen.connecting = true
-			ssh.RetryConnection(screen.hostID)
+			// A new session, so messages still on their way from the
+			// failed attempt are not taken for this one
+			ssh.CloseConnection(screen.sessionID)
+			screen.sessionID = ssh.NewSessionID()
+			ssh.RetryConnection(screen.sessionID, screen.host)
 		},
 		func() {
 			screen.connectionPopup.Hide()
@@ -43,7 +84,7 @@ func (screen *terminalScreen) Init() tea.Cmd {
 		},
 	)
 
-	return ssh.InitiateConnection(screen.host)
+	return ssh.InitiateConnection(screen.sessionID, screen.host)
 }
 
 func (screen *terminalScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
@@ -51,37 +92,38 @@ func (screen *terminalScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 	// and must not be blocked by the popup early return
 	switch message := msg.(type) {
 	case types.SSHConnectingMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connectionPopup.AppendLog(message.Message)
 		}
 		return screen, nil
 
 	case types.SSHAuthenticatingMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connectionPopup.AppendLog(message.Message)
 		}
 		return screen, nil
 
 	case types.SSHHostKeyMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connectionPopup.ShowHostKeyVerification(
 				message.Hostname,
 				message.Port,
 				message.KeyType,
 				message.Fingerprint,
 				message.ServerKey,
+				message.Changed,
 				func() { // onAccept — add to known hosts and continue
-					ssh.ContinueAfterHostKeyVerification(screen.hostID, true)
+					ssh.ContinueAfterHostKeyVerification(screen.sessionID, true)
 				},
 				func() { // onReject — continue without saving
-					ssh.ContinueAfterHostKeyVerification(screen.hostID, false)
+					ssh.ContinueAfterHostKeyVerification(screen.sessionID, false)
 				},
 			)
 		}
 		return screen, nil
 
 	case types.SSHConnectedMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connecting = false
 			screen.connected = true
 			if connLog, ok := message.ConnectionLog.(*models.ConnectionLog); ok {
@@ -89,49 +131,106 @@ func (screen *terminalScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 			}
 			screen.connectionPopup.Hide()
 			screen.keyCaptureMode = types.KeyCaptureTerminal
-			// Resize: full width, height minus tab bar
-			screen.emulator.Resize(shared.GlobalState.ScreenWidth, shared.GlobalState.ScreenHeight-1)
-			ssh.ResizeTerminal(screen.hostID, shared.GlobalState.ScreenWidth, shared.GlobalState.ScreenHeight-1)
+			screen.matcher = notify.NewMatcher(notify.ParsePatterns(screen.host.NotifyPatterns))
+			// The session started at 80x24; give it the size of the area it is shown in
+			width, height := screen.emulator.Size()
+			ssh.ResizeTerminal(screen.sessionID, width, height)
+			if screen.host.AlwaysRecord {
+				screen.startRecording()
+			}
+			if screen.host.Transcript {
+				screen.startTranscript()
+			}
 		}
 		return screen, nil
 
 	case types.SSHOutputMsg:
-		if message.HostID == screen.hostID && screen.connected {
+		if message.SessionID == screen.sessionID && screen.connected {
 			screen.emulator.Write(message.Data)
+			bell := screen.emulator.TakeBell()
+			if !screen.isShown() {
+				screen.raiseActivity(types.ActivityOutput)
+				if bell {
+					screen.raiseActivity(types.ActivityBell)
+				}
+			}
+			notification := screen.notifyOutput(message.Data, bell)
+			if screen.recorder != nil {
+				screen.recorder.WriteOutput(message.Data)
+			}
+			if screen.transcript != nil {
+				if err := screen.transcript.WriteOutput(message.Data); err != nil {
+					// A part that cannot be written ends the transcript
+					screen.stopTranscript()
+				}
+			}
+			return screen, tea.Batch(notification, screen.syncMouseMotion())
 		}
 		return screen, nil
 
 	case types.SSHErrorMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connecting = false
 			screen.connectionPopup.ShowError(message.Error)
 		}
 		return screen, nil
 
 	case types.SSHDisconnectedMsg:
-		if message.HostID == screen.hostID {
+		if message.SessionID == screen.sessionID {
 			screen.connected = false
-			ssh.CloseConnection(screen.hostID)
-			return screen, func() tea.Msg { return types.CloseTabMsg{} }
+			screen.syncInput = false
+			screen.stopRecording()
+			screen.stopTranscript()
+			ssh.CloseConnection(screen.sessionID)
+			closeTab := func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
+			var notification tea.Cmd
+			if !screen.isShown() {
+				screen.raiseActivity(types.ActivityExited)
+				if screen.host.NotifyExit {
+					screen.lastNotified = time.Time{}
+					notification = screen.notify("Session ended")
+				}
+			}
+			if screen.quickConnect {
+				// The tab closes once the user has answered
+				screen.keyCaptureMode = types.KeyCaptureNormal
+				screen.showSaveHost(func() { screen.shouldClose = true })
+				closeTab = nil
+			} else if !screen.isShown() {
+				// Keep the tab, and the last of the session, until it is seen
+				screen.keyCaptureMode = types.KeyCaptureNormal
+				screen.ended = true
+				closeTab = nil
+			}
+			if screen.mouseAllMotion {
+				screen.mouseAllMotion = false
+				return screen, tea.Batch(notification, tea.EnableMouseCellMotion, closeTab)
+			}
+			return screen, tea.Batch(notification, closeTab)
 		}
 		return screen, nil
 
 	case tea.WindowSizeMsg:
-		width := message.Width
-		height := message.Height - 1 // tab bar
-		screen.emulator.Resize(width, height)
-		if screen.connected {
-			ssh.ResizeTerminal(screen.hostID, width, height)
-		}
+		screen.resize(message.Width, message.Height-1) // tab bar
 		return screen, nil
 
 	case tea.MouseMsg:
-		if !screen.connectionPopup.IsVisible() && screen.connected {
-			switch message.Type {
-			case tea.MouseWheelUp:
+		if !screen.connectionPopup.IsVisible() && !screen.pastePopup.IsVisible() && !screen.saveHostPopup.IsVisible() && screen.connected {
+			// Forward mouse events when the remote asked for them; Shift+wheel
+			// still scrolls locally like in xterm
+			reporting := screen.emulator.MouseReporting() && screen.keyCaptureMode == types.KeyCaptureTerminal
+			if reporting && !(message.Shift && tea.MouseEvent(message).IsWheel()) {
+				if data := screen.emulator.EncodeMouse(message); len(data) > 0 {
+					ssh.SendInput(screen.sessionID, data)
+				}
+				return screen, nil
+			}
+
+			switch message.Button {
+			case tea.MouseButtonWheelUp:
 				screen.emulator.WheelUp()
 				return screen, nil
-			case tea.MouseWheelDown:
+			case tea.MouseButtonWheelDown:
 				if screen.emulator.IsScrolled() {
 					screen.emulator.WheelDown()
 				}
@@ -145,8 +244,24 @@ func (screen *terminalScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 			screen.connectionPopup.Update(msg)
 			if screen.shouldClose {
 				screen.shouldClose = false
-				ssh.CloseConnection(screen.hostID)
-				return screen, func() tea.Msg { return types.CloseTabMsg{} }
+				screen.stopRecording()
+				screen.stopTranscript()
+				ssh.CloseConnection(screen.sessionID)
+				return screen, func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
+			}
+			return screen, nil
+		}
+
+		if screen.pastePopup.IsVisible() {
+			screen.pastePopup.Update(msg)
+			return screen, nil
+		}
+
+		if screen.saveHostPopup.IsVisible() {
+			screen.saveHostPopup.Update(msg)
+			if screen.shouldClose {
+				screen.shouldClose = false
+				return screen, func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
 			}
 			return screen, nil
 		}
@@ -161,10 +276,12 @@ func (screen *terminalScreen) Update(msg tea.Msg) (types.Screen, tea.Cmd) {
 		}
 
 		if screen.keyCaptureMode == types.KeyCaptureTerminal && screen.connected {
-			data := keyToBytes(message)
-			if len(data) > 0 {
-				ssh.SendInput(screen.hostID, data)
+			if message.Paste {
+				screen.paste(string(message.Runes))
+				return screen, nil
 			}
+
+			screen.sendKey(message)
 			return screen, nil
 		}
 
@@ -183,14 +300,32 @@ func (screen *terminalScreen) View() string {
 		return screen.connectionPopup.Render()
 	}
 
+	if screen.pastePopup.IsVisible() {
+		return screen.pastePopup.Render()
+	}
+
+	if screen.saveHostPopup.IsVisible() {
+		return screen.saveHostPopup.Render()
+	}
+
+	// Being shown is what clears the marks of the tab
+	screen.activity = types.ActivityNone
+
 	// Show terminal if connected
 	if screen.connected {
 		return screen.emulator.Render()
 	}
 
+	if screen.ended {
+		width, _ := screen.emulator.Size()
+		lines := strings.Split(screen.emulator.Render(), "\n")
+		notice := ansi.Truncate(" Session ended  |  Enter: Close tab", width, "…")
+		lines[len(lines)-1] = styles.TerminalEnded.Width(width).Render(notice)
+		return strings.Join(lines, "\n")
+	}
+
 	// Show connecting message
-	width := shared.GlobalState.ScreenWidth
-	height := shared.GlobalState.ScreenHeight - 4
+	width, height := screen.emulator.Size()
 	message := fmt.Sprintf("Connecting to %s...", screen.host.Name)
 	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, message)
 }
@@ -198,111 +333,380 @@ func (screen *terminalScreen) View() string {
 func (screen *terminalScreen) OnKeyPress(key tea.KeyMsg) tea.Cmd {
 	// Note: Terminal automatically enters capture mode when connected
 	// Shift+Esc releases capture mode (handled in manager)
+	switch key.String() {
+	case "enter", "q", "esc":
+		if screen.ended {
+			return func() tea.Msg { return types.CloseTabMsg{Screen: screen} }
+		}
+	case "r":
+		if screen.connected {
+			screen.toggleRecording()
+		}
+	case "s":
+		screen.ShowSaveHost()
+	case "b":
+		screen.ToggleSyncInput()
+	case "|":
+		return func() tea.Msg { return splitRequestMsg{vertical: true} }
+	case "-":
+		return func() tea.Msg { return splitRequestMsg{vertical: false} }
+	}
 	return nil
 }
 
-// GetKeyCaptureMode returns the current key capture mode
-func (screen *terminalScreen) GetKeyCaptureMode() types.KeyCaptureMode {
-	return screen.keyCaptureMode
+// resize fits the session to the area it is shown in
+func (screen *terminalScreen) resize(width, height int) {
+	if currentWidth, currentHeight := screen.emulator.Size(); currentWidth == width && currentHeight == height {
+		return
+	}
+
+	screen.emulator.Resize(width, height)
+	if screen.connected {
+		ssh.ResizeTerminal(screen.sessionID, width, height)
+	}
+	if screen.recorder != nil {
+		screen.recorder.Resize(width, height)
+	}
 }
 
-// keyToBytes converts a key message to bytes for SSH input
-func keyToBytes(key tea.KeyMsg) []byte {
-	switch key.Type {
-	case tea.KeyEnter:
-		return []byte{'\r'}
-	case tea.KeyBackspace:
-		return []byte{0x7f}
-	case tea.KeyTab:
-		return []byte{'\t'}
-	case tea.KeyEscape:
-		return []byte{0x1b}
-	case tea.KeyUp:
-		return []byte{0x1b, '[', 'A'}
-	case tea.KeyDown:
-		return []byte{0x1b, '[', 'B'}
-	case tea.KeyRight:
-		return []byte{0x1b, '[', 'C'}
-	case tea.KeyLeft:
-		return []byte{0x1b, '[', 'D'}
-	case tea.KeyHome:
-		return []byte{0x1b, '[', 'H'}
-	case tea.KeyEnd:
-		return []byte{0x1b, '[', 'F'}
-	case tea.KeyPgUp:
-		return []byte{0x1b, '[', '5', '~'}
-	case tea.KeyPgDown:
-		return []byte{0x1b, '[', '6', '~'}
-	case tea.KeyDelete:
-		return []byte{0x1b, '[', '3', '~'}
-	case tea.KeyInsert:
-		return []byte{0x1b, '[', '2', '~'}
-	case tea.KeyCtrlA:
-		return []byte{0x01}
-	case tea.KeyCtrlB:
-		return []byte{0x02}
-	case tea.KeyCtrlC:
-		return []byte{0x03}
-	case tea.KeyCtrlD:
-		return []byte{0x04}
-	case tea.KeyCtrlE:
-		return []byte{0x05}
-	case tea.KeyCtrlF:
-		return []byte{0x06}
-	case tea.KeyCtrlG:
-		return []byte{0x07}
-	case tea.KeyCtrlH:
-		return []byte{0x08}
-	case tea.KeyCtrlJ:
-		return []byte{0x0a}
-	case tea.KeyCtrlK:
-		return []byte{0x0b}
-	case tea.KeyCtrlL:
-		return []byte{0x0c}
-	case tea.KeyCtrlN:
-		return []byte{0x0e}
-	case tea.KeyCtrlO:
-		return []byte{0x0f}
-	case tea.KeyCtrlP:
-		return []byte{0x10}
-	case tea.KeyCtrlR:
-		return []byte{0x12}
-	case tea.KeyCtrlS:
-		return []byte{0x13}
-	case tea.KeyCtrlT:
-		return []byte{0x14}
-	case tea.KeyCtrlU:
-		return []byte{0x15}
-	case tea.KeyCtrlV:
-		return []byte{0x16}
-	case tea.KeyCtrlW:
-		return []byte{0x17}
-	case tea.KeyCtrlX:
-		return []byte{0x18}
-	case tea.KeyCtrlY:
-		return []byte{0x19}
-	case tea.KeyCtrlZ:
-		return []byte{0x1a}
-	case tea.KeyCtrlBackslash:
-		return []byte{0x1c}
-	case tea.KeyCtrlUnderscore:
-		return []byte{0x1f}
-	case tea.KeyRunes:
-		return []byte(string(key.Runes))
-	default:
-		str := key.String()
-		if len(str) >= 5 && str[:4] == "ctrl" {
-			ch := str[5:]
-			if len(ch) == 1 {
-				c := ch[0]
-				if c >= 'a' && c <= 'z' {
-					return []byte{byte(c - 'a' + 1)}
-				}
+// hasPopup reports whether the screen is asking the user something, which
+// takes the whole tab to show
+func (screen *terminalScreen) hasPopup() bool {
+	return screen.connectionPopup.IsVisible() || screen.pastePopup.IsVisible() || screen.saveHostPopup.IsVisible()
+}
+
+// Title returns the title the session set for its tab, unless the host
+// keeps its name as the title
+func (screen *terminalScreen) Title() string {
+	if screen.host.StaticTitle {
+		return ""
+	}
+	return screen.emulator.Title()
+}
+
+// Activity reports what happened in the session since it was last shown
+func (screen *terminalScreen) Activity() types.Activity {
+	return screen.activity
+}
+
+func (screen *terminalScreen) raiseActivity(activity types.Activity) {
+	screen.activity = max(screen.activity, activity)
+}
+
+// notifyOutput tells the user about output of a session in the background
+// that the host's rules ask for
+func (screen *terminalScreen) notifyOutput(data []byte, bell bool) tea.Cmd {
+	var found []string
+	// Fed even while shown, to follow output split across reads
+	if screen.matcher != nil {
+		found = screen.matcher.Feed(ansi.Strip(string(data)))
+	}
+	if screen.isShown() {
+		return nil
+	}
+
+	switch {
+	case len(found) > 0:
+		return screen.notify(fmt.Sprintf("Printed %q", found[0]))
+	case bell && screen.host.NotifyBell:
+		return screen.notify("Rang the bell")
+	}
+	return nil
+}
+
+// notify returns the command sending a notification about the session the
+// way the preferences say, unless one was sent moments ago. It runs apart
+// from the update so an OSC sequence is not written while a frame is.
+func (screen *terminalScreen) notify(message string) tea.Cmd {
+	if time.Since(screen.lastNotified) < notifyInterval {
+		return nil
+	}
+	screen.lastNotified = time.Now()
+
+	method, err := notify.ParseMethod(repository.GetPreference(types.PrefNotifyMethod, string(notify.DefaultMethod)))
+	if err != nil {
+		method = notify.DefaultMethod
+	}
+	command := repository.GetPreference(types.PrefNotifyCommand, "")
+	notification := notify.Notification{
+		Title:   screen.host.Name,
+		Message: message,
+		Host:    screen.host.Hostname,
+	}
+	return func() tea.Msg {
+		// There is nowhere to report a failure that would not get in the way
+		_ = notify.Send(method, command, notification)
+		return nil
+	}
+}
+
+// isShown reports whether the screen is in the current tab, on its own or
+// as a pane
+func (screen *terminalScreen) isShown() bool {
+	current := components.TabBar.GetCurrentScreen()
+	if split, ok := current.(*splitScreen); ok {
+		for _, pane := range split.panes() {
+			if pane == screen {
+				return true
 			}
 		}
-		if len(str) > 0 {
-			return []byte(str)
+	}
+	return current == screen
+}
+
+// IsConnected reports whether the session is live
+func (screen *terminalScreen) IsConnected() bool {
+	return screen.connected
+}
+
+// CloseSession ends the session once its tab has been closed
+func (screen *terminalScreen) CloseSession() tea.Cmd {
+	screen.connected = false
+	screen.syncInput = false
+	screen.stopRecording()
+	screen.stopTranscript()
+	ssh.CloseConnection(screen.sessionID)
+
+	if screen.mouseAllMotion {
+		screen.mouseAllMotion = false
+		return tea.EnableMouseCellMotion
+	}
+	return nil
+}
+
+// ToggleSyncInput adds the tab to the tabs whose input is synchronized, or
+// takes it out
+func (screen *terminalScreen) ToggleSyncInput() {
+	screen.syncInput = !screen.syncInput
+}
+
+// IsSyncingInput reports whether keys typed in the tab go to the other
+// synchronized tabs too
+func (screen *terminalScreen) IsSyncingInput() bool {
+	return screen.syncInput
+}
+
+// syncPeers returns the other connected tabs to mirror input to
+func (screen *terminalScreen) syncPeers() []*terminalScreen {
+	if !screen.syncInput {
+		return nil
+	}
+
+	var peers []*terminalScreen
+	for _, peer := range terminalScreens() {
+		if peer != screen && peer.syncInput && peer.connected {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// sendKey types a key in the session and the synchronized ones, each
+// encoding it for the modes its own remote application has set
+func (screen *terminalScreen) sendKey(key tea.KeyMsg) {
+	if data := screen.emulator.EncodeKey(key); len(data) > 0 {
+		ssh.SendInput(screen.sessionID, data)
+	}
+	for _, peer := range screen.syncPeers() {
+		if data := peer.emulator.EncodeKey(key); len(data) > 0 {
+			ssh.SendInput(peer.sessionID, data)
+		}
+	}
+}
+
+// ShowSaveHost offers to save a quick connect session's target as a host
+func (screen *terminalScreen) ShowSaveHost() {
+	if screen.quickConnect {
+		screen.showSaveHost(func() {})
+	}
+}
+
+// CanSaveHost reports whether the session's target is not saved yet
+func (screen *terminalScreen) CanSaveHost() bool {
+	return screen.quickConnect
+}
+
+func (screen *terminalScreen) showSaveHost(onClose func()) {
+	var credentialName, username string
+	switch screen.host.CredentialType {
+	case types.CredentialIdentity:
+		if identity, err := repository.GetIdentityByID(screen.host.CredentialID); err == nil {
+			credentialName, username = identity.Name, identity.Username
+		}
+	case types.CredentialKey:
+		if key, err := repository.GetKeyByID(screen.host.CredentialID); err == nil {
+			credentialName, username = key.Name, key.Username
 		}
 	}
+
+	// The user of the target is kept over the credential's
+	if screen.host.User != "" {
+		username = screen.host.User
+	}
+	login := fmt.Sprintf("Logs in with %s as %s.", credentialName, username)
+
+	screen.saveHostPopup.Show(screen.host.Name, login, screen.saveHost, onClose)
+}
+
+func (screen *terminalScreen) saveHost(name, group string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	host := &models.Host{
+		Name:           name,
+		Hostname:       screen.host.Hostname,
+		Mode:           screen.host.Mode,
+		Port:           screen.host.Port,
+		CredentialID:   screen.host.CredentialID,
+		CredentialType: screen.host.CredentialType,
+		User:           screen.host.User,
+		Group:          hostgroup.NormalizePath(group),
+	}
+	if err := repository.CreateHost(host); err != nil {
+		return err
+	}
+
+	screen.quickConnect = false
+	screen.savedHostID = host.ID
+	hostsScreen.loadHosts()
 	return nil
-}
\ No newline at end of file
+}
+
+// toggleRecording starts recording the session, or pauses and resumes the
+// recording already in progress
+func (screen *terminalScreen) toggleRecording() {
+	switch {
+	case screen.recorder == nil:
+		screen.startRecording()
+	case screen.recorder.IsPaused():
+		screen.recorder.Resume()
+	default:
+		screen.recorder.Pause()
+	}
+}
+
+func (screen *terminalScreen) startRecording() {
+	if screen.recorder != nil {
+		return
+	}
+
+	recordingsDir, err := storage.GetRecordingsDirectory()
+	if err != nil {
+		return
+	}
+
+	path := filepath.Join(recordingsDir, recording.FileName(screen.host.Name, time.Now()))
+	width, height := screen.emulator.Size()
+	recorder, err := recording.NewRecorder(path, width, height, screen.host.Name+"@"+screen.host.Hostname)
+	if err != nil {
+		return
+	}
+	screen.recorder = recorder
+
+	// Link the recording from the session's connection log
+	if screen.connectionLog != nil {
+		screen.connectionLog.RecordingPath = path
+		repository.UpdateConnectionLog(screen.connectionLog)
+	}
+}
+
+func (screen *terminalScreen) stopRecording() {
+	if screen.recorder != nil {
+		screen.recorder.Close()
+		screen.recorder = nil
+	}
+}
+
+// startTranscript tees the session output, stripped of escape sequences, to
+// a text file in the host's transcript directory
+func (screen *terminalScreen) startTranscript() {
+	if screen.transcript != nil {
+		return
+	}
+
+	transcriptsDir, err := storage.GetTranscriptsDirectory()
+	if err != nil {
+		return
+	}
+
+	var sessionID uint
+	startedAt := time.Now()
+	if screen.connectionLog != nil {
+		sessionID = screen.connectionLog.ID
+		startedAt = screen.connectionLog.StartedAt
+	}
+
+	template := repository.GetPreference(types.PrefTranscriptTemplate, recording.DefaultTranscriptTemplate)
+	fileName := recording.TranscriptFileName(template, screen.host.Name, sessionID, startedAt)
+	path := filepath.Join(transcriptsDir, recording.SafeName(screen.host.Name), fileName)
+
+	maxSize := int64(repository.GetIntPreference(types.PrefTranscriptMaxSizeMB, defaultTranscriptMaxSizeMB)) * 1024 * 1024
+	transcript, err := recording.NewTranscript(path, screen.host.Name+"@"+screen.host.Hostname, maxSize)
+	if err != nil {
+		return
+	}
+	screen.transcript = transcript
+}
+
+func (screen *terminalScreen) stopTranscript() {
+	if screen.transcript != nil {
+		screen.transcript.Close()
+		screen.transcript = nil
+	}
+}
+
+// IsRecording reports whether the session output is currently being recorded
+func (screen *terminalScreen) IsRecording() bool {
+	return screen.recorder != nil && !screen.recorder.IsPaused()
+}
+
+// paste sends pasted text to the remote, asking first when it spans more
+// lines than the user's confirmation threshold
+func (screen *terminalScreen) paste(text string) {
+	send := func() {
+		ssh.SendInput(screen.sessionID, screen.emulator.EncodePaste(text))
+		for _, peer := range screen.syncPeers() {
+			ssh.SendInput(peer.sessionID, peer.emulator.EncodePaste(text))
+		}
+	}
+
+	threshold := repository.GetIntPreference(types.PrefPasteConfirmLines, defaultPasteConfirmLines)
+	lines := strings.Count(strings.ReplaceAll(text, "\r\n", "\n"), "\n") + 1
+	if threshold <= 0 || lines <= threshold {
+		send()
+		return
+	}
+
+	screen.pastePopup.Show(
+		text,
+		func(dontAskAgain bool) {
+			if dontAskAgain {
+				repository.SetPreference(types.PrefPasteConfirmLines, strconv.Itoa(0))
+			}
+			send()
+		},
+		func() {},
+	)
+}
+
+// syncMouseMotion switches the host terminal to all-motion tracking while the
+// remote application wants motion events without a button held
+func (screen *terminalScreen) syncMouseMotion() tea.Cmd {
+	allMotion := screen.emulator.MouseAllMotion()
+	if allMotion == screen.mouseAllMotion {
+		return nil
+	}
+
+	screen.mouseAllMotion = allMotion
+	if allMotion {
+		return tea.EnableMouseAllMotion
+	}
+	return tea.EnableMouseCellMotion
+}
+
+// GetKeyCaptureMode returns the current key capture mode
+func (screen *terminalScreen) GetKeyCaptureMode() types.KeyCaptureMode {
+	return screen.keyCaptureMode
+}
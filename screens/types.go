@@ -1,17 +1,28 @@
 package screens
 
 import (
+	"time"
 	"yoru/models"
+	"yoru/recording"
 	"yoru/screens/components"
 	"yoru/screens/forms"
 	"yoru/screens/popups"
+	"yoru/ssh"
 	"yoru/terminal"
 	"yoru/types"
+	"yoru/utils/notify"
 )
 
 type manager struct {
 	types.ScreenManager
-	tabBar types.TabBar
+	tabBar       types.TabBar
+	palette      *popups.CommandPalette
+	namePopup    *popups.NamePopup
+	confirmPopup *popups.ConfirmPopup
+	startTabs    []types.Tab
+
+	startWorkspace *models.Workspace
+	restorePending bool // the last session is offered but not yet answered
 }
 
 type home struct {
@@ -27,6 +38,32 @@ type hosts struct {
 	filterWasActive      bool
 	deletePopup          *popups.DeleteHostPopup
 	identityChooserPopup *popups.IdentityChooserPopup
+	importPopup          *popups.ImportHostsPopup
+	installKeyPopup      *popups.InstallKeyPopup
+	execPopup            *popups.ExecPopup
+	dragging             bool
+	dragRow              int
+}
+
+type splitScreen struct {
+	types.Screen
+	root    *paneNode
+	focused *paneNode
+	zoomed  bool
+}
+
+type execScreen struct {
+	types.Screen
+	hosts       []models.Host
+	command     string
+	results     []*ssh.ExecResult // nil until the host has finished
+	expanded    map[int]bool
+	selectedIdx int
+	scroll      int
+	run         int
+	finished    int
+	startedAt   time.Time
+	elapsed     time.Duration
 }
 
 type logs struct {
@@ -37,15 +74,43 @@ type logs struct {
 
 type terminalScreen struct {
 	types.Screen
+	sessionID       uint // tells this terminal's SSH messages from those of others on the host
 	hostID          uint
 	host            *models.Host
 	emulator        *terminal.Emulator
 	connectionPopup *popups.ConnectionPopup
+	pastePopup      *popups.PasteConfirmPopup
+	saveHostPopup   *popups.SaveHostPopup
+	quickConnect    bool
+	savedHostID     uint // the host a quick connect session was saved as
+	syncInput       bool
 	connecting      bool
 	connected       bool
+	ended           bool // disconnected in the background, kept open to be seen
+	activity        types.Activity
+	matcher         *notify.Matcher
+	lastNotified    time.Time
 	connectionLog   *models.ConnectionLog
 	keyCaptureMode  types.KeyCaptureMode
 	shouldClose     bool
+	mouseAllMotion  bool
+	recorder        *recording.Recorder
+	transcript      *recording.Transcript
+}
+
+type playerScreen struct {
+	types.Screen
+	path       string
+	cast       *recording.Cast
+	err        error
+	emulator   *terminal.Emulator
+	duration   time.Duration
+	position   time.Duration
+	nextEvent  int
+	speed      float64
+	paused     bool
+	lastTick   time.Time
+	generation int
 }
 
 type focusArea int
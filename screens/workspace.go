@@ -0,0 +1,238 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// restorePreviewTabs is how many tab names the restore question lists
+const restorePreviewTabs = 3
+
+// OpenWorkspaceOnStart opens the tabs of workspace as the interface starts,
+// instead of offering to restore the last session
+func (manager *manager) OpenWorkspaceOnStart(workspace *models.Workspace) {
+	manager.startWorkspace = workspace
+}
+
+// captureWorkspace describes the open tabs of saved hosts, the only ones
+// that can be opened again
+func (manager *manager) captureWorkspace(name string) *models.Workspace {
+	workspace := &models.Workspace{Name: name, ActiveTab: -1}
+	for index, tab := range manager.tabBar.GetTabs() {
+		var pane *models.WorkspacePane
+		switch screen := tab.Screen.(type) {
+		case *terminalScreen:
+			pane = workspacePane(&paneNode{screen: screen}, nil)
+		case *splitScreen:
+			pane = workspacePane(screen.root, screen.focused)
+		}
+		if pane == nil {
+			continue
+		}
+		if index == manager.tabBar.GetActiveIndex() {
+			workspace.ActiveTab = len(workspace.Tabs)
+		}
+		workspace.Tabs = append(workspace.Tabs, models.WorkspaceTab{Name: tab.Name, Renamed: tab.Renamed, Pane: pane})
+	}
+	return workspace
+}
+
+// workspacePane describes the layout of a pane, leaving out quick connect
+// hosts
+func workspacePane(node, focused *paneNode) *models.WorkspacePane {
+	if node == nil {
+		return nil
+	}
+	if node.screen != nil {
+		if node.screen.quickConnect {
+			return nil
+		}
+		hostID := node.screen.hostID
+		if node.screen.savedHostID != 0 {
+			hostID = node.screen.savedHostID
+		}
+		return &models.WorkspacePane{HostID: hostID, Focused: node == focused}
+	}
+
+	first, second := workspacePane(node.first, focused), workspacePane(node.second, focused)
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return &models.WorkspacePane{Vertical: node.vertical, Ratio: node.ratio, First: first, Second: second}
+}
+
+// openWorkspace opens the tabs of a workspace after those already open,
+// connecting to each host the usual way. Each pane gets a session of its
+// own, so a host may appear in several of them.
+func (manager *manager) openWorkspace(workspace *models.Workspace) tea.Cmd {
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		message := fmt.Sprintf("Could not read the hosts: %v. Try again?", err)
+		manager.confirmPopup.Show("Open Workspace", message, func() tea.Cmd {
+			return manager.openWorkspace(workspace)
+		})
+		return func() tea.Msg { return nil }
+	}
+	hostsByID := make(map[uint]models.Host, len(hosts))
+	for _, host := range hosts {
+		hostsByID[host.ID] = host
+	}
+
+	var commands []tea.Cmd
+	active := -1
+	for index, tab := range workspace.Tabs {
+		var terminals []*terminalScreen
+		root, focused := buildPane(tab.Pane, hostsByID, &terminals)
+		if root == nil {
+			// Every host of the tab has been deleted since
+			continue
+		}
+
+		var screen types.Screen = root.screen
+		if root.screen == nil {
+			if focused == nil {
+				focused = firstLeaf(root)
+			}
+			split := &splitScreen{root: root, focused: focused}
+			split.layout()
+			screen = split
+		}
+
+		name := tab.Name
+		if !tab.Renamed {
+			host := firstLeaf(root).screen.host
+			name = host.Name + "@" + host.Hostname
+		}
+		manager.tabBar.AddTab(types.Tab{Name: name, Screen: screen, Renamed: tab.Renamed})
+		if index == workspace.ActiveTab {
+			active = len(manager.tabBar.GetTabs()) - 1
+		}
+		for _, terminal := range terminals {
+			commands = append(commands, terminal.Init())
+		}
+	}
+
+	if active >= 0 {
+		manager.tabBar.SwitchToTab(active)
+	}
+	return tea.Batch(commands...)
+}
+
+// buildPane creates the terminals of a pane layout, skipping deleted hosts,
+// and returns the pane and the one of its panes that had the focus
+func buildPane(layout *models.WorkspacePane, hosts map[uint]models.Host, terminals *[]*terminalScreen) (*paneNode, *paneNode) {
+	if layout == nil {
+		return nil, nil
+	}
+	if layout.First == nil && layout.Second == nil {
+		host, ok := hosts[layout.HostID]
+		if !ok {
+			return nil, nil
+		}
+		terminal := NewTerminalScreen(&host)
+		*terminals = append(*terminals, terminal)
+		node := &paneNode{screen: terminal}
+		if layout.Focused {
+			return node, node
+		}
+		return node, nil
+	}
+
+	first, firstFocused := buildPane(layout.First, hosts, terminals)
+	second, secondFocused := buildPane(layout.Second, hosts, terminals)
+	focused := firstFocused
+	if focused == nil {
+		focused = secondFocused
+	}
+	if first == nil {
+		return second, focused
+	}
+	if second == nil {
+		return first, focused
+	}
+
+	node := &paneNode{
+		vertical: layout.Vertical,
+		ratio:    clampRatio(layout.Ratio),
+		first:    first,
+		second:   second,
+	}
+	first.parent, second.parent = node, node
+	return node, focused
+}
+
+func clampRatio(ratio float64) float64 {
+	if ratio == 0 {
+		return 0.5
+	}
+	return max(splitMinRatio, min(ratio, splitMaxRatio))
+}
+
+// offerRestore asks whether to reopen the tabs open when the interface last
+// quit, if there were any
+func (manager *manager) offerRestore() {
+	workspace, err := repository.GetWorkspace("")
+	if err != nil || workspace == nil || len(workspace.Tabs) == 0 {
+		return
+	}
+
+	names := make([]string, 0, restorePreviewTabs)
+	for i, tab := range workspace.Tabs {
+		if i == restorePreviewTabs {
+			names = append(names, fmt.Sprintf("and %d more", len(workspace.Tabs)-restorePreviewTabs))
+			break
+		}
+		names = append(names, tab.Name)
+	}
+	message := fmt.Sprintf("Reopen the %d tabs open when you last quit? %s", len(workspace.Tabs), strings.Join(names, ", "))
+	if len(workspace.Tabs) == 1 {
+		message = fmt.Sprintf("Reopen %s, open when you last quit?", workspace.Tabs[0].Name)
+	}
+
+	manager.restorePending = true
+	manager.confirmPopup.Show("Restore Session", message, func() tea.Cmd {
+		return manager.openWorkspace(workspace)
+	})
+}
+
+// saveLastSession keeps the open tabs to offer them on the next start. It
+// leaves the previous ones alone if they were neither reopened nor
+// declined.
+func (manager *manager) saveLastSession() error {
+	if manager.restorePending && manager.confirmPopup.IsVisible() {
+		return nil
+	}
+	return repository.SaveWorkspace(manager.captureWorkspace(""))
+}
+
+// showSaveWorkspace asks for a name to save the open tabs under
+func (manager *manager) showSaveWorkspace() {
+	manager.namePopup.Show("Save Workspace", "", func(name string) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("name is required")
+		}
+		workspace := manager.captureWorkspace(name)
+		if len(workspace.Tabs) == 0 {
+			return errors.New("no tabs of saved hosts are open")
+		}
+		return repository.SaveWorkspace(workspace)
+	})
+}
+
+// workspaceDetail counts the tabs of a workspace for the palette
+func workspaceDetail(workspace *models.Workspace) string {
+	if len(workspace.Tabs) == 1 {
+		return "1 tab"
+	}
+	return fmt.Sprintf("%d tabs", len(workspace.Tabs))
+}
@@ -7,15 +7,19 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 	"yoru/models"
 	"yoru/repository"
 	"yoru/types"
+	"yoru/utils/network"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
-// LoadCredential loads the credential for a host from the database
+// LoadCredential loads the credential for a host from the database, with
+// the host's user in place of the credential's username when it has one
 func LoadCredential(host *models.Host) (any, error) {
 	if host.CredentialID == 0 {
 		return nil, errors.New("no credential configured for this host")
@@ -23,9 +27,17 @@ func LoadCredential(host *models.Host) (any, error) {
 
 	switch host.CredentialType {
 	case types.CredentialIdentity:
-		return repository.GetIdentityByID(host.CredentialID)
+		identity, err := repository.GetIdentityByID(host.CredentialID)
+		if err == nil && host.User != "" {
+			identity.Username = host.User
+		}
+		return identity, err
 	case types.CredentialKey:
-		return repository.GetKeyByID(host.CredentialID)
+		key, err := repository.GetKeyByID(host.CredentialID)
+		if err == nil && host.User != "" {
+			key.Username = host.User
+		}
+		return key, err
 	default:
 		return nil, errors.New("unknown credential type")
 	}
@@ -62,16 +74,20 @@ func BuildSSHConfig(credential any) (*ssh.ClientConfig, error) {
 			ssh.PublicKeys(signer),
 		}
 
-		// If certificate is present, add it
+		// A certificate is offered before the plain key, so servers that
+		// trust the CA accept it first; one that cannot be used is an error
+		// rather than silently falling back to the key alone
 		if cred.Certificate != "" {
-			pcert, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cred.Certificate))
-			if err == nil {
-				if cert, ok := pcert.(*ssh.Certificate); ok {
-					certSigner, err := ssh.NewCertSigner(cert, signer)
-					if err == nil {
-						config.Auth = append(config.Auth, ssh.PublicKeys(certSigner))
-					}
-				}
+			cert, err := ParseCertificate(cred.Certificate)
+			if err != nil {
+				return nil, err
+			}
+			certSigner, err := ssh.NewCertSigner(cert, signer)
+			if err != nil {
+				return nil, ErrCertificateKey
+			}
+			config.Auth = []ssh.AuthMethod{
+				ssh.PublicKeys(certSigner, signer),
 			}
 		}
 
@@ -104,35 +120,78 @@ func GetMD5Fingerprint(key ssh.PublicKey) string {
 	return formatted
 }
 
-// VerifyHostKey checks if a host key matches a known host in the database
+// VerifyHostKey checks a server key against the known hosts matching the
+// address. A revoked key is rejected even if it is also trusted elsewhere.
 func VerifyHostKey(hostname string, port int, key ssh.PublicKey) (*models.KnownHost, error) {
 	fingerprint := GetFingerprint(key)
 
-	knownHost, err := repository.GetKnownHostByFingerprint(fingerprint)
+	knownHosts, err := repository.GetAllKnownHosts()
 	if err != nil {
-		// Host not known
 		return nil, err
 	}
 
-	// Verify hostname and port match
-	if knownHost.Hostname != hostname || knownHost.Port != port {
-		return nil, fmt.Errorf("host key fingerprint matches but hostname/port differs")
+	var match *models.KnownHost
+	changed := false
+	for i := range knownHosts {
+		knownHost := &knownHosts[i]
+		if !matchesKnownHost(knownHost, hostname, port) {
+			continue
+		}
+
+		switch knownHost.Marker {
+		case types.KnownHostRevoked:
+			if knownHost.Fingerprint == fingerprint {
+				return nil, ErrHostKeyRevoked
+			}
+		case types.KnownHostPlain:
+			if knownHost.Fingerprint == fingerprint {
+				match = knownHost
+			} else {
+				changed = true
+			}
+		}
 	}
 
-	return knownHost, nil
+	switch {
+	case match != nil:
+		return match, nil
+	case changed:
+		return nil, ErrHostKeyChanged
+	default:
+		return nil, ErrHostKeyUnknown
+	}
 }
 
 // SaveHostKey saves a verified host key to the database
 func SaveHostKey(hostname string, port int, key ssh.PublicKey) error {
-	fingerprint := GetFingerprint(key)
-	keyType := key.Type()
-
 	knownHost := &models.KnownHost{
 		Hostname:    hostname,
 		Port:        port,
-		KeyType:     keyType,
-		Fingerprint: fingerprint,
+		Pattern:     knownhosts.Normalize(network.JoinHostPort(hostname, port)),
+		KeyType:     key.Type(),
+		Fingerprint: GetFingerprint(key),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
 	}
 
 	return repository.CreateKnownHost(knownHost)
-}
\ No newline at end of file
+}
+
+// ReplaceHostKey trusts key in place of the keys trusted so far for the
+// host. Revoked keys, host CAs and wildcard entries, which cover other
+// hosts too, stay as they are; a key saved for the host takes precedence
+// over them.
+func ReplaceHostKey(hostname string, port int, key ssh.PublicKey) error {
+	knownHosts, err := repository.GetAllKnownHosts()
+	if err != nil {
+		return err
+	}
+	for i := range knownHosts {
+		knownHost := &knownHosts[i]
+		if knownHost.Marker == types.KnownHostPlain && knownHost.Hostname == hostname && knownHost.Port == port {
+			if err := repository.DeleteKnownHost(knownHost.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return SaveHostKey(hostname, port, key)
+}
@@ -0,0 +1,124 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+	"yoru/utils/network"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrNotCertificate   = errors.New("host key is not a certificate")
+	ErrNoHostAuthority  = errors.New("certificate is not signed by a trusted host CA")
+	ErrInvalidHostEntry = errors.New("host pattern is required")
+)
+
+// VerifyHostCertificate checks a host certificate against the trusted CAs
+// (@cert-authority entries) matching the address. ssh.CertChecker covers the
+// signature, the validity window and the host name against the principals;
+// the certificate, its key and its CA are checked against @revoked entries.
+// The returned entry is the CA that signed the certificate.
+func VerifyHostCertificate(hostname string, port int, key ssh.PublicKey) (*models.KnownHost, error) {
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, ErrNotCertificate
+	}
+	if cert.CertType != ssh.HostCert {
+		return nil, fmt.Errorf("certificate presented as a host key has type %d", cert.CertType)
+	}
+
+	knownHosts, err := repository.GetAllKnownHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var authorities, revoked []*models.KnownHost
+	for i := range knownHosts {
+		knownHost := &knownHosts[i]
+		if !matchesKnownHost(knownHost, hostname, port) {
+			continue
+		}
+		switch knownHost.Marker {
+		case types.KnownHostCertAuthority:
+			authorities = append(authorities, knownHost)
+		case types.KnownHostRevoked:
+			revoked = append(revoked, knownHost)
+		}
+	}
+
+	isRevoked := func(key ssh.PublicKey) bool {
+		fingerprint := GetFingerprint(key)
+		for _, entry := range revoked {
+			if entry.Fingerprint == fingerprint {
+				return true
+			}
+		}
+		return false
+	}
+	if isRevoked(cert) || isRevoked(cert.Key) || isRevoked(cert.SignatureKey) {
+		return nil, ErrHostKeyRevoked
+	}
+
+	var authority *models.KnownHost
+	checker := &ssh.CertChecker{
+		IsHostAuthority: func(auth ssh.PublicKey, address string) bool {
+			fingerprint := GetFingerprint(auth)
+			for _, entry := range authorities {
+				if entry.Fingerprint == fingerprint {
+					authority = entry
+					return true
+				}
+			}
+			return false
+		},
+		IsRevoked: func(cert *ssh.Certificate) bool {
+			return isRevoked(cert)
+		},
+	}
+
+	// The remote address is only used for error messages
+	if err := checker.CheckHostKey(network.JoinHostPort(hostname, port), &net.TCPAddr{}, cert); err != nil {
+		if authority == nil {
+			return nil, ErrNoHostAuthority
+		}
+		return nil, err
+	}
+	return authority, nil
+}
+
+// TrustHostAuthority stores a host CA key for the hosts matching pattern,
+// like an @cert-authority line in known_hosts
+func TrustHostAuthority(key ssh.PublicKey, pattern, comment string) error {
+	return addMarkedKey(types.KnownHostCertAuthority, key, pattern, comment)
+}
+
+// RevokeKey stores a host or CA key that must never be accepted for the
+// hosts matching pattern, like an @revoked line in known_hosts
+func RevokeKey(key ssh.PublicKey, pattern, comment string) error {
+	return addMarkedKey(types.KnownHostRevoked, key, pattern, comment)
+}
+
+func addMarkedKey(marker types.KnownHostMarker, key ssh.PublicKey, pattern, comment string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.ContainsAny(pattern, " \t") {
+		return ErrInvalidHostEntry
+	}
+	if _, ok := key.(*ssh.Certificate); ok {
+		return fmt.Errorf("expected a plain public key, not a certificate")
+	}
+
+	return repository.CreateKnownHost(&models.KnownHost{
+		Pattern:     pattern,
+		Marker:      marker,
+		KeyType:     key.Type(),
+		Fingerprint: GetFingerprint(key),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		Comment:     comment,
+	})
+}
@@ -0,0 +1,135 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// newHostCertificate signs a certificate of certType for principals, valid
+// from validAfter to validBefore
+func newHostCertificate(t *testing.T, authority ssh.Signer, certType uint32, principals []string, validAfter, validBefore time.Time) *ssh.Certificate {
+	t.Helper()
+	cert := &ssh.Certificate{
+		Key:             newTestHostKey(t),
+		CertType:        certType,
+		KeyId:           "test",
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, authority); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestVerifyHostCertificate(t *testing.T) {
+	clearKnownHosts(t)
+
+	authority := newTestSigner(t)
+	stranger := newTestSigner(t)
+	if err := TrustHostAuthority(authority.PublicKey(), "*.example.com", "prod CA"); err != nil {
+		t.Fatal(err)
+	}
+	// Trusted only for another domain
+	if err := TrustHostAuthority(stranger.PublicKey(), "*.example.org", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	valid := func(principals ...string) *ssh.Certificate {
+		return newHostCertificate(t, authority, ssh.HostCert, principals, now.Add(-time.Hour), now.Add(time.Hour))
+	}
+	revokedCert := valid("revoked.example.com")
+	if err := RevokeKey(revokedCert.Key, "*", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		port     int
+		key      ssh.PublicKey
+		wantErr  error
+		wantAny  bool
+	}{
+		{"trusted", "web.example.com", 22, valid("web.example.com"), nil, false},
+		{"other principal", "db.example.com", 22, valid("web.example.com"), nil, true},
+		{"other port", "web.example.com", 2222, valid("web.example.com"), ErrNoHostAuthority, false},
+		{"CA of another domain", "web.example.com", 22, newHostCertificate(t, stranger, ssh.HostCert, []string{"web.example.com"}, now.Add(-time.Hour), now.Add(time.Hour)), ErrNoHostAuthority, false},
+		{"expired", "web.example.com", 22, newHostCertificate(t, authority, ssh.HostCert, []string{"web.example.com"}, now.Add(-2*time.Hour), now.Add(-time.Hour)), nil, true},
+		{"user certificate", "web.example.com", 22, newHostCertificate(t, authority, ssh.UserCert, []string{"web.example.com"}, now.Add(-time.Hour), now.Add(time.Hour)), nil, true},
+		{"revoked key", "revoked.example.com", 22, revokedCert, ErrHostKeyRevoked, false},
+		{"plain key", "web.example.com", 22, newTestHostKey(t), ErrNotCertificate, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, err := VerifyHostCertificate(test.hostname, test.port, test.key)
+			switch {
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("VerifyHostCertificate() = %v, want %v", err, test.wantErr)
+				}
+			case test.wantAny:
+				if err == nil {
+					t.Error("VerifyHostCertificate() = nil, want an error")
+				}
+			case err != nil:
+				t.Errorf("VerifyHostCertificate() = %v", err)
+			case entry.Comment != "prod CA":
+				t.Errorf("signed by %q, want the prod CA", entry.Comment)
+			}
+		})
+	}
+
+	// Revoking the CA revokes everything it signed
+	if err := RevokeKey(authority.PublicKey(), "web.example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyHostCertificate("web.example.com", 22, valid("web.example.com")); !errors.Is(err, ErrHostKeyRevoked) {
+		t.Errorf("VerifyHostCertificate() with a revoked CA = %v, want %v", err, ErrHostKeyRevoked)
+	}
+}
+
+func TestTrustHostAuthority(t *testing.T) {
+	clearKnownHosts(t)
+	authority := newTestSigner(t)
+	cert := newHostCertificate(t, authority, ssh.HostCert, nil, time.Now(), time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		key     ssh.PublicKey
+		pattern string
+		wantErr bool
+	}{
+		{"pattern", authority.PublicKey(), " *.example.com ", false},
+		{"no pattern", authority.PublicKey(), "  ", true},
+		{"pattern with spaces", authority.PublicKey(), "a.example.com b.example.com", true},
+		{"certificate", cert, "*", true},
+	}
+
+	for _, test := range tests {
+		if err := TrustHostAuthority(test.key, test.pattern, ""); (err != nil) != test.wantErr {
+			t.Errorf("%s: TrustHostAuthority() = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrNotUserCertificate = errors.New("not an SSH certificate")
+	ErrCertificateKey     = errors.New("certificate does not match the private key")
+)
+
+// CertificateInfo describes an OpenSSH certificate for display
+type CertificateInfo struct {
+	KeyID           string
+	Serial          uint64
+	CertType        string
+	Principals      []string
+	ValidAfter      time.Time // zero when valid since forever
+	ValidBefore     time.Time // zero when valid forever
+	CriticalOptions []string
+	Extensions      []string
+	CAFingerprint   string
+	Warnings        []string
+}
+
+// ParseCertificate parses a certificate in authorized_keys format
+// (ssh-ed25519-cert-v01@openssh.com AAAA...)
+func ParseCertificate(data string) (*ssh.Certificate, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %w", err)
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, ErrNotUserCertificate
+	}
+	return cert, nil
+}
+
+// InspectCertificate describes a certificate, with warnings when it is not
+// usable for user authentication with signer's key right now. signer may be
+// nil when the private key is encrypted or missing.
+func InspectCertificate(cert *ssh.Certificate, signer ssh.Signer, now time.Time) CertificateInfo {
+	info := CertificateInfo{
+		KeyID:         cert.KeyId,
+		Serial:        cert.Serial,
+		CertType:      "user",
+		Principals:    cert.ValidPrincipals,
+		CAFingerprint: GetFingerprint(cert.SignatureKey),
+	}
+	if cert.CertType == ssh.HostCert {
+		info.CertType = "host"
+		info.Warnings = append(info.Warnings, "This is a host certificate, not a user certificate")
+	}
+
+	if cert.ValidAfter != 0 {
+		info.ValidAfter = time.Unix(int64(min(cert.ValidAfter, uint64(1<<63-1))), 0)
+	}
+	if cert.ValidBefore != ssh.CertTimeInfinity {
+		info.ValidBefore = time.Unix(int64(min(cert.ValidBefore, uint64(1<<63-1))), 0)
+	}
+	switch {
+	case !info.ValidBefore.IsZero() && now.After(info.ValidBefore):
+		info.Warnings = append(info.Warnings, fmt.Sprintf("Expired %s", info.ValidBefore.Local().Format(time.DateTime)))
+	case !info.ValidAfter.IsZero() && now.Before(info.ValidAfter):
+		info.Warnings = append(info.Warnings, fmt.Sprintf("Not valid until %s", info.ValidAfter.Local().Format(time.DateTime)))
+	}
+
+	for name, value := range cert.CriticalOptions {
+		if value != "" {
+			name += "=" + value
+		}
+		info.CriticalOptions = append(info.CriticalOptions, name)
+	}
+	for name := range cert.Extensions {
+		info.Extensions = append(info.Extensions, name)
+	}
+	slices.Sort(info.CriticalOptions)
+	slices.Sort(info.Extensions)
+
+	if signer != nil && !slices.Equal(cert.Key.Marshal(), signer.PublicKey().Marshal()) {
+		info.Warnings = append(info.Warnings, "Certificate does not match the private key")
+	}
+
+	return info
+}
+
+// InspectKeyCertificate parses and describes the certificate of a keychain
+// key, checking it against the private key when that can be parsed
+func InspectKeyCertificate(certificate, privateKey string) (CertificateInfo, error) {
+	cert, err := ParseCertificate(certificate)
+	if err != nil {
+		return CertificateInfo{}, err
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		signer = nil
+	}
+	return InspectCertificate(cert, signer, time.Now()), nil
+}
+
+// DescribeValidity formats the validity window of a certificate
+func (info CertificateInfo) DescribeValidity() string {
+	if info.ValidAfter.IsZero() && info.ValidBefore.IsZero() {
+		return "forever"
+	}
+
+	from := "always"
+	if !info.ValidAfter.IsZero() {
+		from = info.ValidAfter.Local().Format(time.DateTime)
+	}
+	to := "forever"
+	if !info.ValidBefore.IsZero() {
+		to = info.ValidBefore.Local().Format(time.DateTime)
+	}
+	return from + " → " + to
+}
@@ -0,0 +1,100 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseCertificate(t *testing.T) {
+	authority := newTestSigner(t)
+	cert := newHostCertificate(t, authority, ssh.UserCert, []string{"alice"}, time.Now(), time.Now().Add(time.Hour))
+
+	if _, err := ParseCertificate(" " + string(ssh.MarshalAuthorizedKey(cert)) + "\n"); err != nil {
+		t.Errorf("ParseCertificate() = %v", err)
+	}
+	if _, err := ParseCertificate(authorizedKey(authority.PublicKey())); !errors.Is(err, ErrNotUserCertificate) {
+		t.Errorf("ParseCertificate() of a plain key = %v, want %v", err, ErrNotUserCertificate)
+	}
+	if _, err := ParseCertificate("not a key"); err == nil {
+		t.Error("ParseCertificate() of garbage = nil, want an error")
+	}
+}
+
+func TestInspectCertificate(t *testing.T) {
+	authority := newTestSigner(t)
+	user := newTestSigner(t)
+	now := time.Now()
+
+	newCert := func(certType uint32, validAfter, validBefore uint64) *ssh.Certificate {
+		cert := &ssh.Certificate{
+			Key:             user.PublicKey(),
+			CertType:        certType,
+			KeyId:           "alice@laptop",
+			Serial:          7,
+			ValidPrincipals: []string{"alice", "deploy"},
+			ValidAfter:      validAfter,
+			ValidBefore:     validBefore,
+			Permissions: ssh.Permissions{
+				CriticalOptions: map[string]string{"source-address": "10.0.0.0/8", "force-command": "uptime"},
+				Extensions:      map[string]string{"permit-pty": "", "permit-agent-forwarding": ""},
+			},
+		}
+		if err := cert.SignCert(rand.Reader, authority); err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	unix := func(value time.Time) uint64 { return uint64(value.Unix()) }
+
+	tests := []struct {
+		name     string
+		cert     *ssh.Certificate
+		signer   ssh.Signer
+		validity string
+		warnings []string
+	}{
+		{"valid", newCert(ssh.UserCert, unix(now.Add(-time.Hour)), unix(now.Add(time.Hour))), user, " → ", nil},
+		{"forever", newCert(ssh.UserCert, 0, ssh.CertTimeInfinity), user, "forever", nil},
+		{"encrypted private key", newCert(ssh.UserCert, 0, ssh.CertTimeInfinity), nil, "forever", nil},
+		{"expired", newCert(ssh.UserCert, 0, unix(now.Add(-time.Hour))), user, "always → ", []string{"Expired "}},
+		{"not yet valid", newCert(ssh.UserCert, unix(now.Add(time.Hour)), ssh.CertTimeInfinity), user, " → forever", []string{"Not valid until "}},
+		{"host certificate", newCert(ssh.HostCert, 0, ssh.CertTimeInfinity), user, "forever", []string{"This is a host certificate"}},
+		{"other private key", newCert(ssh.UserCert, 0, ssh.CertTimeInfinity), authority, "forever", []string{"Certificate does not match"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := InspectCertificate(test.cert, test.signer, now)
+			if info.KeyID != "alice@laptop" || info.Serial != 7 || !slices.Equal(info.Principals, []string{"alice", "deploy"}) {
+				t.Errorf("info = %+v", info)
+			}
+			if info.CAFingerprint != GetFingerprint(authority.PublicKey()) {
+				t.Errorf("CA fingerprint = %q", info.CAFingerprint)
+			}
+			if want := []string{"force-command=uptime", "source-address=10.0.0.0/8"}; !slices.Equal(info.CriticalOptions, want) {
+				t.Errorf("critical options = %q, want %q", info.CriticalOptions, want)
+			}
+			if want := []string{"permit-agent-forwarding", "permit-pty"}; !slices.Equal(info.Extensions, want) {
+				t.Errorf("extensions = %q, want %q", info.Extensions, want)
+			}
+			if validity := info.DescribeValidity(); !strings.Contains(validity, test.validity) {
+				t.Errorf("DescribeValidity() = %q, want it to contain %q", validity, test.validity)
+			}
+
+			if len(info.Warnings) != len(test.warnings) {
+				t.Fatalf("warnings = %q, want %d", info.Warnings, len(test.warnings))
+			}
+			for i, warning := range info.Warnings {
+				if !strings.HasPrefix(warning, test.warnings[i]) {
+					t.Errorf("warning %q, want one starting %q", warning, test.warnings[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -10,18 +12,22 @@ import (
 	"yoru/repository"
 	"yoru/shared"
 	"yoru/types"
+	"yoru/utils/network"
 
 	"golang.org/x/crypto/ssh"
 )
 
 // NewClient creates a new SSH client instance
-func NewClient(host *models.Host, credential any) *client {
+func NewClient(sessionID uint, host *models.Host, credential any) *client {
 	return &client{
-		host:       host,
-		credential: credential,
-		state:      stateConnecting,
-		outputChan: make(chan []byte, 100),
-		errorChan:  make(chan error, 10),
+		sessionID:       sessionID,
+		host:            host,
+		credential:      credential,
+		state:           stateConnecting,
+		outputChan:      make(chan []byte, 100),
+		errorChan:       make(chan error, 10),
+		hostKeyDecision: make(chan bool, 1),
+		closed:          make(chan struct{}),
 	}
 }
 
@@ -34,16 +40,16 @@ func (c *client) Connect() error {
 	}
 
 	// Connect to SSH server
-	addr := net.JoinHostPort(c.host.Hostname, fmt.Sprintf("%d", c.host.Port))
+	addr := network.JoinHostPort(c.host.Hostname, c.host.Port)
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Starting connection to %s port %d", c.host.Hostname, c.host.Port),
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Starting connection to %s port %d", c.host.Hostname, c.host.Port),
 	})
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Starting address resolution of %s", c.host.Hostname),
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Starting address resolution of %s", c.host.Hostname),
 	})
 
 	conn, err := net.DialTimeout("tcp", addr, 30*time.Second)
@@ -52,19 +58,32 @@ func (c *client) Connect() error {
 	}
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: "- Address resolution finished",
+		SessionID: c.sessionID,
+		Message:   "- Address resolution finished",
 	})
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Connecting to %s port %d", c.host.Hostname, c.host.Port),
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Connecting to %s port %d", c.host.Hostname, c.host.Port),
 	})
 
-	// Custom host key callback to capture and verify the key
-	var serverKey ssh.PublicKey
-	config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-		serverKey = key
+	// The host key is checked during the key exchange, before any
+	// credential is sent to the server
+	config.HostKeyCallback = func(_ string, _ net.Addr, key ssh.PublicKey) error {
+		// Later key exchanges of the session must present the same key
+		if c.hostKey != nil {
+			if !bytes.Equal(key.Marshal(), c.hostKey.Marshal()) {
+				return fmt.Errorf("refusing to continue: %w", ErrHostKeyChanged)
+			}
+			return nil
+		}
+		if err := c.verifyHostKey(key); err != nil {
+			return err
+		}
+		c.hostKey = key
+		// Authentication follows; say what is offered while the server
+		// may still reject it
+		c.announceAuthentication()
 		return nil
 	}
 
@@ -75,92 +94,172 @@ func (c *client) Connect() error {
 	}
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Connection to %s established", c.host.Hostname),
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Connection to %s established", c.host.Hostname),
 	})
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: "- Starting SSH session",
+		SessionID: c.sessionID,
+		Message:   "- Starting SSH session",
 	})
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Remote server: %s", string(sshConn.ServerVersion())),
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Remote server: %s", string(sshConn.ServerVersion())),
 	})
 
-	// Verify host key
-	knownHost, err := VerifyHostKey(c.host.Hostname, c.host.Port, serverKey)
-	if err != nil {
-		// Host key not known — ask user whether to add to known hosts
-		fingerprint := GetFingerprint(serverKey)
-		c.hostKeyDecision = make(chan bool, 1)
-
-		shared.SendMessage(types.SSHHostKeyMsg{
-			HostID:      c.host.ID,
-			Hostname:    c.host.Hostname,
-			Port:        c.host.Port,
-			KeyType:     serverKey.Type(),
-			Fingerprint: fingerprint,
-			ServerKey:   serverKey,
-		})
+	// Create SSH client
+	c.sshClient = ssh.NewClient(sshConn, chans, reqs)
+
+	shared.SendMessage(types.SSHAuthenticatingMsg{
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Authentication succeeded (%s)", c.authMethod()),
+	})
+
+	shared.SendMessage(types.SSHAuthenticatingMsg{
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Authenticated to %s", network.JoinHostPort(c.host.Hostname, c.host.Port)),
+	})
+
+	return nil
+}
+
+// authMethod names how the credential logs in
+func (c *client) authMethod() string {
+	switch c.credential.(type) {
+	case *models.Identity:
+		return "password"
+	case *models.Key:
+		return "publickey"
+	}
+	return "unknown"
+}
+
+// announceAuthentication reports the credential about to be offered,
+// including the certificate of a key and what may get it rejected
+func (c *client) announceAuthentication() {
+	shared.SendMessage(types.SSHAuthenticatingMsg{
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Authenticating to %s", network.JoinHostPort(c.host.Hostname, c.host.Port)),
+	})
+
+	shared.SendMessage(types.SSHAuthenticatingMsg{
+		SessionID: c.sessionID,
+		Message:   fmt.Sprintf("- Authenticating using %s method", c.authMethod()),
+	})
+
+	if key, ok := c.credential.(*models.Key); ok && key.Certificate != "" {
+		if info, err := InspectKeyCertificate(key.Certificate, key.PrivateKey); err == nil {
+			shared.SendMessage(types.SSHAuthenticatingMsg{
+				SessionID: c.sessionID,
+				Message:   fmt.Sprintf("- Offering certificate %q (serial %d) signed by CA %s, valid %s", info.KeyID, info.Serial, info.CAFingerprint, info.DescribeValidity()),
+			})
+			for _, warning := range info.Warnings {
+				shared.SendMessage(types.SSHAuthenticatingMsg{
+					SessionID: c.sessionID,
+					Message:   "- WARNING: " + warning,
+				})
+			}
+		}
+	}
+}
 
-		// Block until user decides
-		if save := <-c.hostKeyDecision; save {
-			_ = SaveHostKey(c.host.Hostname, c.host.Port, serverKey)
+// verifyHostKey decides whether to go on with a server key. Keys signed by
+// a trusted host CA are accepted and revoked keys refused; other keys must
+// be known, or accepted by the user.
+func (c *client) verifyHostKey(serverKey ssh.PublicKey) error {
+	if cert, ok := serverKey.(*ssh.Certificate); ok {
+		authority, err := VerifyHostCertificate(c.host.Hostname, c.host.Port, cert)
+		switch {
+		case err == nil:
 			shared.SendMessage(types.SSHConnectingMsg{
-				HostID:  c.host.ID,
-				Message: "- Host key added to known hosts",
+				SessionID: c.sessionID,
+				Message:   fmt.Sprintf("- Host certificate %q signed by trusted CA %s", cert.KeyId, authority.Fingerprint),
 			})
-		} else {
+			return nil
+		case errors.Is(err, ErrHostKeyRevoked):
+			return fmt.Errorf("refusing to connect: %w (%s)", err, GetFingerprint(cert.Key))
+		default:
+			// As OpenSSH does, fall back to the plain key the certificate carries
 			shared.SendMessage(types.SSHConnectingMsg{
-				HostID:  c.host.ID,
-				Message: "- Host key not saved",
+				SessionID: c.sessionID,
+				Message:   fmt.Sprintf("- Host certificate not accepted: %v", err),
 			})
+			serverKey = cert.Key
 		}
-	} else {
+	}
+
+	knownHost, err := VerifyHostKey(c.host.Hostname, c.host.Port, serverKey)
+	switch {
+	case err == nil:
 		shared.SendMessage(types.SSHConnectingMsg{
-			HostID:  c.host.ID,
-			Message: fmt.Sprintf("- Checking host key: %s", knownHost.Fingerprint),
+			SessionID: c.sessionID,
+			Message:   fmt.Sprintf("- Checking host key: %s", knownHost.Fingerprint),
 		})
 
 		shared.SendMessage(types.SSHConnectingMsg{
-			HostID:  c.host.ID,
-			Message: fmt.Sprintf("- Host %s:%d is known and matches", c.host.Hostname, c.host.Port),
+			SessionID: c.sessionID,
+			Message:   fmt.Sprintf("- Host %s is known and matches", network.JoinHostPort(c.host.Hostname, c.host.Port)),
 		})
+		return nil
+	case errors.Is(err, ErrHostKeyRevoked):
+		return fmt.Errorf("refusing to connect: %w (%s)", err, GetFingerprint(serverKey))
+	case errors.Is(err, ErrHostKeyChanged):
+		shared.SendMessage(types.SSHConnectingMsg{
+			SessionID: c.sessionID,
+			Message:   "- WARNING: the host key differs from the one trusted for this host",
+		})
+	case !errors.Is(err, ErrHostKeyUnknown):
+		return fmt.Errorf("failed to check host key: %w", err)
 	}
-
-	// Create SSH client
-	c.sshClient = ssh.NewClient(sshConn, chans, reqs)
-
-	shared.SendMessage(types.SSHAuthenticatingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Authenticating to %s:%d", c.host.Hostname, c.host.Port),
+	changed := errors.Is(err, ErrHostKeyChanged)
+
+	// Host key not known, or changed — ask the user
+	shared.SendMessage(types.SSHHostKeyMsg{
+		SessionID:   c.sessionID,
+		Hostname:    c.host.Hostname,
+		Port:        c.host.Port,
+		KeyType:     serverKey.Type(),
+		Fingerprint: GetFingerprint(serverKey),
+		ServerKey:   serverKey,
+		Changed:     changed,
 	})
 
-	// Determine auth method
-	authMethod := "unknown"
-	if _, ok := c.credential.(*models.Identity); ok {
-		authMethod = "password"
-	} else if _, ok := c.credential.(*models.Key); ok {
-		authMethod = "publickey"
+	// Block until user decides, or the session is closed meanwhile
+	var save bool
+	select {
+	case save = <-c.hostKeyDecision:
+	case <-c.closed:
+		return errors.New("connection closed")
 	}
 
-	shared.SendMessage(types.SSHAuthenticatingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Authenticating using %s method", authMethod),
-	})
-
-	shared.SendMessage(types.SSHAuthenticatingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Authentication succeeded (%s)", authMethod),
-	})
-
-	shared.SendMessage(types.SSHAuthenticatingMsg{
-		HostID:  c.host.ID,
-		Message: fmt.Sprintf("- Authenticated to %s:%d", c.host.Hostname, c.host.Port),
-	})
+	// A changed key is only ever accepted by replacing the trusted one
+	if changed {
+		if !save {
+			return fmt.Errorf("refusing to connect: %w (%s)", ErrHostKeyChanged, GetFingerprint(serverKey))
+		}
+		if err := ReplaceHostKey(c.host.Hostname, c.host.Port, serverKey); err != nil {
+			return fmt.Errorf("failed to replace host key: %w", err)
+		}
+		shared.SendMessage(types.SSHConnectingMsg{
+			SessionID: c.sessionID,
+			Message:   "- Host key replaced in known hosts",
+		})
+		return nil
+	}
 
+	if save {
+		_ = SaveHostKey(c.host.Hostname, c.host.Port, serverKey)
+		shared.SendMessage(types.SSHConnectingMsg{
+			SessionID: c.sessionID,
+			Message:   "- Host key added to known hosts",
+		})
+	} else {
+		shared.SendMessage(types.SSHConnectingMsg{
+			SessionID: c.sessionID,
+			Message:   "- Host key not saved",
+		})
+	}
 	return nil
 }
 
@@ -174,8 +273,8 @@ func (c *client) StartSession(width, height int) error {
 	c.termHeight = height
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: "- Creating terminal session",
+		SessionID: c.sessionID,
+		Message:   "- Creating terminal session",
 	})
 
 	// Create new session
@@ -219,8 +318,8 @@ func (c *client) StartSession(width, height int) error {
 	}
 
 	shared.SendMessage(types.SSHConnectingMsg{
-		HostID:  c.host.ID,
-		Message: "- Shell started successfully",
+		SessionID: c.sessionID,
+		Message:   "- Shell started successfully",
 	})
 
 	c.state = stateConnected
@@ -249,7 +348,7 @@ func (c *client) StartSession(width, height int) error {
 
 	// Send connected message
 	shared.SendMessage(types.SSHConnectedMsg{
-		HostID:        c.host.ID,
+		SessionID:     c.sessionID,
 		Client:        c,
 		ConnectionLog: connectionLog,
 	})
@@ -270,20 +369,20 @@ func (c *client) streamOutput(stdout, stderr io.Reader) {
 			copy(data, buf[:n])
 
 			shared.SendMessage(types.SSHOutputMsg{
-				HostID: c.host.ID,
-				Data:   data,
+				SessionID: c.sessionID,
+				Data:      data,
 			})
 		}
 
 		if err != nil {
 			if err == io.EOF {
 				shared.SendMessage(types.SSHDisconnectedMsg{
-					HostID: c.host.ID,
+					SessionID: c.sessionID,
 				})
 			} else {
 				shared.SendMessage(types.SSHErrorMsg{
-					HostID: c.host.ID,
-					Error:  fmt.Errorf("output stream error: %w", err),
+					SessionID: c.sessionID,
+					Error:     fmt.Errorf("output stream error: %w", err),
 				})
 			}
 			break
@@ -318,6 +417,7 @@ func (c *client) Resize(width, height int) error {
 // Close closes the SSH connection
 func (c *client) Close() error {
 	c.state = stateDisconnected
+	c.closeOnce.Do(func() { close(c.closed) })
 
 	// Update connection log
 	if c.connectionLog != nil {
@@ -2,65 +2,143 @@ package ssh
 
 import (
 	"fmt"
+	"sync"
 	"yoru/models"
 	"yoru/repository"
 	"yoru/shared"
 	"yoru/types"
+	"yoru/utils/network"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// activeClients stores active SSH clients by host ID
-var activeClients = make(map[uint]*client)
+// activeClients stores active SSH clients by session ID. Each terminal has
+// a session of its own, even when several are open on the same host.
+var (
+	activeClients = make(map[uint]*client)
+	clientsMutex  sync.Mutex
+	lastSessionID uint
 
-// InitiateConnection starts an SSH connection asynchronously
-func InitiateConnection(host *models.Host) tea.Cmd {
+	// pendingSessions are connecting but have no client yet. A session
+	// closed meanwhile is marked true, so its client is dropped once made.
+	pendingSessions = make(map[uint]bool)
+
+	// lastQuickConnectID numbers the hosts opened without saving them down
+	// from the top of the range, away from the IDs of saved hosts
+	lastQuickConnectID = ^uint(0)
+)
+
+// NewSessionID returns an ID for a new session, to tell the messages of
+// terminals on the same host apart
+func NewSessionID() uint {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	lastSessionID++
+	return lastSessionID
+}
+
+// NewQuickConnectHost describes a connection to a target that is not saved
+// as a host, logging in with a keychain credential
+func NewQuickConnectHost(target network.Target, credentialType types.CredentialType, credentialID uint) *models.Host {
+	clientsMutex.Lock()
+	lastQuickConnectID--
+	id := lastQuickConnectID
+	clientsMutex.Unlock()
+
+	host := &models.Host{
+		Name:           target.String(),
+		Hostname:       target.Hostname,
+		Mode:           types.ModeSSH,
+		Port:           target.Port,
+		CredentialType: credentialType,
+		CredentialID:   credentialID,
+		User:           target.User,
+	}
+	host.ID = id
+	return host
+}
+
+// isQuickConnectHost reports whether hostID belongs to a host that is not
+// saved
+func isQuickConnectHost(hostID uint) bool {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	return hostID >= lastQuickConnectID
+}
+
+func getClient(sessionID uint) (*client, bool) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	client, ok := activeClients[sessionID]
+	return client, ok
+}
+
+// InitiateConnection starts the SSH connection of a session asynchronously
+func InitiateConnection(sessionID uint, host *models.Host) tea.Cmd {
+	// Pending from now, so closing the terminal before the command runs
+	// still cancels it
+	startPending(sessionID)
 	return func() tea.Msg {
 		// Start connection in goroutine
-		go connectAsync(host)
+		go connectAsync(sessionID, host)
 
 		// Return connecting message immediately
 		return types.SSHConnectingMsg{
-			HostID:  host.ID,
-			Message: "- Initializing connection",
+			SessionID: sessionID,
+			Message:   "- Initializing connection",
 		}
 	}
 }
 
 // connectAsync performs the full connection flow
-func connectAsync(host *models.Host) {
+func connectAsync(sessionID uint, host *models.Host) {
 	// Load credential
 	credential, err := LoadCredential(host)
 	if err != nil {
+		takePending(sessionID)
 		shared.SendMessage(types.SSHErrorMsg{
-			HostID: host.ID,
-			Error:  fmt.Errorf("failed to load credential: %w", err),
+			SessionID: sessionID,
+			Error:     fmt.Errorf("failed to load credential: %w", err),
 		})
 		return
 	}
 
 	// Create client
-	client := NewClient(host, credential)
-
-	// Store active client
-	activeClients[host.ID] = client
+	client := NewClient(sessionID, host, credential)
+
+	// Store active client, unless the terminal closed in the meantime
+	clientsMutex.Lock()
+	canceled := pendingSessions[sessionID]
+	delete(pendingSessions, sessionID)
+	if !canceled {
+		activeClients[sessionID] = client
+	}
+	clientsMutex.Unlock()
+	if canceled {
+		return
+	}
 
 	// Attempt connection (blocks on host key decision if key is unknown)
 	if err := client.Connect(); err != nil {
 		shared.SendMessage(types.SSHErrorMsg{
-			HostID: host.ID,
-			Error:  err,
+			SessionID: sessionID,
+			Error:     err,
 		})
 		return
 	}
+	if GetClient(sessionID) != client {
+		// Closed while connecting
+		client.Close()
+		return
+	}
 
 	// Start session with dynamic dimensions
 	// Note: Dimensions will be updated by terminal screen after creation
 	// Using default 80x24 initially, will be resized immediately
 	if err := client.StartSession(80, 24); err != nil {
 		shared.SendMessage(types.SSHErrorMsg{
-			HostID: host.ID,
-			Error:  fmt.Errorf("failed to start session: %w", err),
+			SessionID: sessionID,
+			Error:     fmt.Errorf("failed to start session: %w", err),
 		})
 		return
 	}
@@ -68,12 +146,12 @@ func connectAsync(host *models.Host) {
 
 // ContinueAfterHostKeyVerification unblocks the connection goroutine after the user
 // decides whether to save the host key. save=true adds it to known hosts.
-func ContinueAfterHostKeyVerification(hostID uint, save bool) {
-	client, ok := activeClients[hostID]
+func ContinueAfterHostKeyVerification(sessionID uint, save bool) {
+	client, ok := getClient(sessionID)
 	if !ok {
 		shared.SendMessage(types.SSHErrorMsg{
-			HostID: hostID,
-			Error:  fmt.Errorf("client not found"),
+			SessionID: sessionID,
+			Error:     fmt.Errorf("client not found"),
 		})
 		return
 	}
@@ -81,43 +159,66 @@ func ContinueAfterHostKeyVerification(hostID uint, save bool) {
 	client.hostKeyDecision <- save
 }
 
-// RetryConnection retries a failed connection
-func RetryConnection(hostID uint) {
-	if client, ok := activeClients[hostID]; ok {
-		// Close existing client
-		client.Close()
-	}
-
-	// Get host from repository and retry
-	host, err := repository.GetHostByID(hostID)
-	if err != nil {
-		shared.SendMessage(types.SSHErrorMsg{
-			HostID: hostID,
-			Error:  fmt.Errorf("failed to get host: %w", err),
-		})
-		return
+// RetryConnection connects again. Callers close the failed attempt and
+// retry under a new session ID.
+func RetryConnection(sessionID uint, host *models.Host) {
+	// Saved hosts are read again, in case they were edited in the meantime
+	if !isQuickConnectHost(host.ID) {
+		saved, err := repository.GetHostByID(host.ID)
+		if err != nil {
+			shared.SendMessage(types.SSHErrorMsg{
+				SessionID: sessionID,
+				Error:     fmt.Errorf("failed to get host: %w", err),
+			})
+			return
+		}
+		host = saved
 	}
 
 	// Retry connection
-	go connectAsync(host)
+	startPending(sessionID)
+	go connectAsync(sessionID, host)
+}
+
+func startPending(sessionID uint) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	pendingSessions[sessionID] = false
 }
 
-// GetClient returns the active client for a host
-func GetClient(hostID uint) *client {
-	return activeClients[hostID]
+// takePending ends the pending state of a session whose connect gave up
+// before making a client
+func takePending(sessionID uint) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	delete(pendingSessions, sessionID)
 }
 
-// CloseConnection closes an active SSH connection
-func CloseConnection(hostID uint) {
-	if client, ok := activeClients[hostID]; ok {
+// GetClient returns the active client of a session
+func GetClient(sessionID uint) *client {
+	client, _ := getClient(sessionID)
+	return client
+}
+
+// CloseConnection closes the SSH connection of a session, or keeps it from
+// being made if it is still starting
+func CloseConnection(sessionID uint) {
+	clientsMutex.Lock()
+	client, ok := activeClients[sessionID]
+	delete(activeClients, sessionID)
+	if _, pending := pendingSessions[sessionID]; pending {
+		pendingSessions[sessionID] = true
+	}
+	clientsMutex.Unlock()
+
+	if ok {
 		client.Close()
-		delete(activeClients, hostID)
 	}
 }
 
-// ResizeTerminal resizes the terminal for an active connection
-func ResizeTerminal(hostID uint, width, height int) error {
-	client, ok := activeClients[hostID]
+// ResizeTerminal resizes the terminal of a session
+func ResizeTerminal(sessionID uint, width, height int) error {
+	client, ok := getClient(sessionID)
 	if !ok {
 		return fmt.Errorf("client not found")
 	}
@@ -125,9 +226,9 @@ func ResizeTerminal(hostID uint, width, height int) error {
 	return client.Resize(width, height)
 }
 
-// SendInput sends keyboard input to an active connection
-func SendInput(hostID uint, data []byte) error {
-	client, ok := activeClients[hostID]
+// SendInput sends keyboard input to a session
+func SendInput(sessionID uint, data []byte) error {
+	client, ok := getClient(sessionID)
 	if !ok {
 		return fmt.Errorf("client not found")
 	}
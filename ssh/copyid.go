@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"yoru/models"
+	"yoru/types"
+	"yoru/utils/network"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// installKeyScript appends stdin to ~/.ssh/authorized_keys unless the key
+// ($1, its type and base64 fields) is already there. Like ssh-copy-id it
+// fixes a missing trailing newline and the permissions sshd insists on.
+// It is a single line for sh -c, as csh cannot quote newlines.
+var installKeyScript = strings.Join([]string{
+	"cd || exit 1",
+	"umask 077",
+	"mkdir -p .ssh && touch .ssh/authorized_keys || exit 1",
+	"chmod 700 .ssh && chmod 600 .ssh/authorized_keys || exit 1",
+	`if grep -qF "$1" .ssh/authorized_keys; then echo present; exit 0; fi`,
+	`if [ -n "$(tail -c 1 .ssh/authorized_keys)" ]; then echo >> .ssh/authorized_keys || exit 1; fi`,
+	"cat >> .ssh/authorized_keys || exit 1",
+	"if type restorecon >/dev/null 2>&1; then restorecon -F .ssh .ssh/authorized_keys; fi",
+	"echo installed",
+}, "; ")
+
+// installKeyCommand runs installKeyScript through sh whatever the login
+// shell is (fish, csh and tcsh do not speak POSIX sh), passing the key
+// fields as an argument rather than in the script
+func installKeyCommand(keyFields string) string {
+	return "exec sh -c " + shellQuote(installKeyScript) + " sh " + shellQuote(keyFields)
+}
+
+// shellQuote single-quotes value for the remote shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// InstallPublicKey adds key's public key to the authorized_keys of the user
+// that credential logs in as on host. It reports false when the key was
+// already installed. The host key must already be trusted, since there is
+// no one to ask here.
+func InstallPublicKey(host *models.Host, credential any, key *models.Key) (bool, error) {
+	if host.Mode != types.ModeSSH {
+		return false, errors.New("keys can only be installed over SSH")
+	}
+
+	publicKey := strings.TrimSpace(key.PublicKey)
+	if publicKey == "" {
+		info, err := InspectPrivateKey(key.PrivateKey)
+		if err != nil {
+			return false, fmt.Errorf("unable to derive the public key: %w", err)
+		}
+		publicKey = info.PublicKey
+	}
+	parsed, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return false, fmt.Errorf("unable to parse public key: %w", err)
+	}
+	if _, ok := parsed.(*ssh.Certificate); ok {
+		return false, errors.New("expected a plain public key, not a certificate")
+	}
+
+	// Only the type and base64 fields identify the key
+	keyFields := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(parsed)))
+	line := keyFields
+	if comment != "" {
+		line += " " + comment
+	}
+
+	config, err := BuildSSHConfig(credential)
+	if err != nil {
+		return false, err
+	}
+	config.HostKeyCallback = func(_ string, _ net.Addr, serverKey ssh.PublicKey) error {
+		return checkHostKey(host.Hostname, host.Port, serverKey)
+	}
+
+	client, err := ssh.Dial("tcp", network.JoinHostPort(host.Hostname, host.Port), config)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return false, fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdin = strings.NewReader(line + "\n")
+	output, err := session.CombinedOutput(installKeyCommand(keyFields))
+	result := strings.TrimSpace(string(output))
+	if err != nil {
+		if result != "" {
+			return false, fmt.Errorf("remote command failed: %s", result)
+		}
+		return false, fmt.Errorf("remote command failed: %w", err)
+	}
+
+	switch {
+	case strings.HasSuffix(result, "installed"):
+		return true, nil
+	case strings.HasSuffix(result, "present"):
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected output from remote command: %q", result)
+}
+
+// checkHostKey accepts a server key only if it is already trusted, either
+// through a host CA or known hosts
+func checkHostKey(hostname string, port int, key ssh.PublicKey) error {
+	if cert, ok := key.(*ssh.Certificate); ok {
+		_, err := VerifyHostCertificate(hostname, port, cert)
+		if err == nil || errors.Is(err, ErrHostKeyRevoked) {
+			return err
+		}
+		key = cert.Key
+	}
+
+	if _, err := VerifyHostKey(hostname, port, key); err != nil {
+		if errors.Is(err, ErrHostKeyUnknown) {
+			return fmt.Errorf("%w, connect to the host once to verify it", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package ssh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", "''"},
+		{"ssh-ed25519 AAAA", "'ssh-ed25519 AAAA'"},
+		{"it's", `'it'\''s'`},
+		{"$(id) `id`", "'$(id) `id`'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if got := shellQuote(test.value); got != test.want {
+				t.Errorf("shellQuote(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestInstallKeyCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run the command with")
+	}
+	if strings.Contains(installKeyScript, "\n") {
+		t.Fatal("the script spans several lines")
+	}
+
+	home := t.TempDir()
+	authorizedKeys := filepath.Join(home, ".ssh", "authorized_keys")
+	install := func(keyFields, line string) string {
+		t.Helper()
+		// The login shell runs the command, as sshd does
+		cmd := exec.Command("sh", "-c", installKeyCommand(keyFields))
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		cmd.Stdin = strings.NewReader(line + "\n")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("command failed: %v: %s", err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	const first = "ssh-ed25519 AAAAfirst"
+	if got := install(first, first+" user@laptop"); got != "installed" {
+		t.Errorf("first install = %q", got)
+	}
+	if got := install(first, first+" another comment"); got != "present" {
+		t.Errorf("second install = %q", got)
+	}
+
+	// A file without a trailing newline gets one before the key
+	data, err := os.ReadFile(authorizedKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(authorizedKeys, []byte(strings.TrimSuffix(string(data), "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const second = "ssh-ed25519 AAAA'$(touch pwned)'"
+	if got := install(second, second); got != "installed" {
+		t.Errorf("install of a quoted key = %q", got)
+	}
+
+	data, err = os.ReadFile(authorizedKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := first + " user@laptop\n" + second + "\n"; string(data) != want {
+		t.Errorf("authorized_keys = %q, want %q", data, want)
+	}
+	if _, err := os.Stat(filepath.Join(home, "pwned")); err == nil {
+		t.Error("the key fields ran as a command")
+	}
+
+	info, err := os.Stat(filepath.Join(home, ".ssh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf(".ssh mode = %v", info.Mode().Perm())
+	}
+}
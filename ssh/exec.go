@@ -0,0 +1,167 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+	"yoru/models"
+	"yoru/types"
+	"yoru/utils/network"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultExecConcurrency = 8
+	DefaultExecTimeout     = 2 * time.Minute
+
+	// execOutputLimit caps what is kept of each output stream per host
+	execOutputLimit = 1 << 20
+)
+
+// ExecResult is the outcome of running a command on one host. Error is set
+// when the command could not run to completion; a command that ran but
+// failed only has a non-zero ExitCode.
+type ExecResult struct {
+	Host     models.Host
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+	Error    error
+}
+
+// Failed reports whether the command did not run or exited non-zero
+func (result ExecResult) Failed() bool {
+	return result.Error != nil || result.ExitCode != 0
+}
+
+// limitedBuffer keeps the first execOutputLimit bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (buffer *limitedBuffer) Write(data []byte) (int, error) {
+	if room := execOutputLimit - buffer.Len(); room < len(data) {
+		buffer.truncated = true
+		buffer.Buffer.Write(data[:max(room, 0)])
+		return len(data), nil
+	}
+	return buffer.Buffer.Write(data)
+}
+
+func (buffer *limitedBuffer) String() string {
+	if buffer.truncated {
+		return buffer.Buffer.String() + "\n[output truncated]\n"
+	}
+	return buffer.Buffer.String()
+}
+
+// Exec runs command on host in a non-interactive session, without a PTY.
+// The host key must already be trusted, since there is no one to ask.
+func Exec(host *models.Host, command string, timeout time.Duration) (result ExecResult) {
+	result = ExecResult{Host: *host, ExitCode: -1}
+	started := time.Now()
+	defer func() { result.Duration = time.Since(started) }()
+
+	if host.Mode != types.ModeSSH {
+		result.Error = errors.New("commands can only be run over SSH")
+		return result
+	}
+
+	credential, err := LoadCredential(host)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to load credential: %w", err)
+		return result
+	}
+	config, err := BuildSSHConfig(credential)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	config.Timeout = min(timeout, 30*time.Second)
+	config.HostKeyCallback = func(_ string, _ net.Addr, serverKey ssh.PublicKey) error {
+		return checkHostKey(host.Hostname, host.Port, serverKey)
+	}
+
+	client, err := ssh.Dial("tcp", network.JoinHostPort(host.Hostname, host.Port), config)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		result.Error = fmt.Errorf("failed to create session: %w", err)
+		return result
+	}
+	defer session.Close()
+
+	var stdout, stderr limitedBuffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(command) }()
+
+	select {
+	case err = <-done:
+	case <-time.After(timeout - time.Since(started)):
+		session.Signal(ssh.SIGKILL)
+		client.Close()
+		// Run returns once the connection is gone, and with it the writes
+		// to the buffers
+		<-done
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitStatus()
+	default:
+		result.Error = err
+	}
+	return result
+}
+
+// execHost runs the command on each host for ExecAll
+var execHost = Exec
+
+// ExecAll runs command on hosts, at most concurrency at a time. onResult,
+// if set, is called from the worker goroutines as each host finishes;
+// the results are returned in the order of hosts.
+func ExecAll(hosts []models.Host, command string, concurrency int, timeout time.Duration, onResult func(index int, result ExecResult)) []ExecResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]ExecResult, len(hosts))
+	slots := make(chan struct{}, concurrency)
+	var wait sync.WaitGroup
+	for index := range hosts {
+		wait.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wait.Done()
+			defer func() { <-slots }()
+
+			results[index] = execHost(&hosts[index], command, timeout)
+			if onResult != nil {
+				onResult(index, results[index])
+			}
+		}()
+	}
+	wait.Wait()
+	return results
+}
@@ -0,0 +1,100 @@
+package ssh
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"yoru/models"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		name          string
+		writes        []int
+		wantLen       int
+		wantTruncated bool
+	}{
+		{"under the limit", []int{10, 20}, 30, false},
+		{"exactly the limit", []int{execOutputLimit}, execOutputLimit, false},
+		{"one write over", []int{execOutputLimit + 1}, execOutputLimit, true},
+		{"crossing the limit", []int{execOutputLimit - 5, 10}, execOutputLimit, true},
+		{"after the limit", []int{execOutputLimit, 1, 1}, execOutputLimit, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer limitedBuffer
+			for _, size := range test.writes {
+				if n, err := buffer.Write(bytes.Repeat([]byte("x"), size)); n != size || err != nil {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, size)
+				}
+			}
+			if buffer.Len() != test.wantLen {
+				t.Errorf("Len() = %d, want %d", buffer.Len(), test.wantLen)
+			}
+			if got := strings.HasSuffix(buffer.String(), "[output truncated]\n"); got != test.wantTruncated {
+				t.Errorf("truncated = %v, want %v", got, test.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestExecAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		hosts       int
+		concurrency int
+		wantMax     int
+	}{
+		{"fewer hosts than slots", 3, 8, 3},
+		{"more hosts than slots", 10, 4, 4},
+		{"one at a time", 5, 1, 1},
+		{"no concurrency given", 4, 0, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lock sync.Mutex
+			running, maxRunning := 0, 0
+			execHost = func(host *models.Host, command string, _ time.Duration) ExecResult {
+				lock.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return ExecResult{Host: *host, Stdout: host.Name + ": " + command}
+			}
+			defer func() { execHost = Exec }()
+
+			hosts := make([]models.Host, test.hosts)
+			for index := range hosts {
+				hosts[index].Name = string(rune('a' + index))
+			}
+			reported := make(map[int]bool)
+			results := ExecAll(hosts, "uptime", test.concurrency, time.Second, func(index int, result ExecResult) {
+				lock.Lock()
+				defer lock.Unlock()
+				reported[index] = true
+			})
+
+			if maxRunning != test.wantMax {
+				t.Errorf("ran %d at once, want %d", maxRunning, test.wantMax)
+			}
+			if len(reported) != test.hosts {
+				t.Errorf("onResult called for %d hosts, want %d", len(reported), test.hosts)
+			}
+			for index, result := range results {
+				if want := hosts[index].Name + ": uptime"; result.Stdout != want {
+					t.Errorf("results[%d] = %q, want %q", index, result.Stdout, want)
+				}
+			}
+		})
+	}
+}
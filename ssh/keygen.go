@@ -0,0 +1,235 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"yoru/models"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	ErrInvalidKeyBits     = errors.New("invalid key size")
+)
+
+// KeyAlgorithm is a key type offered for generation, as named by ssh-keygen -t
+type KeyAlgorithm string
+
+const (
+	KeyEd25519 KeyAlgorithm = "ed25519"
+	KeyECDSA   KeyAlgorithm = "ecdsa"
+	KeyRSA     KeyAlgorithm = "rsa"
+)
+
+const (
+	minRSABits     = 2048
+	maxRSABits     = 4096
+	defaultRSABits = 3072
+)
+
+// KeySpec describes a key to generate. Bits is ignored for Ed25519 and
+// defaults to 256 for ECDSA and 3072 for RSA when zero.
+type KeySpec struct {
+	Algorithm KeyAlgorithm
+	Bits      int
+	Comment   string
+}
+
+// GeneratedKey holds a new key pair in OpenSSH formats
+type GeneratedKey struct {
+	PrivateKey string // PEM "OPENSSH PRIVATE KEY", unencrypted like every keychain key
+	PublicKey  string // authorized_keys line, with the comment
+}
+
+// GenerateKey creates a key pair the way ssh-keygen does
+func GenerateKey(spec KeySpec) (*GeneratedKey, error) {
+	var private crypto.PrivateKey
+	var public crypto.PublicKey
+
+	switch spec.Algorithm {
+	case KeyEd25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private, public = priv, pub
+	case KeyECDSA:
+		var curve elliptic.Curve
+		switch spec.Bits {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: ECDSA keys are 256, 384 or 521 bits", ErrInvalidKeyBits)
+		}
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private, public = priv, &priv.PublicKey
+	case KeyRSA:
+		bits := spec.Bits
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		if bits < minRSABits || bits > maxRSABits {
+			return nil, fmt.Errorf("%w: RSA keys are %d to %d bits", ErrInvalidKeyBits, minRSABits, maxRSABits)
+		}
+		priv, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		private, public = priv, &priv.PublicKey
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyType, spec.Algorithm)
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, spec.Comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublic)))
+	if spec.Comment != "" {
+		publicKey += " " + spec.Comment
+	}
+
+	return &GeneratedKey{
+		PrivateKey: string(pem.EncodeToMemory(block)),
+		PublicKey:  publicKey,
+	}, nil
+}
+
+// ExportKey writes a keychain key to path with OpenSSH's layout and
+// permissions: the private key (0600), path.pub and, when the key has a
+// certificate, path-cert.pub (0644). A path ending in .ppk gets a single
+// PuTTY key file instead. The private key is encrypted when passphrase is
+// set. Existing files are never overwritten. It returns the files written.
+func ExportKey(key *models.Key, path, passphrase string) ([]string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, errors.New("export path is required")
+	}
+
+	type exportFile struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}
+	var files []exportFile
+	if strings.EqualFold(filepath.Ext(path), ".ppk") {
+		ppk, err := ExportPPK(key, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		files = []exportFile{{path, string(ppk), 0600}}
+	} else {
+		privateKey := key.PrivateKey
+		if passphrase != "" {
+			if privateKey, err = encryptPrivateKey(key, passphrase); err != nil {
+				return nil, err
+			}
+		}
+		files = []exportFile{
+			{path, privateKey, 0600},
+			{path + ".pub", key.PublicKey, 0644},
+			{path + "-cert.pub", key.Certificate, 0644},
+		}
+	}
+
+	for _, file := range files {
+		if file.content == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err == nil {
+			return nil, fmt.Errorf("%s already exists", file.path)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, file := range files {
+		if file.content == "" {
+			continue
+		}
+		content := strings.TrimRight(file.content, "\n") + "\n"
+		if err := writeNewFile(file.path, content, file.mode); err != nil {
+			return written, err
+		}
+		written = append(written, file.path)
+	}
+	return written, nil
+}
+
+// encryptPrivateKey encodes a keychain key as an OpenSSH private key
+// protected by passphrase, keeping the comment of its public key
+func encryptPrivateKey(key *models.Key, passphrase string) (string, error) {
+	private, err := ssh.ParseRawPrivateKey([]byte(key.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	var comment string
+	if fields := strings.Fields(key.PublicKey); len(fields) > 2 {
+		comment = strings.Join(fields[2:], " ")
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(private, comment, []byte(passphrase))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt private key: %w", err)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// expandHome resolves a leading ~/ the way a shell would
+func expandHome(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
+
+// writeNewFile creates path with mode regardless of the umask
+func writeNewFile(path, content string, mode os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := file.Chmod(mode); err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package ssh
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"yoru/models"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKey(t *testing.T) {
+	tests := []struct {
+		spec     KeySpec
+		wantType string
+		wantErr  error
+	}{
+		{KeySpec{Algorithm: KeyEd25519, Comment: "alice@laptop"}, ssh.KeyAlgoED25519, nil},
+		{KeySpec{Algorithm: KeyECDSA}, ssh.KeyAlgoECDSA256, nil},
+		{KeySpec{Algorithm: KeyECDSA, Bits: 384}, ssh.KeyAlgoECDSA384, nil},
+		{KeySpec{Algorithm: KeyECDSA, Bits: 521}, ssh.KeyAlgoECDSA521, nil},
+		{KeySpec{Algorithm: KeyRSA, Bits: 2048}, ssh.KeyAlgoRSA, nil},
+		{KeySpec{Algorithm: KeyECDSA, Bits: 512}, "", ErrInvalidKeyBits},
+		{KeySpec{Algorithm: KeyRSA, Bits: 1024}, "", ErrInvalidKeyBits},
+		{KeySpec{Algorithm: KeyRSA, Bits: 8192}, "", ErrInvalidKeyBits},
+		{KeySpec{Algorithm: "dsa"}, "", ErrUnsupportedKeyType},
+	}
+
+	for _, test := range tests {
+		name := string(test.spec.Algorithm)
+		key, err := GenerateKey(test.spec)
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("GenerateKey(%s %d) = %v, want %v", name, test.spec.Bits, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GenerateKey(%s %d) = %v", name, test.spec.Bits, err)
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey([]byte(key.PrivateKey))
+		if err != nil {
+			t.Errorf("%s: private key does not parse: %v", name, err)
+			continue
+		}
+		public, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(key.PublicKey))
+		if err != nil {
+			t.Errorf("%s: public key does not parse: %v", name, err)
+			continue
+		}
+		if public.Type() != test.wantType {
+			t.Errorf("%s: key type = %s, want %s", name, public.Type(), test.wantType)
+		}
+		if !slices.Equal(public.Marshal(), signer.PublicKey().Marshal()) {
+			t.Errorf("%s: public key does not match the private key", name)
+		}
+		if comment != test.spec.Comment {
+			t.Errorf("%s: comment = %q, want %q", name, comment, test.spec.Comment)
+		}
+	}
+}
+
+func TestExportKey(t *testing.T) {
+	generated, err := GenerateKey(KeySpec{Algorithm: KeyEd25519, Comment: "alice@laptop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &models.Key{PrivateKey: generated.PrivateKey, PublicKey: generated.PublicKey}
+
+	t.Run("plain", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys", "id_ed25519")
+		written, err := ExportKey(key, path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{path, path + ".pub"}; !slices.Equal(written, want) {
+			t.Errorf("written = %q, want %q", written, want)
+		}
+		for file, mode := range map[string]os.FileMode{path: 0600, path + ".pub": 0644} {
+			info, err := os.Stat(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != mode {
+				t.Errorf("%s mode = %v, want %v", filepath.Base(file), info.Mode().Perm(), mode)
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ssh.ParsePrivateKey(data); err != nil {
+			t.Errorf("exported key does not parse: %v", err)
+		}
+
+		if _, err := ExportKey(key, path, ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("ExportKey() over existing files = %v, want an error", err)
+		}
+	})
+
+	t.Run("passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "id_ed25519")
+		if _, err := ExportKey(key, path, "secret"); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var missing *ssh.PassphraseMissingError
+		if _, err := ssh.ParsePrivateKey(data); !errors.As(err, &missing) {
+			t.Errorf("ParsePrivateKey() = %v, want the key to be encrypted", err)
+		}
+		if _, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte("secret")); err != nil {
+			t.Errorf("ParsePrivateKeyWithPassphrase() = %v", err)
+		}
+	})
+
+	t.Run("certificate", func(t *testing.T) {
+		withCert := *key
+		withCert.Certificate = "ssh-ed25519-cert-v01@openssh.com AAAA"
+		path := filepath.Join(t.TempDir(), "id_ed25519")
+		written, err := ExportKey(&withCert, path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{path, path + ".pub", path + "-cert.pub"}; !slices.Equal(written, want) {
+			t.Errorf("written = %q, want %q", written, want)
+		}
+	})
+
+	t.Run("no path", func(t *testing.T) {
+		if _, err := ExportKey(key, "  ", ""); err == nil {
+			t.Error("ExportKey() without a path = nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ed25519"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"yoru/models"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ImportedKey is a private key converted to OpenSSH format
+type ImportedKey struct {
+	PrivateKey string
+	PublicKey  string
+	Comment    string
+	Format     string // the format it was converted from
+}
+
+// NeedsConversion reports whether a private key is in a format other than
+// OpenSSH's own, such as a PuTTY key or a PEM (PKCS#1, PKCS#8, SEC1) key
+func NeedsConversion(privateKey string) bool {
+	data := []byte(strings.TrimSpace(privateKey))
+	if IsPPK(data) {
+		return true
+	}
+	block, _ := pem.Decode(data)
+	return block != nil && block.Type != "OPENSSH PRIVATE KEY"
+}
+
+// ConvertPrivateKey reads a PuTTY (.ppk), PKCS#8 (optionally encrypted),
+// PKCS#1, SEC1 or OpenSSH private key and re-encodes it as an unencrypted
+// OpenSSH key, so it can be used without prompting. passphrase is only
+// needed for encrypted keys.
+func ConvertPrivateKey(data []byte, passphrase string) (*ImportedKey, error) {
+	data = bytes.TrimSpace(data)
+
+	var key crypto.Signer
+	var comment, format string
+	if IsPPK(data) {
+		var err error
+		if key, comment, err = ParsePPK(data, passphrase); err != nil {
+			return nil, err
+		}
+		format = "PuTTY"
+	} else {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("unrecognised private key format")
+		}
+		format = pemFormats[block.Type]
+		if format == "" {
+			format = block.Type
+		}
+
+		var raw any
+		var err error
+		if block.Type == "ENCRYPTED PRIVATE KEY" {
+			if passphrase == "" {
+				return nil, ErrKeyEncrypted
+			}
+			raw, err = decryptPKCS8(block.Bytes, passphrase)
+		} else {
+			raw, err = ssh.ParseRawPrivateKey(data)
+			var passphraseErr *ssh.PassphraseMissingError
+			if errors.As(err, &passphraseErr) {
+				if passphrase == "" {
+					return nil, ErrKeyEncrypted
+				}
+				raw, err = ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(passphrase))
+				if errors.Is(err, x509.IncorrectPasswordError) {
+					err = ErrBadPassphrase
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// OpenSSH keys come back as a pointer
+		if edKey, ok := raw.(*ed25519.PrivateKey); ok {
+			raw = *edKey
+		}
+		signer, ok := raw.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, raw)
+		}
+		key = signer
+	}
+
+	block, err := ssh.MarshalPrivateKey(key, comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	public, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+	if comment != "" {
+		publicKey += " " + comment
+	}
+
+	return &ImportedKey{
+		PrivateKey: string(pem.EncodeToMemory(block)),
+		PublicKey:  publicKey,
+		Comment:    comment,
+		Format:     format,
+	}, nil
+}
+
+// ImportKeyFile converts a private key file for the keychain, named after
+// its comment or file name. A certificate next to it (-cert.pub) is kept.
+func ImportKeyFile(path, passphrase string) (*models.Key, *ImportedKey, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path == "" {
+		return nil, nil, errors.New("key file path is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	imported, err := ConvertPrivateKey(data, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := imported.Comment
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	key := &models.Key{
+		Name:       name,
+		PrivateKey: imported.PrivateKey,
+		PublicKey:  imported.PublicKey,
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, certPath := range []string{path + "-cert.pub", base + "-cert.pub"} {
+		if cert, err := os.ReadFile(certPath); err == nil {
+			key.Certificate = strings.TrimSpace(string(cert))
+			break
+		}
+	}
+	return key, imported, nil
+}
+
+// ExportPPK encodes a keychain private key as a PuTTY key file
+func ExportPPK(key *models.Key, passphrase string) ([]byte, error) {
+	raw, err := ssh.ParseRawPrivateKey([]byte(key.PrivateKey))
+	if err != nil {
+		var passphraseErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseErr) {
+			return nil, ErrKeyEncrypted
+		}
+		return nil, err
+	}
+	if edKey, ok := raw.(*ed25519.PrivateKey); ok {
+		raw = *edKey
+	}
+	signer, ok := raw.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, raw)
+	}
+
+	// PuTTY shows the comment as the key's name
+	comment := key.Name
+	if fields := strings.Fields(key.PublicKey); len(fields) > 2 {
+		comment = strings.Join(fields[2:], " ")
+	}
+	return MarshalPPK(signer, comment, passphrase)
+}
+
+var pemFormats = map[string]string{
+	"PRIVATE KEY":           "PKCS#8",
+	"ENCRYPTED PRIVATE KEY": "PKCS#8",
+	"RSA PRIVATE KEY":       "PKCS#1",
+	"EC PRIVATE KEY":        "SEC1",
+	"OPENSSH PRIVATE KEY":   "OpenSSH",
+}
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// pkcs8MaxIterations caps the PBKDF2 iteration count of encrypted PKCS#8
+// keys, well above what OpenSSL writes, so a crafted file cannot stall on
+// key derivation
+const pkcs8MaxIterations = 10_000_000
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts a PKCS#8 EncryptedPrivateKeyInfo using PBES2 with
+// PBKDF2, the scheme OpenSSL has written by default since 1.1
+func decryptPKCS8(der []byte, passphrase string) (any, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("invalid encrypted PKCS#8 key: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported PKCS#8 encryption %v", info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("invalid PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation %v", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("invalid PBKDF2 parameters: %w", err)
+	}
+	if kdf.IterationCount <= 0 || kdf.IterationCount > pkcs8MaxIterations {
+		return nil, fmt.Errorf("PBKDF2 iteration count %d out of range", kdf.IterationCount)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case kdf.PRF.Algorithm == nil, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 hash %v", kdf.PRF.Algorithm)
+	}
+
+	var keyLength int
+	var newCipher func([]byte) (cipher.Block, error)
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLength, newCipher = 16, aes.NewCipher
+	case scheme.Equal(oidAES192CBC):
+		keyLength, newCipher = 24, aes.NewCipher
+	case scheme.Equal(oidAES256CBC):
+		keyLength, newCipher = 32, aes.NewCipher
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLength, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, fmt.Errorf("unsupported PKCS#8 cipher %v", scheme)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("invalid cipher parameters: %w", err)
+	}
+
+	derived, err := pbkdf2.Key(prf, passphrase, kdf.Salt, kdf.IterationCount, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.EncryptedData) == 0 || len(info.EncryptedData)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid encrypted PKCS#8 key")
+	}
+
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+
+	// A wrong passphrase almost always shows up as bad padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > block.BlockSize() || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, ErrBadPassphrase
+	}
+	key, err := x509.ParsePKCS8PrivateKey(plain[:len(plain)-padding])
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return key, nil
+}
@@ -0,0 +1,118 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// encryptPKCS8 writes der as an encrypted PKCS#8 key the way OpenSSL does,
+// with PBKDF2 over HMAC-SHA256 and AES-256-CBC
+func encryptPKCS8(t *testing.T, der []byte, passphrase string, iterations int) []byte {
+	t.Helper()
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	rand.Read(salt)
+	rand.Read(iv)
+
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plain := append(bytes.Clone(der), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	// Counts out of range are rejected before derivation, so any key does
+	derived := iterations
+	if derived <= 0 || derived > pkcs8MaxIterations {
+		derived = 1
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, derived, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	marshal := func(value any) asn1.RawValue {
+		data, err := asn1.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{FullBytes: data}
+	}
+	kdf := pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	}
+	params := pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: marshal(kdf)},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: marshal(iv)},
+	}
+	info := encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: marshal(params)},
+		EncryptedData: encrypted,
+	}
+	data, err := asn1.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: data})
+}
+
+func TestConvertEncryptedPKCS8(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		iterations int
+		passphrase string
+		wantErr    string
+		wantIs     error
+	}{
+		{"openssl default", 2048, "secret", "", nil},
+		{"wrong passphrase", 2048, "other", "", ErrBadPassphrase},
+		{"no iterations", 0, "secret", "out of range", nil},
+		{"negative iterations", -1, "secret", "out of range", nil},
+		{"too many iterations", pkcs8MaxIterations + 1, "secret", "out of range", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := encryptPKCS8(t, der, "secret", test.iterations)
+			imported, err := ConvertPrivateKey(data, test.passphrase)
+			switch {
+			case test.wantIs != nil:
+				if !errors.Is(err, test.wantIs) {
+					t.Errorf("ConvertPrivateKey() = %v, want %v", err, test.wantIs)
+				}
+			case test.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("ConvertPrivateKey() = %v, want an error containing %q", err, test.wantErr)
+				}
+			case err != nil:
+				t.Errorf("ConvertPrivateKey() = %v", err)
+			case imported.Format != "PKCS#8":
+				t.Errorf("format = %q, want PKCS#8", imported.Format)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrPublicKeyMismatch = errors.New("public key does not match the private key")
+	ErrKeyEncrypted      = errors.New("private key is passphrase protected")
+)
+
+// KeyInfo describes a key pair for display
+type KeyInfo struct {
+	Type           string // as ssh-keygen prints it, e.g. ED25519
+	Bits           int
+	PublicKey      string // authorized_keys line derived from the private key
+	Fingerprint    string
+	MD5Fingerprint string
+	Randomart      string
+}
+
+// InspectPrivateKey derives the public half of a private key. Encrypted
+// OpenSSH keys still carry their public key in the clear; older encrypted
+// PEM keys do not, and return ErrKeyEncrypted.
+func InspectPrivateKey(privateKey string) (*KeyInfo, error) {
+	var publicKey ssh.PublicKey
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	var passphraseErr *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		publicKey = signer.PublicKey()
+	case errors.As(err, &passphraseErr) && passphraseErr.PublicKey != nil:
+		publicKey = passphraseErr.PublicKey
+	case errors.As(err, &passphraseErr):
+		return nil, ErrKeyEncrypted
+	default:
+		return nil, err
+	}
+
+	return DescribePublicKey(publicKey), nil
+}
+
+// DescribePublicKey fills a KeyInfo for a public key
+func DescribePublicKey(key ssh.PublicKey) *KeyInfo {
+	info := &KeyInfo{
+		Type:           keyTypeName(key),
+		Bits:           keyBits(key),
+		PublicKey:      strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+		Fingerprint:    GetFingerprint(key),
+		MD5Fingerprint: GetMD5Fingerprint(key),
+	}
+	info.Randomart = Randomart(key, info.Type, info.Bits)
+	return info
+}
+
+// CheckPublicKey compares a user-supplied authorized_keys line with the
+// public key derived from the private key; the comment is ignored
+func (info *KeyInfo) CheckPublicKey(publicKey string) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(publicKey)))
+	if err != nil {
+		return fmt.Errorf("unable to parse public key: %w", err)
+	}
+	derived, _, _, _, err := ssh.ParseAuthorizedKey([]byte(info.PublicKey))
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(key.Marshal(), derived.Marshal()) {
+		return ErrPublicKeyMismatch
+	}
+	return nil
+}
+
+func keyTypeName(key ssh.PublicKey) string {
+	switch key.Type() {
+	case ssh.KeyAlgoED25519:
+		return "ED25519"
+	case ssh.KeyAlgoSKED25519:
+		return "ED25519-SK"
+	case ssh.KeyAlgoRSA:
+		return "RSA"
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+		return "ECDSA"
+	case ssh.KeyAlgoSKECDSA256:
+		return "ECDSA-SK"
+	case ssh.KeyAlgoDSA:
+		return "DSA"
+	}
+	return strings.ToUpper(key.Type())
+}
+
+func keyBits(key ssh.PublicKey) int {
+	cryptoKey, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+	switch pub := cryptoKey.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	}
+	return 0
+}
+
+const (
+	randomartWidth  = 17
+	randomartHeight = 9
+	randomartSymbol = " .o+=*BOX@%&#/^SE"
+)
+
+// Randomart draws the "drunken bishop" image ssh-keygen shows for a key's
+// SHA256 fingerprint, so keys can be told apart at a glance
+func Randomart(key ssh.PublicKey, keyType string, bits int) string {
+	digest := sha256.Sum256(key.Marshal())
+
+	var field [randomartWidth][randomartHeight]int
+	x, y := randomartWidth/2, randomartHeight/2
+	startX, startY := x, y
+	maxValue := len(randomartSymbol) - 1
+
+	for _, input := range digest {
+		for range 4 {
+			if input&0x1 != 0 {
+				x++
+			} else {
+				x--
+			}
+			if input&0x2 != 0 {
+				y++
+			} else {
+				y--
+			}
+			x = max(0, min(x, randomartWidth-1))
+			y = max(0, min(y, randomartHeight-1))
+			if field[x][y] < maxValue-2 {
+				field[x][y]++
+			}
+			input >>= 2
+		}
+	}
+	field[startX][startY] = maxValue - 1
+	field[x][y] = maxValue
+
+	var art strings.Builder
+	title := fmt.Sprintf("[%s %d]", keyType, bits)
+	if bits == 0 {
+		title = "[" + keyType + "]"
+	}
+	art.WriteString(randomartBorder(title) + "\n")
+	for row := range randomartHeight {
+		art.WriteString("|")
+		for col := range randomartWidth {
+			art.WriteByte(randomartSymbol[field[col][row]])
+		}
+		art.WriteString("|\n")
+	}
+	art.WriteString(randomartBorder("[SHA256]"))
+	return art.String()
+}
+
+// randomartBorder centres a label in the top or bottom border
+func randomartBorder(label string) string {
+	if len(label) > randomartWidth {
+		label = label[:randomartWidth]
+	}
+	left := (randomartWidth - len(label)) / 2
+	return "+" + strings.Repeat("-", left) + label + strings.Repeat("-", randomartWidth-left-len(label)) + "+"
+}
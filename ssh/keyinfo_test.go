@@ -0,0 +1,112 @@
+package ssh
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestRandomart(t *testing.T) {
+	// As printed by ssh-keygen -lv
+	publicKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBmYbIxoOvCybrH9KyHERM6MCqxyIiBrn6Z+XUBJvzNC test"
+	want := strings.Join([]string{
+		"+--[ED25519 256]--+",
+		"|o=+              |",
+		"|oo..   .         |",
+		"|*o.   . o        |",
+		"|oBo  . + o       |",
+		"|OE.   o S        |",
+		"|B+ ..o +         |",
+		"|+.=+o + o        |",
+		"|ooo=++.o         |",
+		"| .+o=*.          |",
+		"+----[SHA256]-----+",
+	}, "\n")
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := DescribePublicKey(key)
+	if info.Randomart != want {
+		t.Errorf("Randomart =\n%s\nwant\n%s", info.Randomart, want)
+	}
+	if info.Fingerprint != "SHA256:pNSbblKGzm66uYGzJYgAA1NMg89KrEy0ipo3TT7KROA=" {
+		t.Errorf("Fingerprint = %q", info.Fingerprint)
+	}
+}
+
+func TestInspectPrivateKey(t *testing.T) {
+	tests := []struct {
+		spec     KeySpec
+		wantType string
+		wantBits int
+	}{
+		{KeySpec{Algorithm: KeyEd25519}, "ED25519", 256},
+		{KeySpec{Algorithm: KeyECDSA, Bits: 384}, "ECDSA", 384},
+		{KeySpec{Algorithm: KeyRSA, Bits: 2048}, "RSA", 2048},
+	}
+
+	for _, test := range tests {
+		generated, err := GenerateKey(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := InspectPrivateKey(generated.PrivateKey)
+		if err != nil {
+			t.Errorf("InspectPrivateKey(%s) = %v", test.spec.Algorithm, err)
+			continue
+		}
+		if info.Type != test.wantType || info.Bits != test.wantBits {
+			t.Errorf("InspectPrivateKey(%s) = %s %d, want %s %d", test.spec.Algorithm, info.Type, info.Bits, test.wantType, test.wantBits)
+		}
+		if title := fmt.Sprintf("[%s %d]", test.wantType, test.wantBits); !strings.Contains(info.Randomart, title) {
+			t.Errorf("Randomart does not show %q", title)
+		}
+		if err := info.CheckPublicKey(generated.PublicKey); err != nil {
+			t.Errorf("CheckPublicKey() of its own public key = %v", err)
+		}
+	}
+}
+
+func TestInspectEncryptedPrivateKey(t *testing.T) {
+	generated, err := GenerateKey(KeySpec{Algorithm: KeyEd25519, Comment: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := ssh.ParseRawPrivateKey([]byte(generated.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(private, "alice", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// OpenSSH keys carry their public key in the clear
+	info, err := InspectPrivateKey(string(pem.EncodeToMemory(block)))
+	if err != nil {
+		t.Fatalf("InspectPrivateKey() = %v", err)
+	}
+	if err := info.CheckPublicKey(generated.PublicKey); err != nil {
+		t.Errorf("CheckPublicKey() = %v", err)
+	}
+
+	other, err := GenerateKey(KeySpec{Algorithm: KeyEd25519})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.CheckPublicKey(other.PublicKey); !errors.Is(err, ErrPublicKeyMismatch) {
+		t.Errorf("CheckPublicKey() of another key = %v, want %v", err, ErrPublicKeyMismatch)
+	}
+	if err := info.CheckPublicKey("garbage"); err == nil {
+		t.Error("CheckPublicKey() of garbage = nil, want an error")
+	}
+	if _, err := InspectPrivateKey("garbage"); err == nil {
+		t.Error("InspectPrivateKey() of garbage = nil, want an error")
+	}
+}
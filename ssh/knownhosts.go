@@ -0,0 +1,291 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+	"yoru/utils/network"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	ErrHostKeyUnknown = errors.New("host key is not known")
+	ErrHostKeyChanged = errors.New("host key does not match the known key for this host")
+	ErrHostKeyRevoked = errors.New("host key has been revoked")
+)
+
+// KnownHostsImport summarises a known_hosts import
+type KnownHostsImport struct {
+	Imported int
+	Skipped  int
+	Invalid  int
+}
+
+// KnownHostsExport summarises a known_hosts export. Skipped counts entries
+// saved before public keys were stored, which cannot be written out.
+type KnownHostsExport struct {
+	Written int
+	Present int
+	Skipped int
+}
+
+// DefaultKnownHostsPath returns the current user's OpenSSH known_hosts file
+func DefaultKnownHostsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// knownHostPattern returns the known_hosts host field for an entry
+func knownHostPattern(knownHost *models.KnownHost) string {
+	if knownHost.Pattern != "" {
+		return knownHost.Pattern
+	}
+	return knownhosts.Normalize(network.JoinHostPort(knownHost.Hostname, knownHost.Port))
+}
+
+// matchesKnownHost applies an entry's host patterns to an address the way
+// OpenSSH does: a negated pattern excludes the host outright, otherwise any
+// matching pattern (plain, wildcard or hashed) selects it
+func matchesKnownHost(knownHost *models.KnownHost, hostname string, port int) bool {
+	if knownHost.Pattern == "" {
+		return knownHost.Hostname == hostname && knownHost.Port == port
+	}
+
+	address := knownhosts.Normalize(network.JoinHostPort(hostname, port))
+	matched := false
+	for _, pattern := range strings.Split(knownHost.Pattern, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		var ok bool
+		if strings.HasPrefix(pattern, "|") {
+			ok = matchHashedHost(pattern, address)
+		} else {
+			// Like OpenSSH, match against "[host]:port" for non-standard ports,
+			// so "*" covers every address but "*.example.com" only port 22
+			ok = wildcardMatch(strings.ToLower(pattern), strings.ToLower(address))
+		}
+
+		if ok && negated {
+			return false
+		}
+		matched = matched || ok
+	}
+	return matched
+}
+
+// splitHostPattern separates a "[host]:port" pattern; others use port 22
+func splitHostPattern(pattern string) (string, int) {
+	if strings.HasPrefix(pattern, "[") {
+		if end := strings.LastIndex(pattern, "]:"); end > 0 {
+			if port, err := strconv.Atoi(pattern[end+2:]); err == nil {
+				return pattern[1:end], port
+			}
+		}
+	}
+	return pattern, 22
+}
+
+// matchHashedHost checks a "|1|salt|hash" entry, an HMAC-SHA1 of the
+// normalised address keyed with the salt
+func matchHashedHost(pattern, address string) bool {
+	parts := strings.Split(pattern, "|")
+	if len(parts) != 4 || parts[1] != "1" {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(address))
+	return hmac.Equal(mac.Sum(nil), hash)
+}
+
+// wildcardMatch supports the * and ? wildcards of known_hosts patterns,
+// where * also matches dots
+func wildcardMatch(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if wildcardMatch(pattern[1:], value[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+		default:
+			if len(value) == 0 || pattern[0] != value[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		value = value[1:]
+	}
+	return len(value) == 0
+}
+
+func isPlainHostPattern(pattern string) bool {
+	return !strings.ContainsAny(pattern, "|*?!,")
+}
+
+func knownHostKey(marker types.KnownHostMarker, pattern, fingerprint string) string {
+	return string(marker) + " " + pattern + " " + fingerprint
+}
+
+// ImportKnownHosts adds the entries of an OpenSSH known_hosts file that are
+// not stored yet, keeping hashed hosts, patterns and markers as they are
+func ImportKnownHosts(path string) (KnownHostsImport, error) {
+	var result KnownHostsImport
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+
+	existing, err := repository.GetAllKnownHosts()
+	if err != nil {
+		return result, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for i := range existing {
+		seen[knownHostKey(existing[i].Marker, knownHostPattern(&existing[i]), existing[i].Fingerprint)] = true
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		marker, hosts, key, comment, _, err := ssh.ParseKnownHosts(scanner.Bytes())
+		if err == io.EOF {
+			continue
+		}
+		if err != nil || (marker != "" && marker != string(types.KnownHostCertAuthority) && marker != string(types.KnownHostRevoked)) {
+			result.Invalid++
+			continue
+		}
+
+		knownHost := &models.KnownHost{
+			Pattern:     strings.Join(hosts, ","),
+			Marker:      types.KnownHostMarker(marker),
+			KeyType:     key.Type(),
+			Fingerprint: GetFingerprint(key),
+			PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))),
+			Comment:     comment,
+		}
+		if len(hosts) == 1 && isPlainHostPattern(hosts[0]) {
+			knownHost.Hostname, knownHost.Port = splitHostPattern(hosts[0])
+		}
+
+		entryKey := knownHostKey(knownHost.Marker, knownHost.Pattern, knownHost.Fingerprint)
+		if seen[entryKey] {
+			result.Skipped++
+			continue
+		}
+		if err := repository.CreateKnownHost(knownHost); err != nil {
+			return result, err
+		}
+		seen[entryKey] = true
+		result.Imported++
+	}
+	return result, scanner.Err()
+}
+
+// ExportKnownHosts appends the stored entries missing from an OpenSSH
+// known_hosts file, creating it if needed
+func ExportKnownHosts(path string) (KnownHostsExport, error) {
+	var result KnownHostsExport
+
+	knownHostsList, err := repository.GetAllKnownHosts()
+	if err != nil {
+		return result, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+
+	present := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		marker, hosts, key, _, _, err := ssh.ParseKnownHosts(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		present[knownHostKey(types.KnownHostMarker(marker), strings.Join(hosts, ","), GetFingerprint(key))] = true
+	}
+
+	var lines strings.Builder
+	for i := range knownHostsList {
+		knownHost := &knownHostsList[i]
+		if knownHost.PublicKey == "" {
+			result.Skipped++
+			continue
+		}
+
+		pattern := knownHostPattern(knownHost)
+		if present[knownHostKey(knownHost.Marker, pattern, knownHost.Fingerprint)] {
+			result.Present++
+			continue
+		}
+
+		if knownHost.Marker != types.KnownHostPlain {
+			lines.WriteString("@" + string(knownHost.Marker) + " ")
+		}
+		lines.WriteString(pattern + " " + knownHost.PublicKey)
+		if knownHost.Comment != "" {
+			lines.WriteString(" " + knownHost.Comment)
+		}
+		lines.WriteString("\n")
+		result.Written++
+	}
+
+	if result.Written == 0 {
+		return result, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return result, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+
+	output := lines.String()
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		output = "\n" + output
+	}
+	if _, err := file.WriteString(output); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return result, nil
+}
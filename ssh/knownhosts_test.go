@@ -0,0 +1,213 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"yoru/models"
+	"yoru/repository"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func authorizedKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+// clearKnownHosts empties the known hosts of the test database before and
+// after a test
+func clearKnownHosts(t *testing.T) {
+	t.Helper()
+	clear := func() {
+		knownHosts, err := repository.GetAllKnownHosts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, knownHost := range knownHosts {
+			if err := repository.DeleteKnownHost(knownHost.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	clear()
+	t.Cleanup(clear)
+}
+
+func TestMatchesKnownHost(t *testing.T) {
+	hashed := knownhosts.HashHostname("secret.example.com")
+	hashedPort := knownhosts.HashHostname("[secret.example.com]:2222")
+
+	tests := []struct {
+		name     string
+		pattern  string
+		hostname string
+		port     int
+		want     bool
+	}{
+		{"plain", "example.com", "example.com", 22, true},
+		{"plain other host", "example.com", "example.org", 22, false},
+		{"plain other port", "example.com", "example.com", 2222, false},
+		{"port", "[example.com]:2222", "example.com", 2222, true},
+		{"port on default", "[example.com]:2222", "example.com", 22, false},
+		{"list", "web,db", "db", 22, true},
+		{"case", "Example.COM", "example.com", 22, true},
+		{"wildcard", "*.example.com", "web.example.com", 22, true},
+		{"wildcard other port", "*.example.com", "web.example.com", 2222, false},
+		{"star covers ports", "*", "web.example.com", 2222, true},
+		{"question mark", "web?", "web1", 22, true},
+		{"negated", "*.example.com,!db.example.com", "db.example.com", 22, false},
+		{"negated alone", "!db.example.com", "web.example.com", 22, false},
+		{"hashed", hashed, "secret.example.com", 22, true},
+		{"hashed other host", hashed, "other.example.com", 22, false},
+		{"hashed port", hashedPort, "secret.example.com", 2222, true},
+		{"ipv6", "[2001:db8::1]:2200", "2001:db8::1", 2200, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			knownHost := &models.KnownHost{Pattern: test.pattern}
+			if got := matchesKnownHost(knownHost, test.hostname, test.port); got != test.want {
+				t.Errorf("matchesKnownHost(%q, %q, %d) = %v, want %v", test.pattern, test.hostname, test.port, got, test.want)
+			}
+		})
+	}
+}
+
+func TestKnownHostsImportExport(t *testing.T) {
+	clearKnownHosts(t)
+
+	plain := newTestHostKey(t)
+	port := newTestHostKey(t)
+	hashed := newTestHostKey(t)
+	authority := newTestHostKey(t)
+	revoked := newTestHostKey(t)
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "known_hosts")
+	lines := []string{
+		"# a comment",
+		"",
+		"plain.example.com " + authorizedKey(plain) + " plain comment",
+		"[port.example.com]:2222 " + authorizedKey(port),
+		knownhosts.HashHostname("hashed.example.com") + " " + authorizedKey(hashed),
+		"@cert-authority *.example.com " + authorizedKey(authority),
+		"@revoked * " + authorizedKey(revoked),
+		"plain.example.com " + authorizedKey(plain) + " duplicate",
+		"broken.example.com ssh-ed25519 not-base64",
+		"@unknown-marker host.example.com " + authorizedKey(plain),
+	}
+	if err := os.WriteFile(source, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportKnownHosts(source)
+	if err != nil {
+		t.Fatalf("ImportKnownHosts() error = %v", err)
+	}
+	if want := (KnownHostsImport{Imported: 5, Skipped: 1, Invalid: 2}); imported != want {
+		t.Errorf("ImportKnownHosts() = %+v, want %+v", imported, want)
+	}
+
+	verifications := []struct {
+		name     string
+		hostname string
+		port     int
+		key      ssh.PublicKey
+		want     error
+	}{
+		{"plain", "plain.example.com", 22, plain, nil},
+		{"port", "port.example.com", 2222, port, nil},
+		{"hashed", "hashed.example.com", 22, hashed, nil},
+		{"changed", "plain.example.com", 22, hashed, ErrHostKeyChanged},
+		{"unknown", "new.example.com", 22, plain, ErrHostKeyUnknown},
+		{"revoked", "plain.example.com", 22, revoked, ErrHostKeyRevoked},
+	}
+	for _, test := range verifications {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := VerifyHostKey(test.hostname, test.port, test.key); !errors.Is(err, test.want) {
+				t.Errorf("VerifyHostKey(%q, %d) = %v, want %v", test.hostname, test.port, err, test.want)
+			}
+		})
+	}
+
+	// Importing again adds nothing
+	imported, err = ImportKnownHosts(source)
+	if err != nil {
+		t.Fatalf("ImportKnownHosts() again error = %v", err)
+	}
+	if want := (KnownHostsImport{Skipped: 6, Invalid: 2}); imported != want {
+		t.Errorf("ImportKnownHosts() again = %+v, want %+v", imported, want)
+	}
+
+	// Exporting to the source finds every entry already there
+	exported, err := ExportKnownHosts(source)
+	if err != nil {
+		t.Fatalf("ExportKnownHosts() error = %v", err)
+	}
+	if want := (KnownHostsExport{Present: 5}); exported != want {
+		t.Errorf("ExportKnownHosts() to the source = %+v, want %+v", exported, want)
+	}
+
+	// A new file gets every entry, which OpenSSH reads back the same way
+	target := filepath.Join(dir, "nested", "known_hosts")
+	exported, err = ExportKnownHosts(target)
+	if err != nil {
+		t.Fatalf("ExportKnownHosts() error = %v", err)
+	}
+	if want := (KnownHostsExport{Written: 5}); exported != want {
+		t.Errorf("ExportKnownHosts() to a new file = %+v, want %+v", exported, want)
+	}
+
+	callback, err := knownhosts.New(target)
+	if err != nil {
+		t.Fatalf("knownhosts.New() error = %v", err)
+	}
+	address := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 22}
+	if err := callback("plain.example.com:22", address, plain); err != nil {
+		t.Errorf("exported plain key not trusted: %v", err)
+	}
+	if err := callback("hashed.example.com:22", address, hashed); err != nil {
+		t.Errorf("exported hashed key not trusted: %v", err)
+	}
+	if err := callback("port.example.com:2222", address, port); err != nil {
+		t.Errorf("exported key on port 2222 not trusted: %v", err)
+	}
+	if err := callback("plain.example.com:22", address, revoked); err == nil {
+		t.Error("exported revoked key is trusted")
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), authorizedKey(plain)+" plain comment\n") {
+		t.Errorf("exported file lost the comment:\n%s", data)
+	}
+
+	exported, err = ExportKnownHosts(target)
+	if err != nil {
+		t.Fatalf("ExportKnownHosts() again error = %v", err)
+	}
+	if want := (KnownHostsExport{Present: 5}); exported != want {
+		t.Errorf("ExportKnownHosts() again = %+v, want %+v", exported, want)
+	}
+}
@@ -0,0 +1,461 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/ssh"
+)
+
+// PuTTY private key files, as documented in the PuTTY manual (appendix C).
+// Version 2 derives keys with SHA-1, version 3 with Argon2.
+
+var (
+	ErrInvalidPPK    = errors.New("invalid PuTTY key file")
+	ErrBadPassphrase = errors.New("incorrect passphrase")
+)
+
+const (
+	ppkHeaderPrefix  = "PuTTY-User-Key-File-"
+	ppkMACKeyPrefix  = "putty-private-key-file-mac-key"
+	ppkEncryptionAES = "aes256-cbc"
+
+	// Export parameters: PuTTY's default memory with a fixed pass count
+	// instead of its time-based calibration
+	ppkArgon2Memory      = 8192
+	ppkArgon2Passes      = 13
+	ppkArgon2Parallelism = 1
+
+	// Import limits, well above what puttygen writes, so a crafted file
+	// cannot exhaust memory or stall on key derivation
+	ppkMaxArgon2Memory = 1 << 18 // KiB, 256 MiB
+	ppkMaxArgon2Passes = 1000
+)
+
+// IsPPK reports whether data looks like a PuTTY private key file
+func IsPPK(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(ppkHeaderPrefix))
+}
+
+type ppkFile struct {
+	version    int
+	algorithm  string
+	encryption string
+	comment    string
+	public     []byte
+	private    []byte
+	mac        []byte
+	headers    map[string]string
+}
+
+func parsePPKFile(data []byte) (*ppkFile, error) {
+	file := &ppkFile{headers: make(map[string]string)}
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimSpace(data)))
+
+	readBlob := func(count string) ([]byte, error) {
+		lines, err := strconv.Atoi(count)
+		if err != nil || lines < 0 || lines > 1024 {
+			return nil, ErrInvalidPPK
+		}
+		var encoded strings.Builder
+		for range lines {
+			if !scanner.Scan() {
+				return nil, ErrInvalidPPK
+			}
+			encoded.WriteString(strings.TrimSpace(scanner.Text()))
+		}
+		return base64.StdEncoding.DecodeString(encoded.String())
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, ErrInvalidPPK
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(name, ppkHeaderPrefix):
+			file.version, err = strconv.Atoi(strings.TrimPrefix(name, ppkHeaderPrefix))
+			file.algorithm = value
+		case name == "Encryption":
+			file.encryption = value
+		case name == "Comment":
+			file.comment = value
+		case name == "Public-Lines":
+			file.public, err = readBlob(value)
+		case name == "Private-Lines":
+			file.private, err = readBlob(value)
+		case name == "Private-MAC":
+			file.mac, err = hex.DecodeString(value)
+		default:
+			file.headers[name] = value
+		}
+		if err != nil {
+			return nil, ErrInvalidPPK
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if file.version != 2 && file.version != 3 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidPPK, file.version)
+	}
+	if file.public == nil || file.private == nil || file.mac == nil {
+		return nil, ErrInvalidPPK
+	}
+	return file, nil
+}
+
+// ppkKeys derives the cipher key, IV and MAC key of a file
+func (file *ppkFile) ppkKeys(passphrase string) (cipherKey, iv, macKey []byte, err error) {
+	if file.version == 2 {
+		macHash := sha1.Sum([]byte(ppkMACKeyPrefix + passphrase))
+		if file.encryption == "none" {
+			return nil, nil, macHash[:], nil
+		}
+		var key []byte
+		for i := range uint32(2) {
+			digest := sha1.New()
+			binary.Write(digest, binary.BigEndian, i)
+			digest.Write([]byte(passphrase))
+			key = digest.Sum(key)
+		}
+		return key[:32], make([]byte, aes.BlockSize), macHash[:], nil
+	}
+
+	if file.encryption == "none" {
+		return nil, nil, []byte{}, nil
+	}
+
+	memory, err1 := strconv.ParseUint(file.headers["Argon2-Memory"], 10, 32)
+	passes, err2 := strconv.ParseUint(file.headers["Argon2-Passes"], 10, 32)
+	parallelism, err3 := strconv.ParseUint(file.headers["Argon2-Parallelism"], 10, 8)
+	salt, err4 := hex.DecodeString(file.headers["Argon2-Salt"])
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return nil, nil, nil, ErrInvalidPPK
+	}
+	if memory == 0 || memory > ppkMaxArgon2Memory || passes == 0 || passes > ppkMaxArgon2Passes || parallelism == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: Argon2 parameters out of range", ErrInvalidPPK)
+	}
+
+	var derived []byte
+	switch file.headers["Key-Derivation"] {
+	case "Argon2id":
+		derived = argon2.IDKey([]byte(passphrase), salt, uint32(passes), uint32(memory), uint8(parallelism), 80)
+	case "Argon2i":
+		derived = argon2.Key([]byte(passphrase), salt, uint32(passes), uint32(memory), uint8(parallelism), 80)
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: unsupported key derivation %q", ErrInvalidPPK, file.headers["Key-Derivation"])
+	}
+	return derived[:32], derived[32:48], derived[48:], nil
+}
+
+func (file *ppkFile) macHash() func() hash.Hash {
+	if file.version == 2 {
+		return sha1.New
+	}
+	return sha256.New
+}
+
+// ppkMAC authenticates the algorithm, encryption, comment and both blobs
+func ppkMAC(newHash func() hash.Hash, macKey []byte, algorithm, encryption, comment string, public, private []byte) []byte {
+	mac := hmac.New(newHash, macKey)
+	for _, field := range [][]byte{[]byte(algorithm), []byte(encryption), []byte(comment), public, private} {
+		binary.Write(mac, binary.BigEndian, uint32(len(field)))
+		mac.Write(field)
+	}
+	return mac.Sum(nil)
+}
+
+// ParsePPK decodes a PuTTY private key (versions 2 and 3), returning the
+// raw private key and its comment. passphrase is ignored for unencrypted
+// files; ErrKeyEncrypted is returned when an encrypted file has none.
+func ParsePPK(data []byte, passphrase string) (crypto.Signer, string, error) {
+	file, err := parsePPKFile(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch file.encryption {
+	case "none":
+		passphrase = ""
+	case ppkEncryptionAES:
+		if passphrase == "" {
+			return nil, "", ErrKeyEncrypted
+		}
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported encryption %q", ErrInvalidPPK, file.encryption)
+	}
+
+	cipherKey, iv, macKey, err := file.ppkKeys(passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+
+	private := file.private
+	if cipherKey != nil {
+		if len(private)%aes.BlockSize != 0 {
+			return nil, "", ErrInvalidPPK
+		}
+		block, err := aes.NewCipher(cipherKey)
+		if err != nil {
+			return nil, "", err
+		}
+		private = make([]byte, len(file.private))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(private, file.private)
+	}
+
+	expected := ppkMAC(file.macHash(), macKey, file.algorithm, file.encryption, file.comment, file.public, private)
+	if !hmac.Equal(expected, file.mac) {
+		if passphrase != "" {
+			return nil, "", ErrBadPassphrase
+		}
+		return nil, "", fmt.Errorf("%w: MAC mismatch", ErrInvalidPPK)
+	}
+
+	key, err := decodePPKKey(file.algorithm, file.public, private)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, file.comment, nil
+}
+
+func decodePPKKey(algorithm string, publicBlob, privateBlob []byte) (crypto.Signer, error) {
+	public := &wireReader{data: publicBlob}
+	private := &wireReader{data: privateBlob}
+	if string(public.string()) != algorithm {
+		return nil, fmt.Errorf("%w: public key does not match the algorithm", ErrInvalidPPK)
+	}
+
+	switch algorithm {
+	case ssh.KeyAlgoRSA:
+		e, n := public.mpint(), public.mpint()
+		d, p, q, _ := private.mpint(), private.mpint(), private.mpint(), private.mpint()
+		if public.err != nil || private.err != nil || !e.IsInt64() {
+			return nil, ErrInvalidPPK
+		}
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPPK, err)
+		}
+		key.Precompute()
+		return key, nil
+
+	case ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521:
+		curve := map[string]elliptic.Curve{
+			ssh.KeyAlgoECDSA256: elliptic.P256(),
+			ssh.KeyAlgoECDSA384: elliptic.P384(),
+			ssh.KeyAlgoECDSA521: elliptic.P521(),
+		}[algorithm]
+		public.string() // curve name
+		point := public.string()
+		d := private.mpint()
+		if public.err != nil || private.err != nil {
+			return nil, ErrInvalidPPK
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(d.Bytes()) > size {
+			return nil, ErrInvalidPPK
+		}
+		key, err := ecdsa.ParseRawPrivateKey(curve, d.FillBytes(make([]byte, size)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPPK, err)
+		}
+		if derived, err := key.PublicKey.Bytes(); err != nil || !bytes.Equal(derived, point) {
+			return nil, fmt.Errorf("%w: private key does not match the public key", ErrInvalidPPK)
+		}
+		return key, nil
+
+	case ssh.KeyAlgoED25519:
+		point := public.string()
+		seed := private.string()
+		if public.err != nil || private.err != nil || len(seed) != ed25519.SeedSize {
+			return nil, ErrInvalidPPK
+		}
+		key := ed25519.NewKeyFromSeed(seed)
+		if !bytes.Equal(key.Public().(ed25519.PublicKey), point) {
+			return nil, fmt.Errorf("%w: private key does not match the public key", ErrInvalidPPK)
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyType, algorithm)
+}
+
+// MarshalPPK encodes a private key as a version 3 PuTTY key file, encrypted
+// with Argon2id and AES-256-CBC when passphrase is set
+func MarshalPPK(key crypto.Signer, comment, passphrase string) ([]byte, error) {
+	sshPublic, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	public := sshPublic.Marshal()
+
+	var private wireWriter
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if len(key.Primes) != 2 {
+			return nil, fmt.Errorf("%w: multi-prime RSA", ErrUnsupportedKeyType)
+		}
+		key.Precompute()
+		private.mpint(key.D)
+		private.mpint(key.Primes[0])
+		private.mpint(key.Primes[1])
+		private.mpint(key.Precomputed.Qinv)
+	case *ecdsa.PrivateKey:
+		private.mpint(key.D)
+	case ed25519.PrivateKey:
+		private.string(key.Seed())
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+	}
+
+	file := &ppkFile{
+		version:    3,
+		algorithm:  sshPublic.Type(),
+		encryption: "none",
+		comment:    comment,
+		public:     public,
+		private:    private.data,
+		headers:    make(map[string]string),
+	}
+
+	if passphrase != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		file.encryption = ppkEncryptionAES
+		file.headers["Key-Derivation"] = "Argon2id"
+		file.headers["Argon2-Memory"] = strconv.Itoa(ppkArgon2Memory)
+		file.headers["Argon2-Passes"] = strconv.Itoa(ppkArgon2Passes)
+		file.headers["Argon2-Parallelism"] = strconv.Itoa(ppkArgon2Parallelism)
+		file.headers["Argon2-Salt"] = hex.EncodeToString(salt)
+
+		// Random padding up to the cipher block size, as PuTTY writes it
+		padding := make([]byte, (aes.BlockSize-len(file.private)%aes.BlockSize)%aes.BlockSize)
+		if _, err := rand.Read(padding); err != nil {
+			return nil, err
+		}
+		file.private = append(file.private, padding...)
+	}
+
+	cipherKey, iv, macKey, err := file.ppkKeys(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	mac := ppkMAC(file.macHash(), macKey, file.algorithm, file.encryption, file.comment, file.public, file.private)
+
+	encrypted := file.private
+	if cipherKey != nil {
+		block, err := aes.NewCipher(cipherKey)
+		if err != nil {
+			return nil, err
+		}
+		encrypted = make([]byte, len(file.private))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, file.private)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s3: %s\n", ppkHeaderPrefix, file.algorithm)
+	fmt.Fprintf(&out, "Encryption: %s\n", file.encryption)
+	fmt.Fprintf(&out, "Comment: %s\n", file.comment)
+	writePPKBlob(&out, "Public-Lines", file.public)
+	if passphrase != "" {
+		for _, name := range []string{"Key-Derivation", "Argon2-Memory", "Argon2-Passes", "Argon2-Parallelism", "Argon2-Salt"} {
+			fmt.Fprintf(&out, "%s: %s\n", name, file.headers[name])
+		}
+	}
+	writePPKBlob(&out, "Private-Lines", encrypted)
+	fmt.Fprintf(&out, "Private-MAC: %s\n", hex.EncodeToString(mac))
+	return out.Bytes(), nil
+}
+
+// writePPKBlob writes base64 data in lines of 64 characters
+func writePPKBlob(out *bytes.Buffer, name string, blob []byte) {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	lines := (len(encoded) + 63) / 64
+	fmt.Fprintf(out, "%s: %d\n", name, lines)
+	for i := 0; i < len(encoded); i += 64 {
+		out.WriteString(encoded[i:min(i+64, len(encoded))] + "\n")
+	}
+}
+
+// wireReader reads SSH wire format strings and mpints, remembering the
+// first error so callers can check once
+type wireReader struct {
+	data []byte
+	err  error
+}
+
+func (reader *wireReader) string() []byte {
+	if reader.err != nil {
+		return nil
+	}
+	if len(reader.data) < 4 {
+		reader.err = ErrInvalidPPK
+		return nil
+	}
+	length := binary.BigEndian.Uint32(reader.data)
+	if uint64(length) > uint64(len(reader.data)-4) {
+		reader.err = ErrInvalidPPK
+		return nil
+	}
+	value := reader.data[4 : 4+length]
+	reader.data = reader.data[4+length:]
+	return value
+}
+
+func (reader *wireReader) mpint() *big.Int {
+	return new(big.Int).SetBytes(reader.string())
+}
+
+type wireWriter struct {
+	data []byte
+}
+
+func (writer *wireWriter) string(value []byte) {
+	writer.data = binary.BigEndian.AppendUint32(writer.data, uint32(len(value)))
+	writer.data = append(writer.data, value...)
+}
+
+// mpint writes a positive integer, with a leading zero byte when the top
+// bit is set
+func (writer *wireWriter) mpint(value *big.Int) {
+	encoded := value.Bytes()
+	if len(encoded) > 0 && encoded[0]&0x80 != 0 {
+		encoded = append([]byte{0}, encoded...)
+	}
+	writer.string(encoded)
+}
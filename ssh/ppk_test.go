@@ -0,0 +1,125 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newTestSigners(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	signers := make(map[string]crypto.Signer)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers["ed25519"] = ed25519Key
+
+	for name, curve := range map[string]elliptic.Curve{"p256": elliptic.P256(), "p384": elliptic.P384(), "p521": elliptic.P521()} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers["ecdsa "+name] = key
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers["rsa"] = rsaKey
+	return signers
+}
+
+func TestPPKRoundTrip(t *testing.T) {
+	for name, signer := range newTestSigners(t) {
+		for _, passphrase := range []string{"", "correct horse"} {
+			t.Run(name+" passphrase="+passphrase, func(t *testing.T) {
+				data, err := MarshalPPK(signer, "user@example.com", passphrase)
+				if err != nil {
+					t.Fatalf("MarshalPPK() error = %v", err)
+				}
+				if !IsPPK(data) {
+					t.Fatalf("IsPPK() = false for:\n%s", data)
+				}
+
+				key, comment, err := ParsePPK(data, passphrase)
+				if err != nil {
+					t.Fatalf("ParsePPK() error = %v", err)
+				}
+				if comment != "user@example.com" {
+					t.Errorf("comment = %q", comment)
+				}
+				public := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+				if !public.Equal(signer.Public()) {
+					t.Error("parsed key does not match the marshalled one")
+				}
+
+				if passphrase == "" {
+					return
+				}
+				if _, _, err := ParsePPK(data, ""); !errors.Is(err, ErrKeyEncrypted) {
+					t.Errorf("ParsePPK() without passphrase = %v, want %v", err, ErrKeyEncrypted)
+				}
+				if _, _, err := ParsePPK(data, "wrong"); !errors.Is(err, ErrBadPassphrase) {
+					t.Errorf("ParsePPK() with a wrong passphrase = %v, want %v", err, ErrBadPassphrase)
+				}
+			})
+		}
+	}
+}
+
+func TestParsePPKInvalid(t *testing.T) {
+	_, signer, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := MarshalPPK(signer, "comment", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := MarshalPPK(signer, "comment", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replaceHeader := func(data []byte, name, value string) string {
+		return regexp.MustCompile("(?m)^"+name+": .*$").ReplaceAllString(string(data), name+": "+value)
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		passphrase string
+	}{
+		{"version 1", strings.Replace(string(plain), "PuTTY-User-Key-File-3", "PuTTY-User-Key-File-1", 1), ""},
+		{"no MAC", string(plain)[:strings.Index(string(plain), "Private-MAC")], ""},
+		{"tampered comment", replaceHeader(plain, "Comment", "other"), ""},
+		{"bad line count", replaceHeader(plain, "Public-Lines", "-1"), ""},
+		{"unknown encryption", replaceHeader(encrypted, "Encryption", "des-cbc"), "secret"},
+		{"unknown key derivation", replaceHeader(encrypted, "Key-Derivation", "scrypt"), "secret"},
+		{"no Argon2 memory", replaceHeader(encrypted, "Argon2-Memory", "0"), "secret"},
+		{"huge Argon2 memory", replaceHeader(encrypted, "Argon2-Memory", "4294967295"), "secret"},
+		{"no Argon2 passes", replaceHeader(encrypted, "Argon2-Passes", "0"), "secret"},
+		{"too many Argon2 passes", replaceHeader(encrypted, "Argon2-Passes", "100000"), "secret"},
+		{"no Argon2 parallelism", replaceHeader(encrypted, "Argon2-Parallelism", "0"), "secret"},
+		{"Argon2 parallelism overflow", replaceHeader(encrypted, "Argon2-Parallelism", "256"), "secret"},
+		{"bad salt", replaceHeader(encrypted, "Argon2-Salt", "zz"), "secret"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := ParsePPK([]byte(test.data), test.passphrase); !errors.Is(err, ErrInvalidPPK) {
+				t.Errorf("ParsePPK() = %v, want %v", err, ErrInvalidPPK)
+			}
+		})
+	}
+}
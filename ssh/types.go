@@ -2,6 +2,7 @@ package ssh
 
 import (
 	"io"
+	"sync"
 	"yoru/models"
 
 	"golang.org/x/crypto/ssh"
@@ -21,6 +22,7 @@ const (
 
 // client is the SSH client wrapper
 type client struct {
+	sessionID      uint
 	host           *models.Host
 	credential     any // *models.Identity or *models.Key
 	sshClient      *ssh.Client
@@ -39,4 +41,9 @@ type client struct {
 
 	// host key verification: receives true to save, false to skip
 	hostKeyDecision chan bool
+	hostKey         ssh.PublicKey // accepted on the first key exchange
+
+	// closed is closed with the client, to stop waiting on the user
+	closed    chan struct{}
+	closeOnce sync.Once
 }
\ No newline at end of file
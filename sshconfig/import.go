@@ -0,0 +1,260 @@
+package sshconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"yoru/models"
+	"yoru/repository"
+	"yoru/types"
+	"yoru/utils/network"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type ConflictAction int
+
+const (
+	ActionSkip ConflictAction = iota
+	ActionReplace
+	ActionRename
+)
+
+func (action ConflictAction) String() string {
+	switch action {
+	case ActionReplace:
+		return "replace"
+	case ActionRename:
+		return "rename"
+	default:
+		return "skip"
+	}
+}
+
+// Candidate is an entry proposed for import. Existing is set when a saved
+// host has the same name or address, in which case Action decides what
+// happens to it.
+type Candidate struct {
+	Entry    Entry
+	Existing *models.Host
+	Action   ConflictAction
+	Selected bool
+}
+
+// Result summarises an import
+type Result struct {
+	Created     int
+	Updated     int
+	Skipped     int
+	KeysCreated int
+	Warnings    []string
+}
+
+// Plan matches parsed entries against the saved hosts. Entries without a
+// conflict are selected; conflicting ones default to being skipped.
+func Plan(entries []Entry) ([]Candidate, error) {
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(entries))
+	for _, entry := range entries {
+		candidate := Candidate{Entry: entry, Selected: true}
+		for i := range hosts {
+			if strings.EqualFold(hosts[i].Name, entry.Alias) ||
+				(strings.EqualFold(hosts[i].Hostname, entry.HostName) && hosts[i].Port == entry.Port) {
+				candidate.Existing = &hosts[i]
+				break
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+type importer struct {
+	names  map[string]bool
+	keys   []models.Key
+	cached map[string]*models.Key
+	result Result
+}
+
+// Apply writes the selected candidates to the database, creating keys for
+// readable identity files along the way
+func Apply(candidates []Candidate) (Result, error) {
+	hosts, err := repository.GetAllHosts()
+	if err != nil {
+		return Result{}, err
+	}
+	keys, err := repository.GetAllKeys()
+	if err != nil {
+		return Result{}, err
+	}
+
+	imp := &importer{
+		names:  make(map[string]bool, len(hosts)),
+		keys:   keys,
+		cached: make(map[string]*models.Key),
+	}
+	for _, host := range hosts {
+		imp.names[strings.ToLower(host.Name)] = true
+	}
+
+	for _, candidate := range candidates {
+		if !candidate.Selected || (candidate.Existing != nil && candidate.Action == ActionSkip) {
+			imp.result.Skipped++
+			continue
+		}
+		if reason := unsupported(candidate.Entry); reason != "" {
+			imp.warn("%s: %s, not imported", candidate.Entry.Alias, reason)
+			imp.result.Skipped++
+			continue
+		}
+		if err := imp.importHost(candidate); err != nil {
+			return imp.result, err
+		}
+	}
+	return imp.result, nil
+}
+
+// unsupported returns why an entry cannot be connected to as configured,
+// or "" when it can
+func unsupported(entry Entry) string {
+	if err := network.ValidateHostname(entry.HostName); err != nil {
+		return fmt.Sprintf("invalid HostName %q: %v", entry.HostName, err)
+	}
+	if entry.ProxyJump != "" {
+		return "connects through ProxyJump " + entry.ProxyJump + ", which is not supported"
+	}
+	return ""
+}
+
+func (imp *importer) importHost(candidate Candidate) error {
+	entry := candidate.Entry
+
+	host := &models.Host{Mode: types.ModeSSH}
+	replacing := candidate.Existing != nil && candidate.Action == ActionReplace
+	if replacing {
+		host = candidate.Existing
+	}
+
+	host.Name = entry.Alias
+	if candidate.Existing != nil && candidate.Action == ActionRename {
+		host.Name = imp.uniqueName(entry.Alias)
+	}
+	host.Hostname = network.NormalizeHostname(entry.HostName)
+	host.Port = entry.Port
+	host.Mode = types.ModeSSH
+	host.User = entry.User
+	if len(entry.LocalForwards) > 0 {
+		imp.warn("%s: imported without LocalForward %s, which is not supported", entry.Alias, strings.Join(entry.LocalForwards, ", "))
+	}
+
+	if key := imp.keyFor(entry); key != nil {
+		host.CredentialType = types.CredentialKey
+		host.CredentialID = key.ID
+	} else if !replacing {
+		host.CredentialType = ""
+		host.CredentialID = 0
+		if entry.User != "" {
+			imp.warn("%s: no readable identity file, choose a credential to log in as %s", entry.Alias, entry.User)
+		}
+	}
+
+	if replacing {
+		if err := repository.UpdateHost(host); err != nil {
+			return err
+		}
+		imp.result.Updated++
+	} else {
+		if err := repository.CreateHost(host); err != nil {
+			return err
+		}
+		imp.result.Created++
+	}
+	imp.names[strings.ToLower(host.Name)] = true
+	return nil
+}
+
+// keyFor returns a key for the first readable identity file of an entry,
+// reusing a saved key with the same private key and username
+func (imp *importer) keyFor(entry Entry) *models.Key {
+	for _, path := range entry.IdentityFiles {
+		cacheKey := path + "\x00" + entry.User
+		if key, ok := imp.cached[cacheKey]; ok {
+			if key != nil {
+				return key
+			}
+			continue
+		}
+
+		key, err := imp.importKey(path, entry.User)
+		if err != nil {
+			imp.warn("%s: %v", entry.Alias, err)
+		}
+		imp.cached[cacheKey] = key
+		if key != nil {
+			return key
+		}
+	}
+	return nil
+}
+
+func (imp *importer) importKey(path, username string) (*models.Key, error) {
+	privateKey, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	var passphraseErr *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseErr) {
+		// Saved keys are used without prompting, so they are stored unencrypted
+		return nil, fmt.Errorf("%s is encrypted, import it from the keychain with its passphrase", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	for i := range imp.keys {
+		if imp.keys[i].PrivateKey == string(privateKey) && imp.keys[i].Username == username {
+			return &imp.keys[i], nil
+		}
+	}
+
+	key := &models.Key{
+		Name:       filepath.Base(path),
+		Username:   username,
+		PrivateKey: string(privateKey),
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+	}
+	// OpenSSH loads <key>-cert.pub alongside the key when it exists
+	if data, err := os.ReadFile(path + "-cert.pub"); err == nil {
+		key.Certificate = strings.TrimSpace(string(data))
+	}
+
+	if err := repository.CreateKey(key); err != nil {
+		return nil, err
+	}
+	imp.keys = append(imp.keys, *key)
+	imp.result.KeysCreated++
+	return key, nil
+}
+
+func (imp *importer) uniqueName(name string) string {
+	candidate := name
+	for i := 2; imp.names[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return candidate
+}
+
+func (imp *importer) warn(format string, args ...any) {
+	imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,27 @@
+package sshconfig
+
+import "testing"
+
+func TestUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    Entry
+		imported bool
+	}{
+		{"plain", Entry{Alias: "web", HostName: "web.example.com"}, true},
+		{"ipv6", Entry{Alias: "v6", HostName: "2001:db8::1"}, true},
+		{"local forwards", Entry{Alias: "db", HostName: "db", LocalForwards: []string{"5432 localhost:5432"}}, true},
+		{"jump host", Entry{Alias: "inner", HostName: "inner", ProxyJump: "bastion"}, false},
+		{"invalid hostname", Entry{Alias: "bad", HostName: "bad_host"}, false},
+		{"unexpanded token", Entry{Alias: "tok", HostName: "%x.example.com"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason := unsupported(test.entry)
+			if (reason == "") != test.imported {
+				t.Errorf("unsupported(%+v) = %q", test.entry, reason)
+			}
+		})
+	}
+}
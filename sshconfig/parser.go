@@ -0,0 +1,343 @@
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth matches the recursion limit of OpenSSH's readconf.c
+const maxIncludeDepth = 16
+
+// Entry is a concrete host alias from an ssh config with its effective settings
+type Entry struct {
+	Alias         string
+	HostName      string
+	Port          int
+	User          string
+	IdentityFiles []string
+	ProxyJump     string
+	LocalForwards []string
+	Source        string
+}
+
+type block struct {
+	patterns []string
+	options  map[string][]string
+	order    []string
+	isMatch  bool
+	source   string
+}
+
+type parser struct {
+	sshDir string
+	blocks []*block
+	seen   map[string]bool
+}
+
+// DefaultPath returns the location of the current user's ssh client config
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "config")
+}
+
+// Parse reads an ssh client config and its Include files and returns one
+// entry per concrete Host alias. Options are resolved as OpenSSH does: the
+// first value obtained for a keyword wins, across every matching block.
+func Parse(configPath string) ([]Entry, error) {
+	p := &parser{
+		sshDir: filepath.Dir(configPath),
+		seen:   make(map[string]bool),
+	}
+	// The implicit first block holds options given before any Host line
+	p.blocks = append(p.blocks, &block{patterns: []string{"*"}, options: make(map[string][]string)})
+
+	if err := p.parseFile(configPath, 0); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	aliases := make(map[string]bool)
+	for _, b := range p.blocks {
+		if b.isMatch {
+			continue
+		}
+		for _, alias := range b.patterns {
+			if aliases[alias] || isPattern(alias) {
+				continue
+			}
+			aliases[alias] = true
+			entries = append(entries, p.resolve(alias, b.source))
+		}
+	}
+	return entries, nil
+}
+
+func (p *parser) parseFile(filePath string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("include nested too deeply at %s", filePath)
+	}
+	if p.seen[filePath] {
+		return nil
+	}
+	p.seen[filePath] = true
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	current := p.blocks[len(p.blocks)-1]
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		keyword, args, err := splitLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+		}
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+		case "host":
+			current = &block{patterns: args, options: make(map[string][]string), source: filePath}
+			p.blocks = append(p.blocks, current)
+		case "match":
+			// Match criteria depend on runtime state we cannot evaluate here
+			current = &block{isMatch: true, options: make(map[string][]string), source: filePath}
+			p.blocks = append(p.blocks, current)
+		case "include":
+			enclosing := current
+			for _, pattern := range args {
+				if err := p.include(pattern, depth); err != nil {
+					return fmt.Errorf("%s:%d: %w", filePath, lineNumber, err)
+				}
+			}
+			// The lines after an Include belong to the enclosing block again,
+			// as in OpenSSH. When the included files opened blocks of their
+			// own, a copy of it keeps those lines after their options.
+			current = enclosing
+			if p.blocks[len(p.blocks)-1] != enclosing {
+				current = &block{
+					patterns: enclosing.patterns,
+					options:  make(map[string][]string),
+					isMatch:  enclosing.isMatch,
+					source:   enclosing.source,
+				}
+				p.blocks = append(p.blocks, current)
+			}
+		default:
+			if len(args) == 0 {
+				continue
+			}
+			if _, ok := current.options[keyword]; !ok {
+				current.order = append(current.order, keyword)
+			}
+			current.options[keyword] = append(current.options[keyword], strings.Join(args, " "))
+		}
+	}
+	return scanner.Err()
+}
+
+func (p *parser) include(pattern string, depth int) error {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(p.sshDir, pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		if err := p.parseFile(match, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) resolve(alias string, source string) Entry {
+	entry := Entry{Alias: alias, Source: source}
+	values := make(map[string]string)
+
+	for _, b := range p.blocks {
+		if b.isMatch || !matchesHost(alias, b.patterns) {
+			continue
+		}
+		for _, keyword := range b.order {
+			switch keyword {
+			case "identityfile":
+				entry.IdentityFiles = append(entry.IdentityFiles, b.options[keyword]...)
+			case "localforward":
+				entry.LocalForwards = append(entry.LocalForwards, b.options[keyword]...)
+			default:
+				if _, ok := values[keyword]; !ok {
+					values[keyword] = b.options[keyword][0]
+				}
+			}
+		}
+	}
+
+	entry.HostName = values["hostname"]
+	if entry.HostName == "" {
+		entry.HostName = alias
+	}
+	entry.HostName = expandTokens(entry.HostName, alias, "", "")
+
+	entry.Port = 22
+	if port, err := strconv.Atoi(values["port"]); err == nil && port > 0 && port <= 65535 {
+		entry.Port = port
+	}
+
+	entry.User = values["user"]
+	if proxyJump := values["proxyjump"]; !strings.EqualFold(proxyJump, "none") {
+		entry.ProxyJump = proxyJump
+	}
+
+	for i, identityFile := range entry.IdentityFiles {
+		entry.IdentityFiles[i] = expandTokens(expandHome(identityFile), alias, entry.HostName, entry.User)
+	}
+
+	return entry
+}
+
+// splitLine returns the lowercased keyword and its arguments, honouring
+// quotes and the "Keyword=value" form
+func splitLine(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil, nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+
+	var args []string
+	var current strings.Builder
+	inQuotes := false
+	hasArg := false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		case r == '#' && !inQuotes && !hasArg:
+			return keyword, args, nil
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if inQuotes {
+		return "", nil, fmt.Errorf("unterminated quote")
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return keyword, args, nil
+}
+
+func isPattern(alias string) bool {
+	return strings.ContainsAny(alias, "*?!")
+}
+
+// matchesHost applies a Host line: any positive pattern must match and no
+// negated pattern may match
+func matchesHost(alias string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(alias))
+		if ok && negated {
+			return false
+		}
+		if ok {
+			matched = true
+		}
+	}
+	return matched
+}
+
+func expandHome(value string) string {
+	if value != "~" && !strings.HasPrefix(value, "~/") {
+		return value
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return value
+	}
+	return filepath.Join(home, strings.TrimPrefix(value, "~"))
+}
+
+// expandTokens substitutes the ssh_config TOKENS used in paths and host names
+func expandTokens(value, alias, hostName, remoteUser string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+
+	home, _ := os.UserHomeDir()
+	localUser := ""
+	if current, err := user.Current(); err == nil {
+		localUser = current.Username
+	}
+	if remoteUser == "" {
+		remoteUser = localUser
+	}
+	if hostName == "" {
+		hostName = alias
+	}
+
+	var expanded strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 == len(value) {
+			expanded.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case '%':
+			expanded.WriteByte('%')
+		case 'd':
+			expanded.WriteString(home)
+		case 'h':
+			expanded.WriteString(hostName)
+		case 'n':
+			expanded.WriteString(alias)
+		case 'r':
+			expanded.WriteString(remoteUser)
+		case 'u':
+			expanded.WriteString(localUser)
+		default:
+			expanded.WriteByte('%')
+			expanded.WriteByte(value[i])
+		}
+	}
+	return expanded.String()
+}
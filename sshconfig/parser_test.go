@@ -0,0 +1,270 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		keyword string
+		args    []string
+		wantErr bool
+	}{
+		{"", "", nil, false},
+		{"   # comment", "", nil, false},
+		{"HostName example.com", "hostname", []string{"example.com"}, false},
+		{"Port=2222", "port", []string{"2222"}, false},
+		{"Port = 2222", "port", []string{"2222"}, false},
+		{"\tUser\tdeploy  # trailing comment", "user", []string{"deploy"}, false},
+		{`IdentityFile "/keys/my key"`, "identityfile", []string{"/keys/my key"}, false},
+		{"Host web db *.internal", "host", []string{"web", "db", "*.internal"}, false},
+		{"Compression", "compression", nil, false},
+		{`IdentityFile "/keys/open`, "", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			keyword, args, err := splitLine(test.line)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("splitLine(%q) error = %v", test.line, err)
+			}
+			if keyword != test.keyword || !reflect.DeepEqual(args, test.args) {
+				t.Errorf("splitLine(%q) = %q, %q, want %q, %q", test.line, keyword, args, test.keyword, test.args)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Entry
+	}{
+		{
+			name: "defaults",
+			files: map[string]string{"config": `
+Host web
+`},
+			want: []Entry{{Alias: "web", HostName: "web", Port: 22}},
+		},
+		{
+			name: "first value wins",
+			files: map[string]string{"config": `
+Host web
+    HostName web.example.com
+    User deploy
+    Port 2222
+
+Host *
+    User root
+    Port 22
+    IdentityFile /keys/default
+`},
+			want: []Entry{{
+				Alias:         "web",
+				HostName:      "web.example.com",
+				Port:          2222,
+				User:          "deploy",
+				IdentityFiles: []string{"/keys/default"},
+			}},
+		},
+		{
+			name: "options before any host apply to all",
+			files: map[string]string{"config": `
+User admin
+Host a b
+    Port 2200
+`},
+			want: []Entry{
+				{Alias: "a", HostName: "a", Port: 2200, User: "admin"},
+				{Alias: "b", HostName: "b", Port: 2200, User: "admin"},
+			},
+		},
+		{
+			name: "patterns and negation",
+			files: map[string]string{"config": `
+Host *.internal !bastion.internal
+    User ops
+Host db.internal bastion.internal
+Host *
+`},
+			want: []Entry{
+				{Alias: "db.internal", HostName: "db.internal", Port: 22, User: "ops"},
+				{Alias: "bastion.internal", HostName: "bastion.internal", Port: 22},
+			},
+		},
+		{
+			name: "identity files accumulate and expand tokens",
+			files: map[string]string{"config": `
+Host git
+    HostName github.com
+    User git
+    IdentityFile /keys/%n
+Host *
+    IdentityFile /keys/%r@%h
+`},
+			want: []Entry{{
+				Alias:         "git",
+				HostName:      "github.com",
+				Port:          22,
+				User:          "git",
+				IdentityFiles: []string{"/keys/git", "/keys/git@github.com"},
+			}},
+		},
+		{
+			name: "jump hosts and forwards",
+			files: map[string]string{"config": `
+Host inner
+    ProxyJump bastion
+    LocalForward 8080 localhost:80
+    LocalForward 5432 db:5432
+Host direct
+    ProxyJump none
+`},
+			want: []Entry{
+				{Alias: "inner", HostName: "inner", Port: 22, ProxyJump: "bastion", LocalForwards: []string{"8080 localhost:80", "5432 db:5432"}},
+				{Alias: "direct", HostName: "direct", Port: 22},
+			},
+		},
+		{
+			name: "invalid port falls back",
+			files: map[string]string{"config": `
+Host web
+    Port 70000
+`},
+			want: []Entry{{Alias: "web", HostName: "web", Port: 22}},
+		},
+		{
+			name: "match blocks are skipped",
+			files: map[string]string{"config": `
+Match user root
+    Port 2022
+Host web
+`},
+			want: []Entry{{Alias: "web", HostName: "web", Port: 22}},
+		},
+		{
+			name: "include",
+			files: map[string]string{
+				"config": `
+Include conf.d/*
+Host web
+    User deploy
+`,
+				"conf.d/db": `
+Host db
+    HostName 10.0.0.5
+`,
+			},
+			want: []Entry{
+				{Alias: "db", HostName: "10.0.0.5", Port: 22, Source: "conf.d/db"},
+				{Alias: "web", HostName: "web", Port: 22, User: "deploy"},
+			},
+		},
+		{
+			name: "options after a top level include",
+			files: map[string]string{
+				"config": `
+Include inc
+User globaluser
+Host web
+`,
+				"inc": `
+Host db
+    Port 2200
+`,
+			},
+			want: []Entry{
+				{Alias: "db", HostName: "db", Port: 2200, User: "globaluser", Source: "inc"},
+				{Alias: "web", HostName: "web", Port: 22, User: "globaluser"},
+			},
+		},
+		{
+			name: "options after an include inside a host",
+			files: map[string]string{
+				"config": `
+Host web
+    Include inc
+    User deploy
+Host *
+    User root
+`,
+				"inc": `
+Port 2200
+Host db
+    User dbadmin
+`,
+			},
+			want: []Entry{
+				{Alias: "web", HostName: "web", Port: 2200, User: "deploy"},
+				{Alias: "db", HostName: "db", Port: 22, User: "dbadmin", Source: "inc"},
+			},
+		},
+		{
+			name: "include loop",
+			files: map[string]string{
+				"config": `
+Include other
+Host web
+`,
+				"other": `
+Include config
+Host db
+`,
+			},
+			want: []Entry{
+				{Alias: "db", HostName: "db", Port: 22, Source: "other"},
+				{Alias: "web", HostName: "web", Port: 22},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				filePath := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := Parse(filepath.Join(dir, "config"))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			for i := range test.want {
+				source := test.want[i].Source
+				if source == "" {
+					source = "config"
+				}
+				test.want[i].Source = filepath.Join(dir, source)
+			}
+			if !reflect.DeepEqual(entries, test.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", entries, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("Host web\n    IdentityFile \"unterminated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(config); err == nil {
+		t.Error("Parse() accepted an unterminated quote")
+	}
+	if _, err := Parse(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Parse() of a missing file = %v", err)
+	}
+}
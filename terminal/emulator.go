@@ -16,11 +16,18 @@ const (
 	attrBlink     = 1 << 5
 )
 
+// scrollbackLimit is the number of lines kept after they scroll off the screen
+const scrollbackLimit = 1000
+
+var blankCell = vt10x.Glyph{Char: ' ', FG: vt10x.DefaultFG, BG: vt10x.DefaultBG}
+
 type Emulator struct {
 	vt           vt10x.Terminal
 	width        int
 	height       int
 	scrollOffset int
+	modes        modeTracker
+	scrollback   [][]vt10x.Glyph
 }
 
 func NewEmulator(width, height int) *Emulator {
@@ -33,25 +40,85 @@ func NewEmulator(width, height int) *Emulator {
 	}
 }
 
+func (e *Emulator) Size() (width, height int) {
+	return e.width, e.height
+}
+
 func (e *Emulator) Resize(width, height int) {
 	e.width = width
 	e.height = height
 	e.vt.Resize(width, height)
+	e.modes.resetScrollRegion()
 }
 
 func (e *Emulator) Write(data []byte) {
-	e.vt.Write(data)
+	// Feed the data in pieces so the top rows can be saved to the
+	// scrollback just before a line feed or SU scrolls them away
+	start := 0
+	for i, b := range data {
+		lines, feed := e.modes.step(b)
+		if lines == 0 {
+			continue
+		}
+		e.vt.Write(data[start:i])
+		start = i
+		e.saveScrolledLines(lines, feed)
+	}
+	e.vt.Write(data[start:])
 	e.scrollOffset = 0
 }
 
+// saveScrolledLines keeps the top rows that are about to scroll off the
+// screen. Nothing is kept on the alternate screen, or when a scrolling
+// region leaves the lines on screen.
+func (e *Emulator) saveScrolledLines(lines int, feed bool) {
+	if e.vt.Mode()&vt10x.ModeAltScreen != 0 || !e.modes.fullScreenRegion(e.height) {
+		return
+	}
+	if feed && e.vt.Cursor().Y != e.height-1 {
+		return
+	}
+
+	for y := 0; y < min(lines, e.height); y++ {
+		row := make([]vt10x.Glyph, e.width)
+		for x := range row {
+			row[x] = e.vt.Cell(x, y)
+		}
+
+		if len(e.scrollback) >= scrollbackLimit {
+			copy(e.scrollback, e.scrollback[1:])
+			e.scrollback = e.scrollback[:len(e.scrollback)-1]
+		}
+		e.scrollback = append(e.scrollback, row)
+	}
+}
+
+// Title returns the window title the remote application set, if any
+func (e *Emulator) Title() string {
+	return e.modes.title
+}
+
+// TakeBell reports whether the remote application rang the bell since the
+// last call
+func (e *Emulator) TakeBell() bool {
+	rang := e.modes.bell
+	e.modes.bell = false
+	return rang
+}
+
+// BracketedPaste reports whether the remote application enabled bracketed paste (mode 2004)
+func (e *Emulator) BracketedPaste() bool {
+	return e.modes.bracketedPaste
+}
+
 func (e *Emulator) IsScrolled() bool {
 	return e.scrollOffset > 0
 }
 
 func (e *Emulator) WheelUp() {
 	e.scrollOffset += 3
-	if e.scrollOffset > 1000 {
-		e.scrollOffset = 1000
+	if e.scrollOffset > len(e.scrollback) {
+		e.scrollOffset = len(e.scrollback)
 	}
 }
 
@@ -67,11 +134,22 @@ func (e *Emulator) Render() string {
 	result.Grow(e.width * e.height * 2)
 
 	cursor := e.vt.Cursor()
-	cursorVisible := e.vt.CursorVisible()
+	cursorVisible := e.vt.CursorVisible() && e.scrollOffset == 0
+
+	// When scrolled back, the top rows come from the scrollback buffer
+	firstLine := len(e.scrollback) - e.scrollOffset
 
 	for y := 0; y < e.height; y++ {
+		line := firstLine + y
 		for x := 0; x < e.width; x++ {
-			cell := e.vt.Cell(x, y)
+			cell := blankCell
+			if line < len(e.scrollback) {
+				if row := e.scrollback[line]; x < len(row) {
+					cell = row[x]
+				}
+			} else {
+				cell = e.vt.Cell(x, line-len(e.scrollback))
+			}
 			ch := cell.Char
 
 			if ch == 0 {
@@ -0,0 +1,45 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrollback(t *testing.T) {
+	tests := []struct {
+		name string
+		data []string
+		want []string
+	}{
+		{"line feeds", []string{"a\r\nb\r\nc\r\nd"}, []string{"a"}},
+		{"no scroll", []string{"a\r\nb"}, nil},
+		{"index", []string{"a\r\nb\r\nc\x1bD"}, []string{"a"}},
+		{"next line", []string{"a\r\nb\r\nc\x1bEd\x1bE"}, []string{"a", "b"}},
+		{"scroll up", []string{"a\r\nb\r\nc\x1b[2S"}, []string{"a", "b"}},
+		{"index split across writes", []string{"a\r\nb\r\nc\x1b", "D"}, []string{"a"}},
+		{"scroll region", []string{"\x1b[1;2ra\r\nb\r\nc\r\nd\x1b[S"}, nil},
+		{"scroll region reset", []string{"\x1b[2;3r\x1b[r\x1b[3;1Hc\r\nd"}, []string{""}},
+		{"alternate screen", []string{"\x1b[?1049ha\r\nb\r\nc\r\nd"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			emulator := NewEmulator(10, 3)
+			for _, data := range test.data {
+				emulator.Write([]byte(data))
+			}
+
+			var got []string
+			for _, row := range emulator.scrollback {
+				var line strings.Builder
+				for _, cell := range row {
+					line.WriteRune(cell.Char)
+				}
+				got = append(got, strings.TrimRight(line.String(), " \x00"))
+			}
+			if strings.Join(got, "|") != strings.Join(test.want, "|") || len(got) != len(test.want) {
+				t.Errorf("scrollback = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hinshun/vt10x"
+)
+
+// xterm modifier bits, sent as 1 + the sum of the pressed modifiers
+const (
+	modShift = 1
+	modAlt   = 2
+	modCtrl  = 4
+)
+
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+type keySequence struct {
+	code  int  // numeric parameter for CSI <code> ~ sequences
+	final byte // final byte for CSI/SS3 sequences
+	mod   int  // modifiers implied by the key type itself
+}
+
+// cursorKeys are sent as CSI <final> or SS3 <final> depending on DECCKM
+var cursorKeys = map[tea.KeyType]keySequence{
+	tea.KeyUp:             {final: 'A'},
+	tea.KeyDown:           {final: 'B'},
+	tea.KeyRight:          {final: 'C'},
+	tea.KeyLeft:           {final: 'D'},
+	tea.KeyHome:           {final: 'H'},
+	tea.KeyEnd:            {final: 'F'},
+	tea.KeyCtrlUp:         {final: 'A', mod: modCtrl},
+	tea.KeyCtrlDown:       {final: 'B', mod: modCtrl},
+	tea.KeyCtrlRight:      {final: 'C', mod: modCtrl},
+	tea.KeyCtrlLeft:       {final: 'D', mod: modCtrl},
+	tea.KeyCtrlHome:       {final: 'H', mod: modCtrl},
+	tea.KeyCtrlEnd:        {final: 'F', mod: modCtrl},
+	tea.KeyShiftUp:        {final: 'A', mod: modShift},
+	tea.KeyShiftDown:      {final: 'B', mod: modShift},
+	tea.KeyShiftRight:     {final: 'C', mod: modShift},
+	tea.KeyShiftLeft:      {final: 'D', mod: modShift},
+	tea.KeyShiftHome:      {final: 'H', mod: modShift},
+	tea.KeyShiftEnd:       {final: 'F', mod: modShift},
+	tea.KeyCtrlShiftUp:    {final: 'A', mod: modCtrl | modShift},
+	tea.KeyCtrlShiftDown:  {final: 'B', mod: modCtrl | modShift},
+	tea.KeyCtrlShiftRight: {final: 'C', mod: modCtrl | modShift},
+	tea.KeyCtrlShiftLeft:  {final: 'D', mod: modCtrl | modShift},
+	tea.KeyCtrlShiftHome:  {final: 'H', mod: modCtrl | modShift},
+	tea.KeyCtrlShiftEnd:   {final: 'F', mod: modCtrl | modShift},
+}
+
+// functionKeys are F1-F4 (and their shifted F13-F16 aliases), sent as SS3 <final>
+var functionKeys = map[tea.KeyType]keySequence{
+	tea.KeyF1:  {final: 'P'},
+	tea.KeyF2:  {final: 'Q'},
+	tea.KeyF3:  {final: 'R'},
+	tea.KeyF4:  {final: 'S'},
+	tea.KeyF13: {final: 'P', mod: modShift},
+	tea.KeyF14: {final: 'Q', mod: modShift},
+	tea.KeyF15: {final: 'R', mod: modShift},
+	tea.KeyF16: {final: 'S', mod: modShift},
+}
+
+// tildeKeys are editing and function keys sent as CSI <code> ~
+var tildeKeys = map[tea.KeyType]keySequence{
+	tea.KeyInsert:     {code: 2},
+	tea.KeyDelete:     {code: 3},
+	tea.KeyPgUp:       {code: 5},
+	tea.KeyPgDown:     {code: 6},
+	tea.KeyCtrlPgUp:   {code: 5, mod: modCtrl},
+	tea.KeyCtrlPgDown: {code: 6, mod: modCtrl},
+	tea.KeyF5:         {code: 15},
+	tea.KeyF6:         {code: 17},
+	tea.KeyF7:         {code: 18},
+	tea.KeyF8:         {code: 19},
+	tea.KeyF9:         {code: 20},
+	tea.KeyF10:        {code: 21},
+	tea.KeyF11:        {code: 23},
+	tea.KeyF12:        {code: 24},
+	tea.KeyF17:        {code: 15, mod: modShift},
+	tea.KeyF18:        {code: 17, mod: modShift},
+	tea.KeyF19:        {code: 18, mod: modShift},
+	tea.KeyF20:        {code: 19, mod: modShift},
+}
+
+// AppCursorKeys reports whether the remote enabled application cursor keys (DECCKM)
+func (e *Emulator) AppCursorKeys() bool {
+	return e.vt.Mode()&vt10x.ModeAppCursor != 0
+}
+
+// EncodeKey converts a key message to the bytes an xterm would send for it,
+// honouring the cursor key mode currently requested by the remote application
+func (e *Emulator) EncodeKey(key tea.KeyMsg) []byte {
+	if key.Paste {
+		return e.EncodePaste(string(key.Runes))
+	}
+
+	alt := 0
+	if key.Alt {
+		alt = modAlt
+	}
+
+	if seq, ok := cursorKeys[key.Type]; ok {
+		mod := seq.mod | alt
+		if mod != 0 {
+			return fmt.Appendf(nil, "\x1b[1;%d%c", mod+1, seq.final)
+		}
+		if e.AppCursorKeys() {
+			return []byte{0x1b, 'O', seq.final}
+		}
+		return []byte{0x1b, '[', seq.final}
+	}
+
+	if seq, ok := functionKeys[key.Type]; ok {
+		mod := seq.mod | alt
+		if mod != 0 {
+			return fmt.Appendf(nil, "\x1b[1;%d%c", mod+1, seq.final)
+		}
+		return []byte{0x1b, 'O', seq.final}
+	}
+
+	if seq, ok := tildeKeys[key.Type]; ok {
+		mod := seq.mod | alt
+		if mod != 0 {
+			return fmt.Appendf(nil, "\x1b[%d;%d~", seq.code, mod+1)
+		}
+		return fmt.Appendf(nil, "\x1b[%d~", seq.code)
+	}
+
+	var data []byte
+	switch {
+	case key.Type == tea.KeyRunes:
+		data = []byte(string(key.Runes))
+	case key.Type == tea.KeySpace:
+		data = []byte{' '}
+	case key.Type == tea.KeyShiftTab:
+		data = []byte{0x1b, '[', 'Z'}
+	case key.Type >= tea.KeyCtrlAt && key.Type <= tea.KeyCtrlUnderscore, key.Type == tea.KeyBackspace:
+		// Control key types share their values with the C0 bytes they produce
+		data = []byte{byte(key.Type)}
+	default:
+		return nil
+	}
+
+	// Meta sends ESC before the key, matching xterm's metaSendsEscape
+	if key.Alt {
+		return append([]byte{0x1b}, data...)
+	}
+	return data
+}
+
+// EncodePaste converts pasted text to the bytes sent to the remote. Newlines
+// become carriage returns as in xterm, and the text is wrapped in bracketed
+// paste markers when the remote application asked for them.
+func (e *Emulator) EncodePaste(text string) []byte {
+	text = strings.ReplaceAll(text, "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+
+	if !e.BracketedPaste() {
+		return []byte(text)
+	}
+
+	// Drop any end marker inside the text so it cannot break out of the paste
+	text = strings.ReplaceAll(text, pasteEnd, "")
+	return []byte(pasteStart + text + pasteEnd)
+}
@@ -0,0 +1,78 @@
+package terminal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEncodeKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		appCursor bool
+		key       tea.KeyMsg
+		want      string
+	}{
+		{"runes", false, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("héllo")}, "héllo"},
+		{"alt runes", false, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true}, "\x1bx"},
+		{"space", false, tea.KeyMsg{Type: tea.KeySpace}, " "},
+		{"enter", false, tea.KeyMsg{Type: tea.KeyEnter}, "\r"},
+		{"tab", false, tea.KeyMsg{Type: tea.KeyTab}, "\t"},
+		{"shift tab", false, tea.KeyMsg{Type: tea.KeyShiftTab}, "\x1b[Z"},
+		{"backspace", false, tea.KeyMsg{Type: tea.KeyBackspace}, "\x7f"},
+		{"ctrl c", false, tea.KeyMsg{Type: tea.KeyCtrlC}, "\x03"},
+		{"escape", false, tea.KeyMsg{Type: tea.KeyEscape}, "\x1b"},
+		{"up", false, tea.KeyMsg{Type: tea.KeyUp}, "\x1b[A"},
+		{"up in application mode", true, tea.KeyMsg{Type: tea.KeyUp}, "\x1bOA"},
+		{"home in application mode", true, tea.KeyMsg{Type: tea.KeyHome}, "\x1bOH"},
+		{"ctrl left", false, tea.KeyMsg{Type: tea.KeyCtrlLeft}, "\x1b[1;5D"},
+		{"ctrl left in application mode", true, tea.KeyMsg{Type: tea.KeyCtrlLeft}, "\x1b[1;5D"},
+		{"alt up", false, tea.KeyMsg{Type: tea.KeyUp, Alt: true}, "\x1b[1;3A"},
+		{"ctrl shift end", false, tea.KeyMsg{Type: tea.KeyCtrlShiftEnd}, "\x1b[1;6F"},
+		{"f1", false, tea.KeyMsg{Type: tea.KeyF1}, "\x1bOP"},
+		{"f13", false, tea.KeyMsg{Type: tea.KeyF13}, "\x1b[1;2P"},
+		{"f5", false, tea.KeyMsg{Type: tea.KeyF5}, "\x1b[15~"},
+		{"f12", false, tea.KeyMsg{Type: tea.KeyF12}, "\x1b[24~"},
+		{"delete", false, tea.KeyMsg{Type: tea.KeyDelete}, "\x1b[3~"},
+		{"alt delete", false, tea.KeyMsg{Type: tea.KeyDelete, Alt: true}, "\x1b[3;3~"},
+		{"ctrl page up", false, tea.KeyMsg{Type: tea.KeyCtrlPgUp}, "\x1b[5;5~"},
+		{"paste", false, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a\nb"), Paste: true}, "a\rb"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			emulator := NewEmulator(80, 24)
+			if test.appCursor {
+				emulator.Write([]byte("\x1b[?1h"))
+			}
+			if got := string(emulator.EncodeKey(test.key)); got != test.want {
+				t.Errorf("EncodeKey() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEncodePaste(t *testing.T) {
+	tests := []struct {
+		name      string
+		bracketed bool
+		text      string
+		want      string
+	}{
+		{"plain", false, "one\r\ntwo\nthree", "one\rtwo\rthree"},
+		{"bracketed", true, "one\ntwo", "\x1b[200~one\rtwo\x1b[201~"},
+		{"end marker dropped", true, "a\x1b[201~b", "\x1b[200~ab\x1b[201~"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			emulator := NewEmulator(80, 24)
+			if test.bracketed {
+				emulator.Write([]byte("\x1b[?2004h"))
+			}
+			if got := string(emulator.EncodePaste(test.text)); got != test.want {
+				t.Errorf("EncodePaste() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package terminal
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	modeBracketedPaste = 2004
+
+	// oscLimit bounds the OSC payload kept; titles are far shorter
+	oscLimit = 4096
+	// titleLimit is the most of a title that is kept
+	titleLimit = 256
+)
+
+type parserState int
+
+const (
+	stateGround parserState = iota
+	stateEscape
+	stateCSI
+	stateOSC
+	stateOSCEscape
+)
+
+// modeTracker follows the DEC private modes that vt10x does not implement,
+// the window title, the bell and the scrolling region. It keeps its parser
+// state between writes so sequences split across SSH reads are still
+// recognised.
+type modeTracker struct {
+	state   parserState
+	private bool
+	params  []int
+	current int
+	hasNum  bool
+	osc     []byte
+
+	bracketedPaste bool
+	title          string
+	bell           bool
+	// DECSTBM margins as sent, 1-based; 0 is the edge of the screen
+	scrollTop    int
+	scrollBottom int
+}
+
+// step parses one byte before the terminal does. It returns the number of
+// lines the byte scrolls up; feed is set for line feeds (LF, VT, FF, IND
+// and NEL), which only scroll when the cursor is on the bottom margin,
+// while SU always scrolls.
+func (tracker *modeTracker) step(b byte) (lines int, feed bool) {
+	switch tracker.state {
+	case stateGround:
+		switch b {
+		case 0x1b:
+			tracker.state = stateEscape
+		case 0x07:
+			tracker.bell = true
+		case '\n', '\v', '\f':
+			return 1, true
+		}
+	case stateEscape:
+		switch b {
+		case '[':
+			tracker.state = stateCSI
+			tracker.private = false
+			tracker.params = tracker.params[:0]
+			tracker.current = 0
+			tracker.hasNum = false
+		case ']':
+			tracker.state = stateOSC
+			tracker.osc = tracker.osc[:0]
+		case 'c': // RIS - full reset
+			tracker.reset()
+			tracker.state = stateGround
+		case 'D', 'E': // IND and NEL
+			tracker.state = stateGround
+			return 1, true
+		case '\n', '\v', '\f':
+			// Executed without ending the sequence
+			return 1, true
+		case 0x1b:
+		default:
+			tracker.state = stateGround
+		}
+	case stateCSI:
+		switch {
+		case b == '?' && !tracker.hasNum && len(tracker.params) == 0:
+			tracker.private = true
+		case b >= '0' && b <= '9':
+			tracker.current = tracker.current*10 + int(b-'0')
+			tracker.hasNum = true
+		case b == ';':
+			tracker.params = append(tracker.params, tracker.current)
+			tracker.current = 0
+			tracker.hasNum = false
+		case b >= 0x40 && b <= 0x7e:
+			if tracker.hasNum {
+				tracker.params = append(tracker.params, tracker.current)
+			}
+			tracker.state = stateGround
+			switch {
+			case tracker.private && (b == 'h' || b == 'l'):
+				tracker.setModes(tracker.params, b == 'h')
+			case !tracker.private && b == 'r': // DECSTBM
+				tracker.scrollTop, tracker.scrollBottom = tracker.param(0), tracker.param(1)
+			case !tracker.private && b == 'S': // SU
+				return max(tracker.param(0), 1), false
+			}
+		case b == 0x1b:
+			tracker.state = stateEscape
+		case b == '\n' || b == '\v' || b == '\f':
+			return 1, true
+		case b < 0x20:
+			// C0 controls are executed mid-sequence and do not end it
+		}
+	case stateOSC:
+		switch b {
+		case 0x07:
+			// BEL ends the sequence here, it does not ring
+			tracker.handleOSC()
+			tracker.state = stateGround
+		case 0x1b:
+			tracker.state = stateOSCEscape
+		default:
+			if len(tracker.osc) < oscLimit {
+				tracker.osc = append(tracker.osc, b)
+			}
+		}
+	case stateOSCEscape:
+		if b == '\\' {
+			tracker.handleOSC()
+			tracker.state = stateGround
+		} else {
+			tracker.state = stateOSC
+		}
+	}
+	return 0, false
+}
+
+// param returns the i-th parameter of the current CSI sequence, 0 when it
+// is missing
+func (tracker *modeTracker) param(i int) int {
+	if i < len(tracker.params) {
+		return tracker.params[i]
+	}
+	return 0
+}
+
+// fullScreenRegion reports whether the scrolling region spans a screen of
+// the given height, so lines scrolled off its top leave the screen
+func (tracker *modeTracker) fullScreenRegion(height int) bool {
+	return tracker.scrollTop <= 1 && (tracker.scrollBottom == 0 || tracker.scrollBottom >= height)
+}
+
+func (tracker *modeTracker) reset() {
+	tracker.bracketedPaste = false
+	tracker.title = ""
+	tracker.resetScrollRegion()
+}
+
+// resetScrollRegion goes back to a full screen region, as vt10x does on a
+// reset or a resize
+func (tracker *modeTracker) resetScrollRegion() {
+	tracker.scrollTop = 0
+	tracker.scrollBottom = 0
+}
+
+// handleOSC acts on a complete OSC sequence. Only titles (OSC 0 sets the
+// icon name and title, OSC 2 the title) are of interest; an empty title
+// goes back to none.
+func (tracker *modeTracker) handleOSC() {
+	command, text, _ := strings.Cut(string(tracker.osc), ";")
+	if command != "0" && command != "2" {
+		return
+	}
+
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(text, ""))
+	if runes := []rune(text); len(runes) > titleLimit {
+		text = string(runes[:titleLimit])
+	}
+	tracker.title = strings.TrimSpace(text)
+}
+
+func (tracker *modeTracker) setModes(modes []int, set bool) {
+	for _, mode := range modes {
+		switch mode {
+		case modeBracketedPaste:
+			tracker.bracketedPaste = set
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hinshun/vt10x"
+)
+
+// xterm mouse button codes and flags
+const (
+	mouseRelease = 3
+	mouseShift   = 4
+	mouseMeta    = 8
+	mouseCtrl    = 16
+	mouseMotion  = 32
+
+	// legacy reports encode each value as a single byte offset by 32
+	mouseLegacyMax = 255 - 32
+)
+
+var mouseButtonCodes = map[tea.MouseButton]int{
+	tea.MouseButtonNone:       mouseRelease,
+	tea.MouseButtonLeft:       0,
+	tea.MouseButtonMiddle:     1,
+	tea.MouseButtonRight:      2,
+	tea.MouseButtonWheelUp:    64,
+	tea.MouseButtonWheelDown:  65,
+	tea.MouseButtonWheelLeft:  66,
+	tea.MouseButtonWheelRight: 67,
+	tea.MouseButtonBackward:   128,
+	tea.MouseButtonForward:    129,
+}
+
+// MouseReporting reports whether the remote application enabled any mouse tracking mode
+func (e *Emulator) MouseReporting() bool {
+	return e.vt.Mode()&vt10x.ModeMouseMask != 0
+}
+
+// MouseAllMotion reports whether the remote wants motion events even with no button held (mode 1003)
+func (e *Emulator) MouseAllMotion() bool {
+	return e.vt.Mode()&vt10x.ModeMouseMany != 0
+}
+
+// EncodeMouse converts a mouse event to the report expected by the remote
+// application, or nil when its current tracking mode does not cover the event
+func (e *Emulator) EncodeMouse(msg tea.MouseMsg) []byte {
+	mode := e.vt.Mode()
+	if mode&vt10x.ModeMouseMask == 0 {
+		return nil
+	}
+	if msg.X < 0 || msg.Y < 0 || msg.X >= e.width || msg.Y >= e.height {
+		return nil
+	}
+
+	x10 := mode&vt10x.ModeMouseX10 != 0
+	switch msg.Action {
+	case tea.MouseActionRelease:
+		if x10 {
+			return nil
+		}
+	case tea.MouseActionMotion:
+		buttonHeld := msg.Button != tea.MouseButtonNone
+		if mode&vt10x.ModeMouseMany == 0 && (mode&vt10x.ModeMouseMotion == 0 || !buttonHeld) {
+			return nil
+		}
+	}
+
+	code, ok := mouseButtonCodes[msg.Button]
+	if !ok {
+		return nil
+	}
+
+	if !x10 {
+		if msg.Shift {
+			code |= mouseShift
+		}
+		if msg.Alt {
+			code |= mouseMeta
+		}
+		if msg.Ctrl {
+			code |= mouseCtrl
+		}
+	}
+	if msg.Action == tea.MouseActionMotion {
+		code |= mouseMotion
+	}
+
+	col, row := msg.X+1, msg.Y+1
+
+	// SGR (1006) reports keep the button on release and mark it with 'm'
+	if mode&vt10x.ModeMouseSgr != 0 {
+		final := 'M'
+		if msg.Action == tea.MouseActionRelease {
+			final = 'm'
+		}
+		return fmt.Appendf(nil, "\x1b[<%d;%d;%d%c", code, col, row, final)
+	}
+
+	if msg.Action == tea.MouseActionRelease {
+		code = code&^0x83 | mouseRelease
+	}
+	if col > mouseLegacyMax || row > mouseLegacyMax {
+		return nil
+	}
+	return []byte{0x1b, '[', 'M', byte(32 + code), byte(32 + col), byte(32 + row)}
+}
@@ -0,0 +1,48 @@
+package terminal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEncodeMouse(t *testing.T) {
+	press := func(button tea.MouseButton, x, y int) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Button: button, Action: tea.MouseActionPress}
+	}
+
+	tests := []struct {
+		name  string
+		modes string
+		msg   tea.MouseMsg
+		want  string
+	}{
+		{"not reporting", "", press(tea.MouseButtonLeft, 0, 0), ""},
+		{"x10 press", "\x1b[?9h", press(tea.MouseButtonLeft, 0, 0), "\x1b[M !!"},
+		{"x10 release", "\x1b[?9h", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease}, ""},
+		{"x10 ignores modifiers", "\x1b[?9h", tea.MouseMsg{X: 1, Y: 2, Button: tea.MouseButtonRight, Action: tea.MouseActionPress, Ctrl: true}, "\x1b[M\"\"#"},
+		{"normal press", "\x1b[?1000h", press(tea.MouseButtonMiddle, 4, 9), "\x1b[M!%*"},
+		{"normal release", "\x1b[?1000h", tea.MouseMsg{X: 4, Y: 9, Button: tea.MouseButtonMiddle, Action: tea.MouseActionRelease}, "\x1b[M#%*"},
+		{"normal shift ctrl", "\x1b[?1000h", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Shift: true, Ctrl: true}, "\x1b[M4!!"},
+		{"normal wheel", "\x1b[?1000h", press(tea.MouseButtonWheelUp, 0, 0), "\x1b[M`!!"},
+		{"normal motion dropped", "\x1b[?1000h", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion}, ""},
+		{"button motion", "\x1b[?1002h", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion}, "\x1b[M@!!"},
+		{"button motion without button", "\x1b[?1002h", tea.MouseMsg{Button: tea.MouseButtonNone, Action: tea.MouseActionMotion}, ""},
+		{"any motion without button", "\x1b[?1003h", tea.MouseMsg{Button: tea.MouseButtonNone, Action: tea.MouseActionMotion}, "\x1b[MC!!"},
+		{"sgr press", "\x1b[?1000h\x1b[?1006h", press(tea.MouseButtonLeft, 299, 0), "\x1b[<0;300;1M"},
+		{"sgr release keeps button", "\x1b[?1000h\x1b[?1006h", tea.MouseMsg{X: 2, Y: 3, Button: tea.MouseButtonRight, Action: tea.MouseActionRelease}, "\x1b[<2;3;4m"},
+		{"sgr alt", "\x1b[?1000h\x1b[?1006h", tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Alt: true}, "\x1b[<8;1;1M"},
+		{"legacy out of range", "\x1b[?1000h", press(tea.MouseButtonLeft, 299, 0), ""},
+		{"outside the screen", "\x1b[?1000h", press(tea.MouseButtonLeft, 0, 50), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			emulator := NewEmulator(300, 50)
+			emulator.Write([]byte(test.modes))
+			if got := string(emulator.EncodeMouse(test.msg)); got != test.want {
+				t.Errorf("EncodeMouse() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
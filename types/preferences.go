@@ -0,0 +1,28 @@
+package types
+
+type PreferenceKey string
+
+const (
+	// PrefPasteConfirmLines is the line count above which a paste asks for
+	// confirmation; 0 disables the prompt
+	PrefPasteConfirmLines PreferenceKey = "paste_confirm_lines"
+
+	// PrefTranscriptTemplate names transcript files, see recording.TranscriptFileName
+	PrefTranscriptTemplate PreferenceKey = "transcript_template"
+
+	// PrefTranscriptMaxSizeMB is the size at which a transcript continues in
+	// a new file; 0 disables rotation
+	PrefTranscriptMaxSizeMB PreferenceKey = "transcript_max_size_mb"
+
+	// PrefCollapsedGroups lists the host groups collapsed in the sidebar,
+	// one path per line
+	PrefCollapsedGroups PreferenceKey = "collapsed_host_groups"
+
+	// PrefNotifyMethod is how notifications of background sessions are
+	// delivered, see notify.Method
+	PrefNotifyMethod PreferenceKey = "notify_method"
+
+	// PrefNotifyCommand is the local command run by the "command" notify
+	// method
+	PrefNotifyCommand PreferenceKey = "notify_command"
+)
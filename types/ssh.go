@@ -4,43 +4,54 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-// SSH Bubble Tea messages for async events
+// SSH Bubble Tea messages for async events. Sessions are told apart by
+// SessionID, as several terminals may be open on the same host.
 
 type SSHConnectingMsg struct {
-	HostID  uint
-	Message string
+	SessionID uint
+	Message   string
 }
 
 type SSHAuthenticatingMsg struct {
-	HostID  uint
-	Message string
+	SessionID uint
+	Message   string
 }
 
 type SSHHostKeyMsg struct {
-	HostID      uint
+	SessionID   uint
 	Hostname    string
 	Port        int
 	KeyType     string
 	Fingerprint string
 	ServerKey   ssh.PublicKey
+	Changed     bool // another key is trusted for the host
 }
 
 type SSHConnectedMsg struct {
-	HostID        uint
+	SessionID     uint
 	Client        any // *ssh.Client from ssh package
 	ConnectionLog any // *models.ConnectionLog
 }
 
 type SSHOutputMsg struct {
-	HostID uint
-	Data   []byte
+	SessionID uint
+	Data      []byte
 }
 
 type SSHErrorMsg struct {
-	HostID uint
-	Error  error
+	SessionID uint
+	Error     error
 }
 
 type SSHDisconnectedMsg struct {
-	HostID uint
-}
\ No newline at end of file
+	SessionID uint
+}
+
+// SSHKeyInstalledMsg reports the outcome of installing a public key on a host
+type SSHKeyInstalledMsg struct {
+	HostID    uint
+	KeyID     uint
+	UseKey    bool // switch the host's credential to the key on success
+	Installed bool // false when the key was already authorized
+	Error     error
+}
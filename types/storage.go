@@ -23,3 +23,12 @@ const (
 	CredentialIdentity CredentialType = "identity"
 	CredentialKey      CredentialType = "key"
 )
+
+// KnownHostMarker mirrors the @-markers of OpenSSH known_hosts lines
+type KnownHostMarker string
+
+const (
+	KnownHostPlain         KnownHostMarker = ""
+	KnownHostCertAuthority KnownHostMarker = "cert-authority"
+	KnownHostRevoked       KnownHostMarker = "revoked"
+)
@@ -1,5 +1,7 @@
 package types
 
+import tea "github.com/charmbracelet/bubbletea"
+
 // KeyCaptureMode defines how keyboard input is handled
 type KeyCaptureMode int
 
@@ -11,4 +13,45 @@ const (
 // KeyCapturer is implemented by screens that support key capture mode
 type KeyCapturer interface {
 	GetKeyCaptureMode() KeyCaptureMode
-}
\ No newline at end of file
+}
+
+// SessionRecorder is implemented by screens that can record their session
+type SessionRecorder interface {
+	IsRecording() bool
+}
+
+// InputSyncer is implemented by screens whose keystrokes can be mirrored to
+// the other synchronized tabs
+type InputSyncer interface {
+	IsSyncingInput() bool
+}
+
+// SessionHolder is implemented by screens holding a remote session, which
+// closing their tab ends
+type SessionHolder interface {
+	IsConnected() bool
+	CloseSession() tea.Cmd
+}
+
+// TitleReporter is implemented by screens whose session can set the title
+// of their tab
+type TitleReporter interface {
+	Title() string
+}
+
+// Activity is what happened in a tab while it was in the background, from
+// least to most worth a look
+type Activity int
+
+const (
+	ActivityNone   Activity = iota
+	ActivityOutput          // the session printed something
+	ActivityBell            // the session rang the bell
+	ActivityExited          // the session ended
+)
+
+// ActivityReporter is implemented by screens that report what happened
+// while their tab was in the background
+type ActivityReporter interface {
+	Activity() Activity
+}
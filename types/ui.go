@@ -1,8 +1,20 @@
 package types
 
 type Tab struct {
-	Name   string
-	Screen Screen
+	Name    string
+	Screen  Screen
+	Renamed bool // named by the user, which titles set by the session do not override
+}
+
+// Title is what the tab bar shows for the tab: the title its session set,
+// or its name
+func (tab Tab) Title() string {
+	if reporter, ok := tab.Screen.(TitleReporter); ok && !tab.Renamed {
+		if title := reporter.Title(); title != "" {
+			return title
+		}
+	}
+	return tab.Name
 }
 
 // AddTabMsg is a message to add a new tab and switch to it
@@ -11,14 +23,23 @@ type AddTabMsg struct {
 	Screen  Screen
 }
 
-// CloseTabMsg is a message to remove the current tab
-type CloseTabMsg struct{}
+// CloseTabMsg is a message to remove a tab. Screen identifies the tab to
+// close; nil closes the current tab.
+type CloseTabMsg struct {
+	Screen Screen
+}
 
 type TabBar interface {
 	AddTab(tab Tab)
 	RemoveCurrentTab()
+	RemoveTab(index int)
+	GetTabs() []Tab
+	GetActiveIndex() int
 	GetCurrentScreen() Screen
 	UpdateCurrentScreen(screen Screen)
+	UpdateScreen(index int, screen Screen)
+	RenameTab(index int, name string)
+	MoveTab(index int, delta int)
 	SwitchToTab(index int)
 	SwitchToLastTab()
 	NextTab()
@@ -28,6 +49,7 @@ type TabBar interface {
 
 type NavBar interface {
 	GetActiveTab() (int, string)
+	GetItems() []string
 	SwitchToTab(index int)
 	NextTab()
 	PrevTab()
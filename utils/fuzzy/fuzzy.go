@@ -0,0 +1,129 @@
+package fuzzy
+
+import (
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 12
+	bonusBoundary    = 10
+	bonusFirst       = 6
+	bonusExact       = 20
+	maxGapPenalty    = 8
+)
+
+// Result is a successful match: the higher the score the better, and
+// Positions are the indices of the matched runes of the text
+type Result struct {
+	Score     int
+	Positions []int
+}
+
+// Match looks for the runes of pattern in order in text, the way fzf
+// does. Matching ignores case unless pattern has an upper case letter.
+// Runs of consecutive runes and runes starting a word score higher.
+func Match(pattern, text string) (Result, bool) {
+	needle := []rune(pattern)
+	haystack := []rune(text)
+	if len(needle) == 0 {
+		return Result{}, true
+	}
+	if len(needle) > len(haystack) {
+		return Result{}, false
+	}
+
+	caseSensitive := false
+	for _, r := range needle {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return a == b || unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	// Find where the first complete match ends, then walk back from there
+	// to the latest start, which gives the tightest window
+	end := -1
+	n := 0
+	for i, r := range haystack {
+		if equal(needle[n], r) {
+			n++
+			if n == len(needle) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return Result{}, false
+	}
+	start := end
+	n = len(needle) - 1
+	for i := end; i >= 0; i-- {
+		if equal(needle[n], haystack[i]) {
+			if n == 0 {
+				start = i
+				break
+			}
+			n--
+		}
+	}
+
+	positions := make([]int, 0, len(needle))
+	n = 0
+	for i := start; i <= end && n < len(needle); i++ {
+		if equal(needle[n], haystack[i]) {
+			positions = append(positions, i)
+			n++
+		}
+	}
+
+	score := 0
+	previous := -1
+	for _, i := range positions {
+		score += scoreMatch
+		if previous >= 0 && i == previous+1 {
+			score += bonusConsecutive
+		}
+		if previous >= 0 && i > previous+1 {
+			score -= min(i-previous-1, maxGapPenalty)
+		}
+		if isBoundary(haystack, i) {
+			score += bonusBoundary
+		}
+		if i == 0 {
+			score += bonusFirst
+		}
+		previous = i
+	}
+	if len(needle) == len(haystack) {
+		score += bonusExact
+	}
+
+	return Result{Score: score, Positions: positions}, true
+}
+
+// isBoundary reports whether the rune at i starts a word: it follows a
+// separator, a lower case letter (camelCase) or a change between letters
+// and digits
+func isBoundary(text []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	previous, current := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(previous) && !unicode.IsDigit(previous):
+		return unicode.IsLetter(current) || unicode.IsDigit(current)
+	case unicode.IsLower(previous) && unicode.IsUpper(current):
+		return true
+	case unicode.IsLetter(previous) != unicode.IsLetter(current):
+		return true
+	}
+	return false
+}
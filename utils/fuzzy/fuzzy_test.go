@@ -0,0 +1,67 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		wantOK    bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"web", "web-01", true, []int{0, 1, 2}},
+		{"wb1", "web-01", true, []int{0, 2, 5}},
+		{"WEB", "web-01", false, nil},
+		{"Web", "Web-01", true, []int{0, 1, 2}},
+		{"db", "prod-db", true, []int{5, 6}},
+		// The tightest window wins over the first rune found
+		{"ab", "a-x-ab", true, []int{4, 5}},
+		{"é", "café", true, []int{3}},
+		{"bew", "web", false, nil},
+		{"webs", "web", false, nil},
+	}
+
+	for _, test := range tests {
+		result, ok := Match(test.pattern, test.text)
+		if ok != test.wantOK {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", test.pattern, test.text, ok, test.wantOK)
+			continue
+		}
+		if ok && !slices.Equal(result.Positions, test.positions) {
+			t.Errorf("Match(%q, %q) positions = %v, want %v", test.pattern, test.text, result.Positions, test.positions)
+		}
+	}
+}
+
+func TestMatchRanking(t *testing.T) {
+	// Each pair is pattern, better text, worse text
+	tests := []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		{"web", "web", "web-01"},
+		{"web", "web-01", "my-w-e-b"},
+		{"db", "prod-db", "adobe"},
+		{"pw", "prodWeb", "proxy-raw"},
+		{"api", "api-gateway", "rapid"},
+	}
+
+	for _, test := range tests {
+		better, ok := Match(test.pattern, test.better)
+		if !ok {
+			t.Fatalf("Match(%q, %q) did not match", test.pattern, test.better)
+		}
+		worse, ok := Match(test.pattern, test.worse)
+		if !ok {
+			t.Fatalf("Match(%q, %q) did not match", test.pattern, test.worse)
+		}
+		if better.Score <= worse.Score {
+			t.Errorf("%q scores %d in %q, want more than %d in %q", test.pattern, better.Score, test.better, worse.Score, test.worse)
+		}
+	}
+}
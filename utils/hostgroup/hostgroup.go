@@ -0,0 +1,177 @@
+package hostgroup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"yoru/models"
+)
+
+// Separator divides the folders of a group path, as in "prod/eu-west"
+const Separator = "/"
+
+// Node is a group in the host tree. The root node has no name and holds
+// the hosts without a group.
+type Node struct {
+	Name   string
+	Path   string
+	Groups []*Node
+	Hosts  []models.Host
+}
+
+// NormalizePath trims the folders of a group path and drops empty ones,
+// so " prod / eu-west/ " is stored as "prod/eu-west"
+func NormalizePath(value string) string {
+	var segments []string
+	for _, segment := range strings.Split(value, Separator) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, Separator)
+}
+
+// Ancestors lists a group path and the paths of the folders above it,
+// outermost first: "a/b/c" gives "a", "a/b" and "a/b/c"
+func Ancestors(path string) []string {
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, Separator)
+	ancestors := make([]string, len(segments))
+	for i := range segments {
+		ancestors[i] = strings.Join(segments[:i+1], Separator)
+	}
+	return ancestors
+}
+
+// ParseTags splits tags typed as "web, prod #db" into their names,
+// dropping a leading # and case-insensitive duplicates
+func ParseTags(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		tag := strings.TrimLeft(field, "#")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// JoinTags encodes tags the way they are stored on the host
+func JoinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// NormalizeTags parses and re-encodes tags typed by the user
+func NormalizeTags(value string) string {
+	return JoinTags(ParseTags(value))
+}
+
+// Select picks hosts by a comma separated list of terms: "#tag", a group
+// path with a leading slash ("/prod/eu", including its subgroups; "/" is
+// every host), or a host name. A name that matches no host is an error,
+// as it is most likely a typo.
+func Select(hosts []models.Host, selector string) ([]models.Host, error) {
+	selected := make(map[uint]bool)
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		matched := false
+		for _, host := range hosts {
+			var ok bool
+			switch {
+			case strings.HasPrefix(term, "#"):
+				ok = hasTag(host, strings.TrimPrefix(term, "#"))
+			case strings.HasPrefix(term, Separator):
+				ok = inGroup(host, NormalizePath(term))
+			default:
+				ok = strings.EqualFold(host.Name, term)
+			}
+			if ok {
+				selected[host.ID] = true
+				matched = true
+			}
+		}
+		if !matched && !strings.HasPrefix(term, "#") && !strings.HasPrefix(term, Separator) {
+			return nil, fmt.Errorf("no host is named %q", term)
+		}
+	}
+
+	var result []models.Host
+	for _, host := range hosts {
+		if selected[host.ID] {
+			result = append(result, host)
+		}
+	}
+	return result, nil
+}
+
+func hasTag(host models.Host, tag string) bool {
+	for _, hostTag := range ParseTags(host.Tags) {
+		if strings.EqualFold(hostTag, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func inGroup(host models.Host, path string) bool {
+	group := NormalizePath(host.Group)
+	return path == "" || group == path || strings.HasPrefix(group, path+Separator)
+}
+
+// Build arranges hosts into a tree of groups. Groups are sorted by name
+// and hosts by their sort order, newest first when it is equal.
+func Build(hosts []models.Host) *Node {
+	root := &Node{}
+	nodes := map[string]*Node{"": root}
+
+	for _, host := range hosts {
+		path := NormalizePath(host.Group)
+		parent := root
+		for _, ancestor := range Ancestors(path) {
+			node, ok := nodes[ancestor]
+			if !ok {
+				node = &Node{Name: ancestor[strings.LastIndex(ancestor, Separator)+1:], Path: ancestor}
+				nodes[ancestor] = node
+				parent.Groups = append(parent.Groups, node)
+			}
+			parent = node
+		}
+		parent.Hosts = append(parent.Hosts, host)
+	}
+
+	for _, node := range nodes {
+		sort.SliceStable(node.Groups, func(i, j int) bool {
+			return strings.ToLower(node.Groups[i].Name) < strings.ToLower(node.Groups[j].Name)
+		})
+		sort.SliceStable(node.Hosts, func(i, j int) bool {
+			if node.Hosts[i].SortOrder != node.Hosts[j].SortOrder {
+				return node.Hosts[i].SortOrder < node.Hosts[j].SortOrder
+			}
+			return node.Hosts[i].ID > node.Hosts[j].ID
+		})
+	}
+	return root
+}
+
+// Count is the number of hosts in the group and the groups below it
+func (node *Node) Count() int {
+	count := len(node.Hosts)
+	for _, group := range node.Groups {
+		count += group.Count()
+	}
+	return count
+}
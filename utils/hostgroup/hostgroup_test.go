@@ -0,0 +1,151 @@
+package hostgroup
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"yoru/models"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"prod", "prod"},
+		{" prod / eu-west/ ", "prod/eu-west"},
+		{"/prod//eu/", "prod/eu"},
+		{" / ", ""},
+	}
+
+	for _, test := range tests {
+		if got := NormalizePath(test.value); got != test.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a/b/c", []string{"a", "a/b", "a/b/c"}},
+	}
+
+	for _, test := range tests {
+		if got := Ancestors(test.path); !slices.Equal(got, test.want) {
+			t.Errorf("Ancestors(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"web, prod #db", []string{"web", "prod", "db"}},
+		{"web,,Web WEB", []string{"web"}},
+		{"##db # ,", []string{"db"}},
+	}
+
+	for _, test := range tests {
+		if got := ParseTags(test.value); !slices.Equal(got, test.want) {
+			t.Errorf("ParseTags(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+	if got := NormalizeTags(" #web  prod,web "); got != "web,prod" {
+		t.Errorf("NormalizeTags() = %q, want %q", got, "web,prod")
+	}
+}
+
+func testHosts() []models.Host {
+	hosts := []models.Host{
+		{Name: "web1", Group: "prod/eu", Tags: "web"},
+		{Name: "web2", Group: "prod/us", Tags: "web,canary"},
+		{Name: "db", Group: "prod", Tags: "db"},
+		{Name: "dev", Group: "production"},
+		{Name: "laptop"},
+	}
+	for index := range hosts {
+		hosts[index].ID = uint(index + 1)
+	}
+	return hosts
+}
+
+func hostNames(hosts []models.Host) string {
+	var names []string
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{"#web", "web1 web2", false},
+		{"#WEB", "web1 web2", false},
+		{"#missing", "", false},
+		{"/prod", "web1 web2 db", false},
+		{"/prod/eu/", "web1", false},
+		{"/", "web1 web2 db dev laptop", false},
+		{"LAPTOP", "laptop", false},
+		{"laptop, #db, laptop", "db laptop", false},
+		{" , ", "", false},
+		{"laptpo", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := Select(testHosts(), test.selector)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Select(%q) error = %v, want error %v", test.selector, err, test.wantErr)
+			continue
+		}
+		if names := hostNames(got); names != test.want {
+			t.Errorf("Select(%q) = %q, want %q", test.selector, names, test.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	hosts := append(testHosts(),
+		models.Host{Name: "web3", Group: " prod / eu ", SortOrder: -1},
+		models.Host{Name: "web4", Group: "prod/eu"},
+		models.Host{Name: "alpha", Group: "Beta"},
+	)
+	// Equal sort orders put the newest first
+	for index := range hosts {
+		hosts[index].ID = uint(index + 1)
+	}
+
+	root := Build(hosts)
+	if got := hostNames(root.Hosts); got != "laptop" {
+		t.Errorf("root hosts = %q, want %q", got, "laptop")
+	}
+	if root.Count() != len(hosts) {
+		t.Errorf("Count() = %d, want %d", root.Count(), len(hosts))
+	}
+
+	var groups []string
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		for _, group := range node.Groups {
+			groups = append(groups, group.Path+"="+hostNames(group.Hosts))
+			walk(group)
+		}
+	}
+	walk(root)
+	want := []string{"Beta=alpha", "prod=db", "prod/eu=web3 web4 web1", "prod/us=web2", "production=dev"}
+	if !slices.Equal(groups, want) {
+		t.Errorf("groups = %q, want %q", groups, want)
+	}
+}
@@ -0,0 +1,195 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxHostnameLength = 253
+	maxLabelLength    = 63
+
+	// DefaultSSHPort is used for targets that do not name a port
+	DefaultSSHPort = 22
+)
+
+var (
+	ErrHostnameRequired = errors.New("hostname is required")
+	ErrInvalidHostname  = errors.New("invalid hostname")
+	ErrHostnameTooLong  = errors.New("hostname is longer than 253 characters")
+	ErrLabelTooLong     = errors.New("hostname label is longer than 63 characters")
+	ErrInvalidIPv6      = errors.New("invalid IPv6 address")
+	ErrInvalidZone      = errors.New("zone ID is only allowed on IPv6 addresses")
+	ErrInvalidPort      = errors.New("port must be 1-65535")
+	ErrInvalidScheme    = errors.New("only ssh:// URIs are supported")
+)
+
+// Target is a connection typed as user@host:port or an ssh:// URI
+type Target struct {
+	User     string
+	Hostname string
+	Port     int
+}
+
+// ParseTarget reads user@host:port, where the user and port are optional
+// and IPv6 literals are bracketed when a port follows, or an ssh:// URI
+// (RFC 4248 style, ssh://user@host:port)
+func ParseTarget(value string) (Target, error) {
+	value = strings.TrimSpace(value)
+	target := Target{Port: DefaultSSHPort}
+
+	var address string
+	if scheme, _, ok := strings.Cut(value, "://"); ok {
+		if !strings.EqualFold(scheme, "ssh") {
+			return target, ErrInvalidScheme
+		}
+		uri, err := url.Parse(value)
+		if err != nil {
+			return target, ErrInvalidHostname
+		}
+		target.User = uri.User.Username()
+		address = uri.Host
+	} else {
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			target.User = value[:at]
+			value = value[at+1:]
+		}
+		address = value
+	}
+
+	// A bare IPv6 literal has several colons and no port
+	hostname, port := address, ""
+	if strings.HasPrefix(address, "[") || strings.Count(address, ":") == 1 {
+		var err error
+		if hostname, port, err = net.SplitHostPort(address); err != nil {
+			hostname, port = address, ""
+		}
+	}
+	if port != "" {
+		number, err := strconv.Atoi(port)
+		if err != nil || number < 1 || number > 65535 {
+			return target, ErrInvalidPort
+		}
+		target.Port = number
+	}
+
+	target.Hostname = NormalizeHostname(hostname)
+	if err := ValidateHostname(target.Hostname); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// String formats the target the way it is typed, leaving out the default port
+func (target Target) String() string {
+	address := target.Hostname
+	if target.Port != DefaultSSHPort {
+		address = JoinHostPort(target.Hostname, target.Port)
+	} else if strings.Contains(address, ":") {
+		address = "[" + address + "]"
+	}
+	if target.User != "" {
+		return target.User + "@" + address
+	}
+	return address
+}
+
+// NormalizeHostname trims whitespace and the brackets users often type
+// around IPv6 literals, returning the form stored on the host
+func NormalizeHostname(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
+
+// ValidateHostname accepts IPv4 literals, IPv6 literals with an optional
+// zone ID (fe80::1%eth0) and RFC 1123 host names
+func ValidateHostname(value string) error {
+	value = NormalizeHostname(value)
+	if value == "" {
+		return ErrHostnameRequired
+	}
+
+	if strings.ContainsAny(value, ":%") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			if !strings.Contains(value, ":") {
+				return ErrInvalidZone
+			}
+			return ErrInvalidIPv6
+		}
+		if !addr.Is6() {
+			return ErrInvalidIPv6
+		}
+		return nil
+	}
+
+	if _, err := netip.ParseAddr(value); err == nil {
+		return nil
+	}
+
+	return validateDNSName(value)
+}
+
+func validateDNSName(name string) error {
+	// A single trailing dot marks a fully qualified name
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return ErrInvalidHostname
+	}
+	if len(name) > maxHostnameLength {
+		return ErrHostnameTooLong
+	}
+
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if label == "" {
+			return ErrInvalidHostname
+		}
+		if len(label) > maxLabelLength {
+			return ErrLabelTooLong
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return ErrInvalidHostname
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return ErrInvalidHostname
+			}
+		}
+	}
+
+	// Dotted names made only of digits are mistyped IPv4 addresses, not
+	// host names (RFC 1123 section 2.1)
+	last := labels[len(labels)-1]
+	if _, err := strconv.Atoi(last); err == nil {
+		return ErrInvalidHostname
+	}
+
+	return nil
+}
+
+// JoinHostPort formats an address for display and dialing, bracketing
+// IPv6 literals as in [2001:db8::1]:22
+func JoinHostPort(hostname string, port int) string {
+	return net.JoinHostPort(hostname, strconv.Itoa(port))
+}
+
+// ResolveAddresses looks up the addresses a hostname currently resolves
+// to; IP literals are returned as they are
+func ResolveAddresses(ctx context.Context, hostname string) ([]string, error) {
+	hostname = NormalizeHostname(hostname)
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return []string{hostname}, nil
+	}
+
+	return net.DefaultResolver.LookupHost(ctx, hostname)
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		value string
+		want  error
+	}{
+		{"example.com", nil},
+		{"example.com.", nil},
+		{"db-01.internal", nil},
+		{"localhost", nil},
+		{"  host  ", nil},
+		{"192.168.1.10", nil},
+		{"2001:db8::1", nil},
+		{"[2001:db8::1]", nil},
+		{"fe80::1%eth0", nil},
+		{"", ErrHostnameRequired},
+		{"   ", ErrHostnameRequired},
+		{"[]", ErrHostnameRequired},
+		{"-host", ErrInvalidHostname},
+		{"host-", ErrInvalidHostname},
+		{"a..b", ErrInvalidHostname},
+		{".", ErrInvalidHostname},
+		{"under_score", ErrInvalidHostname},
+		{"host name", ErrInvalidHostname},
+		{"256.1.1.1", ErrInvalidHostname},
+		{"1.2.3", ErrInvalidHostname},
+		{strings.Repeat("a", 64) + ".com", ErrLabelTooLong},
+		{strings.Repeat("a.", 127) + "com", ErrHostnameTooLong},
+		{"2001:db8::g", ErrInvalidIPv6},
+		{"192.168.1.1%eth0", ErrInvalidZone},
+		{"host%eth0", ErrInvalidZone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if err := ValidateHostname(test.value); !errors.Is(err, test.want) {
+				t.Errorf("ValidateHostname(%q) = %v, want %v", test.value, err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Target
+		wantErr error
+	}{
+		{"example.com", Target{Hostname: "example.com", Port: 22}, nil},
+		{"root@example.com", Target{User: "root", Hostname: "example.com", Port: 22}, nil},
+		{"root@example.com:2222", Target{User: "root", Hostname: "example.com", Port: 2222}, nil},
+		{"  deploy@10.0.0.5:22  ", Target{User: "deploy", Hostname: "10.0.0.5", Port: 22}, nil},
+		{"user@corp@bastion", Target{User: "user@corp", Hostname: "bastion", Port: 22}, nil},
+		{"2001:db8::1", Target{Hostname: "2001:db8::1", Port: 22}, nil},
+		{"[2001:db8::1]", Target{Hostname: "2001:db8::1", Port: 22}, nil},
+		{"admin@[2001:db8::1]:2200", Target{User: "admin", Hostname: "2001:db8::1", Port: 2200}, nil},
+		{"ssh://example.com", Target{Hostname: "example.com", Port: 22}, nil},
+		{"SSH://git@example.com:7999", Target{User: "git", Hostname: "example.com", Port: 7999}, nil},
+		{"ssh://[::1]:2022", Target{Hostname: "::1", Port: 2022}, nil},
+		{"", Target{Port: 22}, ErrHostnameRequired},
+		{"root@", Target{User: "root", Port: 22}, ErrHostnameRequired},
+		{"example.com:0", Target{Port: 22}, ErrInvalidPort},
+		{"example.com:65536", Target{Port: 22}, ErrInvalidPort},
+		{"example.com:ssh", Target{Port: 22}, ErrInvalidPort},
+		{"http://example.com", Target{Port: 22}, ErrInvalidScheme},
+		{"bad_host", Target{Hostname: "bad_host", Port: 22}, ErrInvalidHostname},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := ParseTarget(test.value)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("ParseTarget(%q) error = %v, want %v", test.value, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ParseTarget(%q) = %+v, want %+v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTargetString(t *testing.T) {
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{Hostname: "example.com", Port: 22}, "example.com"},
+		{Target{User: "root", Hostname: "example.com", Port: 2222}, "root@example.com:2222"},
+		{Target{Hostname: "2001:db8::1", Port: 22}, "[2001:db8::1]"},
+		{Target{User: "admin", Hostname: "2001:db8::1", Port: 2200}, "admin@[2001:db8::1]:2200"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.target.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+			// What String prints parses back to the same target
+			if parsed, err := ParseTarget(test.want); err != nil || parsed != test.target {
+				t.Errorf("ParseTarget(%q) = %+v, %v", test.want, parsed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Method is how a notification reaches the user
+type Method string
+
+const (
+	// MethodOSC9 asks the hosting terminal emulator for a desktop
+	// notification with OSC 9 (iTerm2, Windows Terminal, WezTerm, Ghostty)
+	MethodOSC9 Method = "osc9"
+	// MethodOSC777 does the same with OSC 777 (urxvt, foot, Konsole, Ghostty)
+	MethodOSC777 Method = "osc777"
+	// MethodCommand runs a local command, given the notification in the
+	// YORU_TITLE, YORU_MESSAGE and YORU_HOST environment variables
+	MethodCommand Method = "command"
+	// MethodOff drops notifications
+	MethodOff Method = "off"
+
+	DefaultMethod = MethodOSC9
+)
+
+// Methods lists the valid methods
+var Methods = []Method{MethodOSC9, MethodOSC777, MethodCommand, MethodOff}
+
+// Output is the terminal the interface draws to. The renderer writes each
+// frame in one call, so sequences sent through it land between frames.
+var Output = &terminalOutput{file: os.Stdout}
+
+// terminalOutput serialises the writes to a terminal; it keeps the file's
+// descriptor visible so the program still finds the terminal's size
+type terminalOutput struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func (output *terminalOutput) Write(data []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.file.Write(data)
+}
+
+func (output *terminalOutput) Read(data []byte) (int, error) {
+	return output.file.Read(data)
+}
+
+func (output *terminalOutput) Close() error {
+	return output.file.Close()
+}
+
+func (output *terminalOutput) Fd() uintptr {
+	return output.file.Fd()
+}
+
+// Notification is a session event worth telling the user about
+type Notification struct {
+	Title   string
+	Message string
+	Host    string
+}
+
+// ParseMethod checks a method name, as stored in the preferences
+func ParseMethod(value string) (Method, error) {
+	for _, method := range Methods {
+		if string(method) == value {
+			return method, nil
+		}
+	}
+	return "", fmt.Errorf("unknown notification method %q", value)
+}
+
+// Send delivers a notification. command is only used by MethodCommand.
+// It writes to Output, so it is meant to run from a command rather than
+// while the interface updates.
+func Send(method Method, command string, notification Notification) error {
+	switch method {
+	case MethodOSC9:
+		return writeOSC("9;" + clean(notification.Title+": "+notification.Message))
+	case MethodOSC777:
+		return writeOSC("777;notify;" + clean(notification.Title) + ";" + clean(notification.Message))
+	case MethodCommand:
+		return runCommand(command, notification)
+	case MethodOff:
+		return nil
+	}
+	return fmt.Errorf("unknown notification method %q", method)
+}
+
+// writeOSC sends an OSC sequence to the terminal the interface runs in,
+// through tmux to the terminal outside it if need be
+func writeOSC(payload string) error {
+	sequence := "\x1b]" + payload + "\x07"
+	if os.Getenv("TMUX") != "" {
+		sequence = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := Output.Write([]byte(sequence))
+	return err
+}
+
+// runCommand starts command in the background without waiting for it
+func runCommand(command string, notification Notification) error {
+	if strings.TrimSpace(command) == "" {
+		return errors.New("no notification command set")
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"YORU_TITLE="+notification.Title,
+		"YORU_MESSAGE="+notification.Message,
+		"YORU_HOST="+notification.Host,
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// clean drops the control characters that would end the sequence early;
+// OSC 777 also takes ";" as a separator
+func clean(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		if r == ';' {
+			return ','
+		}
+		return r
+	}, text)
+}
+
+// ParsePatterns splits a comma separated list of output patterns
+func ParsePatterns(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// NormalizePatterns tidies a list of patterns for storage
+func NormalizePatterns(value string) string {
+	return strings.Join(ParsePatterns(value), ", ")
+}
+
+// Matcher finds patterns in session output as it arrives, including those
+// split across reads. Matching ignores case.
+type Matcher struct {
+	patterns []string
+	lowered  []string
+	tail     string
+	longest  int
+}
+
+// NewMatcher creates a matcher for patterns, nil if there are none
+func NewMatcher(patterns []string) *Matcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	matcher := &Matcher{patterns: patterns}
+	for _, pattern := range patterns {
+		lowered := strings.ToLower(pattern)
+		matcher.lowered = append(matcher.lowered, lowered)
+		matcher.longest = max(matcher.longest, len(lowered))
+	}
+	return matcher
+}
+
+// Feed looks for the patterns in the next piece of output, which should
+// be free of escape sequences, and returns those that appeared in it
+func (matcher *Matcher) Feed(text string) []string {
+	window := matcher.tail + strings.ToLower(text)
+
+	var found []string
+	for index, pattern := range matcher.lowered {
+		// Only matches ending in the new text, the rest were reported
+		searchFrom := max(0, len(matcher.tail)-len(pattern)+1)
+		if strings.Contains(window[searchFrom:], pattern) {
+			found = append(found, matcher.patterns[index])
+		}
+	}
+
+	// Keep what a pattern could still be completed from; patterns do not
+	// span lines
+	if newline := strings.LastIndexAny(window, "\r\n"); newline >= 0 {
+		window = window[newline+1:]
+	}
+	if len(window) >= matcher.longest {
+		window = window[len(window)-matcher.longest+1:]
+	}
+	matcher.tail = window
+	return found
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		feeds    []string
+		want     [][]string
+	}{
+		{
+			name:     "single read",
+			patterns: []string{"error"},
+			feeds:    []string{"build error: missing file"},
+			want:     [][]string{{"error"}},
+		},
+		{
+			name:     "ignores case",
+			patterns: []string{"Done"},
+			feeds:    []string{"ALL DONE"},
+			want:     [][]string{{"Done"}},
+		},
+		{
+			name:     "split across reads",
+			patterns: []string{"password:"},
+			feeds:    []string{"sudo pass", "word: "},
+			want:     [][]string{nil, {"password:"}},
+		},
+		{
+			name:     "split across three reads",
+			patterns: []string{"finished"},
+			feeds:    []string{"fin", "ish", "ed"},
+			want:     [][]string{nil, nil, {"finished"}},
+		},
+		{
+			name:     "reported once",
+			patterns: []string{"fail"},
+			feeds:    []string{"fail", "ed", " again"},
+			want:     [][]string{{"fail"}, nil, nil},
+		},
+		{
+			name:     "repeated later",
+			patterns: []string{"fail"},
+			feeds:    []string{"fail\n", "fail\n"},
+			want:     [][]string{{"fail"}, {"fail"}},
+		},
+		{
+			name:     "not across lines",
+			patterns: []string{"ab"},
+			feeds:    []string{"a\n", "b"},
+			want:     [][]string{nil, nil},
+		},
+		{
+			name:     "several patterns",
+			patterns: []string{"warning", "error"},
+			feeds:    []string{"1 error, 2 warnings"},
+			want:     [][]string{{"warning", "error"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher := NewMatcher(test.patterns)
+			for i, text := range test.feeds {
+				if got := matcher.Feed(text); !reflect.DeepEqual(got, test.want[i]) {
+					t.Errorf("Feed(%q) = %q, want %q", text, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewMatcherWithoutPatterns(t *testing.T) {
+	if matcher := NewMatcher(ParsePatterns(" , ")); matcher != nil {
+		t.Errorf("NewMatcher() = %v, want nil", matcher)
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		value      string
+		want       []string
+		normalized string
+	}{
+		{"", nil, ""},
+		{"error", []string{"error"}, "error"},
+		{" error ,, build failed ,", []string{"error", "build failed"}, "error, build failed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if got := ParsePatterns(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParsePatterns(%q) = %q, want %q", test.value, got, test.want)
+			}
+			if got := NormalizePatterns(test.value); got != test.normalized {
+				t.Errorf("NormalizePatterns(%q) = %q, want %q", test.value, got, test.normalized)
+			}
+		})
+	}
+}
@@ -17,6 +17,18 @@ func getDatabasePath() (string, error) {
 	return filepath.Join(baseDir, databaseName), ensureDirectoryExists(baseDir)
 }
 
+func getDataDirectory() (string, error) {
+	if shared.Version == "dev" {
+		return os.Getwd()
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, shared.PackageName), nil
+}
+
 func getBaseDirectory() (string, error) {
 	if shared.Version == "dev" {
 		return os.Getwd()
@@ -27,3 +39,7 @@ func getBaseDirectory() (string, error) {
 func ensureDirectoryExists(path string) error {
 	return os.MkdirAll(path, 0755)
 }
+
+func ensurePrivateDirectoryExists(path string) error {
+	return os.MkdirAll(path, 0700)
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"path/filepath"
 	"yoru/types"
 
 	"gorm.io/driver/sqlite"
@@ -21,3 +22,25 @@ func GetDatabaseInstance() (*types.Database, error) {
 
 	return &types.Database{DB: database}, err
 }
+
+// GetRecordingsDirectory returns the directory session recordings are written to
+func GetRecordingsDirectory() (string, error) {
+	dataDir, err := getDataDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	recordingsDir := filepath.Join(dataDir, "recordings")
+	return recordingsDir, ensurePrivateDirectoryExists(recordingsDir)
+}
+
+// GetTranscriptsDirectory returns the directory holding the per-host transcript directories
+func GetTranscriptsDirectory() (string, error) {
+	dataDir, err := getDataDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	transcriptsDir := filepath.Join(dataDir, "transcripts")
+	return transcriptsDir, ensurePrivateDirectoryExists(transcriptsDir)
+}
@@ -1,17 +1,18 @@
 package main
 
 import (
+	"os"
+	"yoru/cli"
 	"yoru/screens"
-	"yoru/shared"
 	"yoru/utils/errors"
-
-	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	program := tea.NewProgram(screens.ScreenManager, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	shared.SetProgram(program)
-	if _, err := program.Run(); err != nil {
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	if err := screens.Run(); err != nil {
 		errors.ExitOnBridgeFailedStart(err)
 	}
 }